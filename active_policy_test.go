@@ -0,0 +1,115 @@
+package keys_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func activePolicyStore(t *testing.T, now time.Time) *MockStore {
+	t.Helper()
+
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	long := now.Add(48 * time.Hour)
+	short := now.Add(time.Hour)
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	older := makeTestKey("older", AlgEdDSA, true, &long, enc, priv)
+	older.CreatedAt = now
+	older.Priority = 10
+	store.data[older.KID] = older
+
+	priv, _ = generatePrivateKey(AlgEdDSA)
+	newer := makeTestKey("newer", AlgEdDSA, true, &short, enc, priv)
+	newer.CreatedAt = now.Add(time.Minute)
+	store.data[newer.KID] = newer
+
+	return store
+}
+
+func TestActivePolicy_SelectsWinner(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name   string
+		policy ActivePolicy
+		want   string
+	}{
+		{"default", nil, "newer"},
+		{"newest", PreferNewest, "newer"},
+		{"latest expiry", PreferLatestExpiry, "older"},
+		{"priority", PreferPriority, "older"},
+	}
+
+	for _, tc := range cases {
+		km, err := NewKeyManager(activePolicyStore(t, now), MockEncryptor{}, mockPolicy, WithActivePolicy(tc.policy))
+		if err != nil {
+			t.Fatalf("%s: NewKeyManager error: %v", tc.name, err)
+		}
+
+		if ck := km.activeKey(AlgEdDSA); ck == nil || ck.key.KID != tc.want {
+			t.Fatalf("%s: expected %s to sign, got %v", tc.name, tc.want, ck)
+		}
+		if dups := km.DuplicateActiveKeys(); len(dups) != 1 || dups[0].Winner != tc.want {
+			t.Fatalf("%s: duplicates must report the policy's winner, got %+v", tc.name, dups)
+		}
+	}
+}
+
+func TestActivePolicy_TiesBrokenByKID(t *testing.T) {
+	now := time.Now()
+	store := activePolicyStore(t, now)
+	store.data["newer"].CreatedAt = now
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if ck := km.activeKey(AlgEdDSA); ck.key.KID != "older" {
+		t.Fatalf("equal keys must be ordered by KID, got %s", ck.key.KID)
+	}
+}
+
+func TestSetPriority(t *testing.T) {
+	store := activePolicyStore(t, time.Now())
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithActivePolicy(PreferPriority))
+
+	if err := km.SetPriority("newer", 20); err != nil {
+		t.Fatalf("SetPriority error: %v", err)
+	}
+	if ck := km.activeKey(AlgEdDSA); ck.key.KID != "newer" {
+		t.Fatalf("raised priority must win, got %s", ck.key.KID)
+	}
+	if !store.data["older"].IsActive || !store.data["newer"].IsActive {
+		t.Fatalf("setting a priority must keep both keys active")
+	}
+
+	kid, _ := signedBy(t, km)
+	if kid != "newer" {
+		t.Fatalf("the higher-priority key must sign, got %s", kid)
+	}
+
+	if err := km.SetPriority("newer", 5); err != nil {
+		t.Fatalf("SetPriority error: %v", err)
+	}
+	if kid, _ := signedBy(t, km); kid != "older" {
+		t.Fatalf("lowering the priority must hand signing back, got %s", kid)
+	}
+
+	if err := km.SetPriority("missing", 1); err == nil {
+		t.Fatalf("unknown KID must fail")
+	}
+}
+
+func signedBy(t *testing.T, km *KeyManager) (string, []byte) {
+	t.Helper()
+
+	var kid string
+	sig, err := km.Sign(AlgEdDSA, func(k string) ([]byte, error) {
+		kid = k
+		return []byte("payload"), nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	return kid, sig
+}
@@ -0,0 +1,351 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+func (km *KeyManager) saver() (KeySaver, error) {
+	if km.verifyOnly {
+		return nil, ErrVerifyOnly
+	}
+	if km.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	saver, ok := km.store.(KeySaver)
+	if !ok {
+		return nil, fmt.Errorf("%w: requires KeySaver", ErrStoreUnsupported)
+	}
+	return saver, nil
+}
+
+// Revoke marks a stored key as revoked. Revoked keys are dropped from the
+// JWKS, and signing or verifying with them fails with ErrKeyRevoked.
+// Revoking the active key leaves its slot without an active key until the
+// next rotation.
+func (km *KeyManager) Revoke(kid string) error {
+	return km.RevokeCtx(context.Background(), kid)
+}
+
+func (km *KeyManager) RevokeCtx(ctx context.Context, kid string) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.KID != kid {
+			continue
+		}
+		if err := km.authorize(ctx, ActionRevoke, k); err != nil {
+			return err
+		}
+		if k.RevokedAt != nil {
+			return nil
+		}
+
+		now := km.clock.Now()
+		revoked := *k
+		revoked.IsActive = false
+		revoked.RevokedAt = &now
+		revoked.UpdatedAt = now
+
+		if err := saver.Save(&revoked); err != nil {
+			return err
+		}
+
+		km.logger().Info("key revoked", "kid", kid, "alg", k.Alg)
+		km.publish(ctx, LifecycleEvent{Type: EventKeyRevoked, KID: kid, Alg: k.Alg, Purpose: k.Purpose})
+
+		return km.ReloadCache()
+	}
+
+	return fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+// SetPriority stores priority on a key and reloads the cache. Priorities
+// only pick the signing key under PreferPriority. The other active keys of
+// the slot stay active, so setting the priority of an active key needs a
+// KeyBatchSaver store.
+func (km *KeyManager) SetPriority(kid string, priority int) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	keys, err := km.listKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.KID != kid {
+			continue
+		}
+		if k.Priority == priority {
+			return nil
+		}
+
+		updated := *k
+		updated.Priority = priority
+		updated.UpdatedAt = km.clock.Now()
+
+		if err := km.putKeys(saver, &updated); err != nil {
+			return err
+		}
+
+		return km.ReloadCache()
+	}
+
+	return fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+// ImportKey stores an existing private key for alg under a new KID and
+// returns the KID. With activate set it replaces the active key of the
+// default purpose; otherwise it is only used to verify.
+func (km *KeyManager) ImportKey(alg Alg, priv crypto.Signer, activate bool) (string, error) {
+	return km.importKey(context.Background(), keyImport{alg: alg, priv: priv, activate: activate})
+}
+
+// keyImport describes a private key to store. An empty kid is generated,
+// and a cert replaces the one WithSelfSignedCertificates would issue.
+type keyImport struct {
+	kid      string
+	alg      Alg
+	purpose  Purpose
+	labels   map[string]string
+	priv     crypto.Signer
+	activate bool
+	cert     []byte
+}
+
+func (km *KeyManager) importKey(ctx context.Context, in keyImport) (string, error) {
+	saver, err := km.saver()
+	if err != nil {
+		return "", err
+	}
+
+	alg, purpose, priv := in.alg, in.purpose, in.priv
+	if err := checkKeyAlg(alg, priv); err != nil {
+		return "", err
+	}
+
+	policy, err := km.rotationConfigFor(purpose)
+	if err != nil {
+		return "", err
+	}
+
+	kid := in.kid
+	if kid == "" {
+		if kid, err = km.newKID(ctx, alg); err != nil {
+			return "", err
+		}
+	} else if taken, err := km.kidTaken(ctx, kid); err != nil {
+		return "", err
+	} else if taken {
+		return "", fmt.Errorf("%w: %s", ErrKIDCollision, kid)
+	}
+
+	privBytes, err := marshalPKCS8(priv)
+	if err != nil {
+		return "", err
+	}
+
+	pubBytes, err := marshalPublicKey(priv.Public())
+	if err != nil {
+		return "", err
+	}
+
+	now := km.clock.Now()
+	expires := now.Add(policy.TTL)
+
+	cert := in.cert
+	if cert == nil {
+		if cert, err = km.issueCertificate(alg, privBytes, kid, now, expires); err != nil {
+			wipeBytes(privBytes)
+			return "", err
+		}
+	}
+
+	escrow, err := km.sealEscrow(kid, privBytes)
+	if err != nil {
+		wipeBytes(privBytes)
+		return "", err
+	}
+
+	encrypted, err := km.encrypt(ctx, privBytes)
+	wipeBytes(privBytes)
+	if err != nil {
+		return "", err
+	}
+
+	k := &Key{
+		KID:          kid,
+		Alg:          alg,
+		Purpose:      purpose,
+		Labels:       in.labels,
+		Environment:  km.environment,
+		IsActive:     in.activate,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		ExpiresAt:    &expires,
+		EncryptedKey: encrypted,
+		PublicKey:    pubBytes,
+		Certificate:  cert,
+		Escrow:       escrow,
+	}
+
+	if k.Attestation, err = km.attest(ctx, k, OriginImported); err != nil {
+		return "", err
+	}
+
+	if err := saver.Save(k); err != nil {
+		return "", err
+	}
+	km.publish(ctx, LifecycleEvent{Type: EventKeyImported, KID: k.KID, Alg: alg, Purpose: purpose})
+
+	return k.KID, km.reload(ctx)
+}
+
+func checkKeyAlg(alg Alg, priv crypto.Signer) error {
+	var ok bool
+
+	switch alg {
+	case AlgRS256:
+		_, ok = priv.(*rsa.PrivateKey)
+	case AlgES256:
+		k, isECDSA := priv.(*ecdsa.PrivateKey)
+		ok = isECDSA && k.Curve == elliptic.P256()
+	case AlgEdDSA:
+		_, ok = priv.(ed25519.PrivateKey)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+
+	if !ok {
+		return fmt.Errorf("%T cannot be used for %s", priv, alg)
+	}
+	return nil
+}
+
+// ReEncrypt decrypts every stored key with the manager's Encryptor and
+// saves it encrypted with to. Nothing is saved unless every key
+// re-encrypts. A KeyBatchSaver store gets all keys in one transaction;
+// with any other store a failed save puts back the keys already saved.
+// The manager keeps its own Encryptor, so it cannot load the re-encrypted
+// keys; build a new manager with to afterwards.
+func (km *KeyManager) ReEncrypt(to Encryptor) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	keys, err := km.listKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	now := km.clock.Now()
+
+	var updates, prev []*Key
+	for _, k := range keys {
+		if k.EncryptedKey == nil {
+			continue
+		}
+
+		plain, err := km.decrypt(context.Background(), k)
+		if err != nil {
+			return &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+		}
+
+		encrypted, err := to.Encrypt(plain)
+		wipeBytes(plain)
+		if err != nil {
+			return &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+		}
+
+		updated := *k
+		updated.EncryptedKey = encrypted
+		updated.UpdatedAt = now
+
+		updates = append(updates, &updated)
+		prev = append(prev, k)
+	}
+
+	return km.saveAll(saver, updates, prev)
+}
+
+// putKeys stores keys exactly as given, leaving the other active keys of
+// their slots alone. KeySaver.Save retires those, so without a
+// KeyBatchSaver only inactive keys can be stored this way.
+func (km *KeyManager) putKeys(saver KeySaver, keys ...*Key) error {
+	if batch, ok := km.store.(KeyBatchSaver); ok {
+		return batch.SaveAll(keys)
+	}
+
+	for _, k := range keys {
+		if k.IsActive {
+			return fmt.Errorf("%w: requires KeyBatchSaver to update active key %s", ErrStoreUnsupported, k.KID)
+		}
+	}
+	for _, k := range keys {
+		if err := saver.Save(k); err != nil {
+			return &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+		}
+	}
+	return nil
+}
+
+// saveAll persists keys in one transaction when the store is a
+// KeyBatchSaver. Otherwise each key is saved in turn, and when a save
+// fails the keys already saved are rolled back: restored to prev[i], or
+// deleted when prev is nil. The error says so if the rollback fails too.
+func (km *KeyManager) saveAll(saver KeySaver, keys, prev []*Key) error {
+	if batch, ok := km.store.(KeyBatchSaver); ok {
+		return batch.SaveAll(keys)
+	}
+
+	for i, k := range keys {
+		if err := saver.Save(k); err != nil {
+			err = &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+			if rbErr := km.undoSaves(saver, keys[:i], prev); rbErr != nil {
+				return fmt.Errorf("%w; rollback failed, store is partially updated: %w", err, rbErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (km *KeyManager) undoSaves(saver KeySaver, saved, prev []*Key) error {
+	deleter, _ := km.store.(KeyDeleter)
+
+	var errs []error
+	for i, k := range saved {
+		var err error
+		switch {
+		case prev != nil:
+			err = saver.Save(prev[i])
+		case deleter != nil:
+			err = deleter.Delete(k.KID)
+		default:
+			err = fmt.Errorf("%w: requires KeyDeleter", ErrStoreUnsupported)
+		}
+		if err != nil {
+			errs = append(errs, &KeyError{KID: k.KID, Alg: k.Alg, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
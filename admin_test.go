@@ -0,0 +1,140 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRevoke_RejectsKeyEverywhere(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	kid := km.activeKey(AlgEdDSA).key.KID
+	sig, _ := km.Sign(AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil })
+
+	if err := km.Revoke(kid); err != nil {
+		t.Fatalf("Revoke error: %v", err)
+	}
+
+	if err := km.Verify(kid, []byte("x"), sig); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("expected ErrKeyRevoked from Verify, got %v", err)
+	}
+	if _, err := km.SignWithKID(kid, func(string) ([]byte, error) { return nil, nil }); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("expected ErrKeyRevoked from SignWithKID, got %v", err)
+	}
+	if err := km.VerifyAny(AlgEdDSA, []byte("x"), sig); err == nil {
+		t.Fatalf("expected VerifyAny to skip revoked key")
+	}
+
+	body, _ := km.JWKS()
+	var jwks JWKS
+	_ = json.Unmarshal(body, &jwks)
+	if len(jwks.Keys) != 0 {
+		t.Fatalf("expected revoked key to be dropped from JWKS")
+	}
+
+	if keys := km.Keys(); len(keys) != 1 || keys[0].State != KeyStateRevoked {
+		t.Fatalf("expected revoked state in Keys, got %+v", keys)
+	}
+
+	if err := km.Revoke("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestImportKey(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	priv, _ := generatePrivateKey(AlgES256)
+	kid, err := km.ImportKey(AlgES256, priv, true)
+	if err != nil {
+		t.Fatalf("ImportKey error: %v", err)
+	}
+
+	if ck := km.activeKey(AlgES256); ck == nil || ck.key.KID != kid {
+		t.Fatalf("expected imported key to be active")
+	}
+
+	rsaKey, _ := generatePrivateKey(AlgRS256)
+	if _, err := km.ImportKey(AlgES256, rsaKey, false); err == nil {
+		t.Fatalf("expected error importing an RSA key as ES256")
+	}
+}
+
+func TestReEncrypt(t *testing.T) {
+	store := NewMockStore()
+	from, _ := NewAESGCMEncryptor(make([]byte, 32))
+
+	km, _ := NewKeyManager(store, from, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgRS256})
+
+	toKey := make([]byte, 32)
+	toKey[0] = 1
+	to, _ := NewAESGCMEncryptor(toKey)
+
+	if err := km.ReEncrypt(to); err != nil {
+		t.Fatalf("ReEncrypt error: %v", err)
+	}
+
+	if _, err := NewKeyManager(store, from, mockPolicy); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("expected the old master key to stop working, got %v", err)
+	}
+
+	reopened, err := NewKeyManager(store, to, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager with new master key error: %v", err)
+	}
+	if reopened.activeKey(AlgRS256) == nil {
+		t.Fatalf("expected keys to load under the new master key")
+	}
+}
+
+func TestAdmin_RequiresKeySaver(t *testing.T) {
+	km, _ := NewKeyManager(&listOnlyStore{NewMockStore()}, MockEncryptor{}, mockPolicy)
+
+	if err := km.Revoke("k"); !errors.Is(err, ErrStoreUnsupported) {
+		t.Fatalf("expected ErrStoreUnsupported, got %v", err)
+	}
+}
+
+// unbatchedStore hides MockStore.SaveAll and fails the save numbered
+// failAt, counting from 1.
+type unbatchedStore struct {
+	mock   *MockStore
+	failAt int
+	saves  int
+}
+
+func (s *unbatchedStore) List() ([]*Key, error)         { return s.mock.List() }
+func (s *unbatchedStore) Rotate(newKey, old *Key) error { return s.mock.Rotate(newKey, old) }
+func (s *unbatchedStore) Delete(kid string) error       { return s.mock.Delete(kid) }
+
+func (s *unbatchedStore) Save(key *Key) error {
+	s.saves++
+	if s.saves == s.failAt {
+		return errors.New("store unavailable")
+	}
+	return s.mock.Save(key)
+}
+
+func TestReEncrypt_RollsBackFailedSave(t *testing.T) {
+	store := &unbatchedStore{mock: NewMockStore()}
+	from, _ := NewAESGCMEncryptor(make([]byte, 32))
+
+	km, _ := NewKeyManager(store, from, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgES256, AlgRS256})
+
+	toKey := make([]byte, 32)
+	toKey[0] = 1
+	to, _ := NewAESGCMEncryptor(toKey)
+
+	store.failAt = 2
+	if err := km.ReEncrypt(to); err == nil {
+		t.Fatalf("expected ReEncrypt to fail")
+	}
+
+	if _, err := NewKeyManager(store.mock, from, mockPolicy); err != nil {
+		t.Fatalf("every key must still open with the old master key: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// ApprovalRequest describes a key created by Rotate that waits for
+// approval. PreviousKID is the key it will replace, if any.
+type ApprovalRequest struct {
+	KID         string
+	Alg         Alg
+	Purpose     Purpose
+	Labels      map[string]string
+	PreviousKID string
+	RequestedAt time.Time
+}
+
+// Approver forwards approval requests to an external process, such as a
+// ticket system or a two-person rule, which later calls ApproveKey or
+// RejectKey. An error leaves the key pending.
+type Approver interface {
+	RequestApproval(ctx context.Context, req ApprovalRequest) error
+}
+
+type ApproverFunc func(ctx context.Context, req ApprovalRequest) error
+
+func (f ApproverFunc) RequestApproval(ctx context.Context, req ApprovalRequest) error {
+	return f(ctx, req)
+}
+
+// pendingIn reports whether s already has a key awaiting approval, so
+// repeated rotations do not pile up requests.
+func pendingIn(keys []*Key, s keySlot) bool {
+	for _, k := range keys {
+		if k.Pending && k.RevokedAt == nil && slotOf(k) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (km *KeyManager) requestApproval(ctx context.Context, k, oldKey *Key) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	k.IsActive = false
+	k.Pending = true
+	if err := saver.Save(k); err != nil {
+		return err
+	}
+
+	req := ApprovalRequest{
+		KID:         k.KID,
+		Alg:         k.Alg,
+		Purpose:     k.Purpose,
+		Labels:      maps.Clone(k.Labels),
+		RequestedAt: k.CreatedAt,
+	}
+	if oldKey != nil {
+		req.PreviousKID = oldKey.KID
+	}
+
+	km.logger().InfoContext(ctx, "key pending approval", "kid", k.KID, "alg", k.Alg)
+	km.publish(ctx, LifecycleEvent{Type: EventKeyPending, KID: k.KID, Alg: k.Alg, Purpose: k.Purpose, PreviousKID: req.PreviousKID})
+
+	if err := km.approver.RequestApproval(ctx, req); err != nil {
+		return fmt.Errorf("request approval for %s: %w", k.KID, err)
+	}
+
+	return km.reload(ctx)
+}
+
+// ApproveKey activates a pending key, retiring the active key of its slot.
+// Under a PropagationDelay the key is staged instead and activates once
+// the delay has passed.
+func (km *KeyManager) ApproveKey(ctx context.Context, kid string) error {
+	if km.verifyOnly {
+		return ErrVerifyOnly
+	}
+	if km.closed.Load() {
+		return ErrClosed
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending, err := findPending(keys, kid)
+	if err != nil {
+		return err
+	}
+	if err := km.authorize(ctx, ActionRotate, pending); err != nil {
+		return err
+	}
+
+	approved := cloneKey(pending)
+	approved.Pending = false
+
+	policy, err := km.rotationConfigFor(approved.Purpose)
+	if err != nil {
+		return err
+	}
+	retired := km.retireActive(keys, slotOf(approved), approved.KID)
+	if policy.PropagationDelay > 0 && len(retired) > 0 {
+		return km.stage(ctx, approved, retired[0], policy.PropagationDelay)
+	}
+
+	return km.promote(ctx, keys, approved)
+}
+
+// promote stores k as the active key of its slot, retiring every current
+// one, and reloads.
+func (km *KeyManager) promote(ctx context.Context, keys []*Key, k *Key) error {
+	now := km.clock.Now()
+	k.IsActive = true
+	k.Staged = false
+	k.UpdatedAt = now
+
+	retired := km.retireActive(keys, slotOf(k), k.KID)
+	if err := km.storeRotateAll(ctx, k, retired); err != nil {
+		return err
+	}
+
+	var oldKID string
+	if len(retired) > 0 {
+		oldKID = retired[0].KID
+	}
+	km.logRotate(ctx, slotOf(k), k.KID, oldKID, nil)
+	km.publish(ctx, LifecycleEvent{Type: EventKeyRotated, KID: k.KID, Alg: k.Alg, Purpose: k.Purpose, PreviousKID: oldKID})
+
+	return km.reload(ctx)
+}
+
+// RejectKey revokes a pending key.
+func (km *KeyManager) RejectKey(ctx context.Context, kid string) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending, err := findPending(keys, kid)
+	if err != nil {
+		return err
+	}
+	if err := km.authorize(ctx, ActionRevoke, pending); err != nil {
+		return err
+	}
+
+	now := km.clock.Now()
+	rejected := cloneKey(pending)
+	rejected.Pending = false
+	rejected.RevokedAt = &now
+	rejected.UpdatedAt = now
+
+	if err := saver.Save(rejected); err != nil {
+		return err
+	}
+
+	km.logger().InfoContext(ctx, "pending key rejected", "kid", kid, "alg", rejected.Alg)
+	km.publish(ctx, LifecycleEvent{Type: EventKeyRevoked, KID: kid, Alg: rejected.Alg, Purpose: rejected.Purpose})
+
+	return km.reload(ctx)
+}
+
+func findPending(keys []*Key, kid string) (*Key, error) {
+	for _, k := range keys {
+		if k.KID != kid {
+			continue
+		}
+		if !k.Pending || k.RevokedAt != nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotPending, kid)
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
@@ -0,0 +1,100 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type recordingApprover struct {
+	requests []ApprovalRequest
+}
+
+func (a *recordingApprover) RequestApproval(_ context.Context, req ApprovalRequest) error {
+	a.requests = append(a.requests, req)
+	return nil
+}
+
+func TestApproval_RotateWaitsForApproval(t *testing.T) {
+	approver := &recordingApprover{}
+	store := NewMockStore()
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithApprover(approver))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if len(approver.requests) != 1 {
+		t.Fatalf("expected one approval request, got %d", len(approver.requests))
+	}
+	kid := approver.requests[0].KID
+
+	if km.activeKey(AlgEdDSA) != nil {
+		t.Fatalf("pending key must not be active")
+	}
+	if k := store.data[kid]; !k.Pending || k.IsActive || keyState(k) != KeyStatePending {
+		t.Fatalf("key must be stored as pending, got %+v", k)
+	}
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if len(approver.requests) != 1 || len(store.data) != 1 {
+		t.Fatalf("a slot with a pending key must not get another one")
+	}
+
+	if err := km.ApproveKey(t.Context(), kid); err != nil {
+		t.Fatalf("ApproveKey error: %v", err)
+	}
+	if ck := km.activeKey(AlgEdDSA); ck == nil || ck.key.KID != kid {
+		t.Fatalf("approved key must be active, got %v", ck)
+	}
+	if err := km.ApproveKey(t.Context(), kid); !errors.Is(err, ErrNotPending) {
+		t.Fatalf("expected ErrNotPending, got %v", err)
+	}
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	next := approver.requests[1]
+	if next.PreviousKID != kid {
+		t.Fatalf("request must name the key it replaces, got %q", next.PreviousKID)
+	}
+
+	if err := km.RejectKey(t.Context(), next.KID); err != nil {
+		t.Fatalf("RejectKey error: %v", err)
+	}
+	if k := store.data[next.KID]; k.RevokedAt == nil || k.Pending {
+		t.Fatalf("rejected key must be revoked, got %+v", k)
+	}
+	if ck := km.activeKey(AlgEdDSA); ck.key.KID != kid {
+		t.Fatalf("rejection must keep the current key active, got %s", ck.key.KID)
+	}
+}
+
+func TestApproval_FailedRequestKeepsKeyPending(t *testing.T) {
+	store := NewMockStore()
+	approver := ApproverFunc(func(context.Context, ApprovalRequest) error {
+		return errors.New("ticket system down")
+	})
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithApprover(approver))
+
+	if err := km.Rotate(AlgEdDSA); err == nil {
+		t.Fatalf("failed approval request must be reported")
+	}
+	if len(store.data) != 1 {
+		t.Fatalf("key must stay stored as pending")
+	}
+	for kid := range store.data {
+		if err := km.ApproveKey(t.Context(), kid); err != nil {
+			t.Fatalf("ApproveKey error: %v", err)
+		}
+	}
+	if km.activeKey(AlgEdDSA) == nil {
+		t.Fatalf("approved key must be active")
+	}
+}
@@ -0,0 +1,59 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+)
+
+// Key origins recorded in Attestation.Origin.
+const (
+	OriginGenerated = "generated"
+	OriginImported  = "imported"
+)
+
+// ProtectionSoftware is the protection level of keys generated in process
+// and stored encrypted, recorded when no Attester is configured.
+const ProtectionSoftware = "software"
+
+// Attestation records where a key came from and how its material is
+// protected, as reported by an HSM or KMS backend.
+type Attestation struct {
+	Origin          string `json:"origin"`
+	ProtectionLevel string `json:"protection_level"`
+
+	// Backend names the protecting key or device, e.g. a KMS key ARN.
+	Backend string `json:"backend,omitempty"`
+
+	// Evidence is the backend's raw attestation statement, if any.
+	Evidence []byte `json:"evidence,omitempty"`
+}
+
+// Attester is implemented by encryptors backed by an HSM or KMS that can
+// attest to new keys. Attest is called before the key is stored; an empty
+// Origin is filled in by the manager.
+type Attester interface {
+	Attest(ctx context.Context, k *Key) (*Attestation, error)
+}
+
+func (km *KeyManager) attest(ctx context.Context, k *Key, origin string) (*Attestation, error) {
+	a := km.attester
+	if a == nil {
+		a, _ = km.encryptor.(Attester)
+	}
+	if a == nil {
+		return &Attestation{Origin: origin, ProtectionLevel: ProtectionSoftware}, nil
+	}
+
+	att, err := a.Attest(ctx, k)
+	if err != nil {
+		return nil, fmt.Errorf("attest key %s: %w", k.KID, err)
+	}
+	if att == nil {
+		att = &Attestation{ProtectionLevel: ProtectionSoftware}
+	}
+	if att.Origin == "" {
+		att.Origin = origin
+	}
+
+	return att, nil
+}
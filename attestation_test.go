@@ -0,0 +1,80 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type kmsEncryptor struct {
+	MockEncryptor
+	err error
+}
+
+func (e kmsEncryptor) Attest(ctx context.Context, k *Key) (*Attestation, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &Attestation{ProtectionLevel: "hsm", Backend: "arn:aws:kms:eu-west-1:1:key/a"}, nil
+}
+
+func TestAttestation_RecordedFromEncryptor(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, kmsEncryptor{}, mockPolicy, WithJWKSAttestation())
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	keys, _ := store.List()
+	att := keys[0].Attestation
+	if att == nil || att.Origin != OriginGenerated || att.ProtectionLevel != "hsm" {
+		t.Fatalf("unexpected stored attestation %+v", att)
+	}
+
+	if info := km.Keys()[0]; info.Attestation == nil || info.Attestation.Backend != att.Backend {
+		t.Fatalf("KeyInfo must expose the attestation, got %+v", info.Attestation)
+	}
+
+	raw, _ := km.JWKS()
+	var jwks JWKS
+	_ = json.Unmarshal(raw, &jwks)
+	if a := jwks.Keys[0].Attestation; a == nil || a.ProtectionLevel != "hsm" {
+		t.Fatalf("JWKS must carry the attestation, got %s", raw)
+	}
+}
+
+func TestAttestation_DefaultsAndJWKSOptIn(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	kid, err := km.ImportKey(AlgEdDSA, priv, true)
+	if err != nil {
+		t.Fatalf("ImportKey error: %v", err)
+	}
+
+	info := km.Keys()[0]
+	if info.KID != kid || info.Attestation == nil || info.Attestation.Origin != OriginImported || info.Attestation.ProtectionLevel != ProtectionSoftware {
+		t.Fatalf("unexpected attestation %+v", info.Attestation)
+	}
+
+	raw, _ := km.JWKS()
+	if strings.Contains(string(raw), "attestation") {
+		t.Fatalf("JWKS must not carry attestations by default, got %s", raw)
+	}
+}
+
+func TestAttestation_FailureStopsRotation(t *testing.T) {
+	boom := errors.New("kms unavailable")
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, kmsEncryptor{err: boom}, mockPolicy)
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); !errors.Is(err, boom) {
+		t.Fatalf("expected attestation error, got %v", err)
+	}
+	if keys, _ := store.List(); len(keys) != 0 {
+		t.Fatalf("unattested key must not be stored")
+	}
+}
@@ -0,0 +1,77 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type Action string
+
+const (
+	ActionSign   Action = "sign"
+	ActionRotate Action = "rotate"
+	ActionRevoke Action = "revoke"
+	ActionExport Action = "export"
+	ActionDelete Action = "delete"
+)
+
+// AccessRequest describes an operation on keys about to be performed. KID
+// is empty for rotations, which create the key.
+type AccessRequest struct {
+	Action  Action
+	Caller  string
+	KID     string
+	Alg     Alg
+	Purpose Purpose
+	Labels  map[string]string
+}
+
+// Authorizer is consulted before signing with, rotating, revoking or
+// exporting a key. The caller comes from WithCaller. A non-nil error denies
+// the request; it is returned wrapped in ErrForbidden. Rotations the
+// manager performs on its own, such as RotateExpired and scheduled
+// rotation, are not authorized.
+type Authorizer interface {
+	Authorize(ctx context.Context, req AccessRequest) error
+}
+
+type AuthorizerFunc func(ctx context.Context, req AccessRequest) error
+
+func (f AuthorizerFunc) Authorize(ctx context.Context, req AccessRequest) error {
+	return f(ctx, req)
+}
+
+// PurposeAuthorizer restricts every action on keys of a listed purpose to
+// the listed callers. Purposes that are not listed are open to everyone.
+type PurposeAuthorizer map[Purpose][]string
+
+func (p PurposeAuthorizer) Authorize(_ context.Context, req AccessRequest) error {
+	callers, ok := p[req.Purpose]
+	if !ok || slices.Contains(callers, req.Caller) {
+		return nil
+	}
+	return fmt.Errorf("caller %q may not %s keys of purpose %q", req.Caller, req.Action, req.Purpose)
+}
+
+func (km *KeyManager) authorize(ctx context.Context, action Action, k *Key) error {
+	return km.authorizeRequest(ctx, AccessRequest{
+		Action:  action,
+		KID:     k.KID,
+		Alg:     k.Alg,
+		Purpose: k.Purpose,
+		Labels:  k.Labels,
+	})
+}
+
+func (km *KeyManager) authorizeRequest(ctx context.Context, req AccessRequest) error {
+	if km.authorizer == nil {
+		return nil
+	}
+
+	req.Caller = CallerFromContext(ctx)
+	if err := km.authorizer.Authorize(ctx, req); err != nil {
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAuthorizer_PurposeRestrictedToCaller(t *testing.T) {
+	authz := PurposeAuthorizer{PurposeAccess: {"token-service"}}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithAuthorizer(authz))
+
+	tokenSvc := WithCaller(context.Background(), "token-service")
+	other := WithCaller(context.Background(), "billing")
+
+	if err := km.RotateWithPurposeCtx(other, AlgEdDSA, PurposeAccess); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("other caller must not rotate access keys, got %v", err)
+	}
+	if err := km.RotateWithPurposeCtx(tokenSvc, AlgEdDSA, PurposeAccess); err != nil {
+		t.Fatalf("token service must rotate access keys: %v", err)
+	}
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("unrestricted purpose must stay open: %v", err)
+	}
+
+	build := func(kid string) ([]byte, error) { return []byte("x"), nil }
+
+	if _, err := km.SignWithPurposeCtx(other, AlgEdDSA, PurposeAccess, build); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("other caller must not sign with access keys, got %v", err)
+	}
+	if _, err := km.SignWithPurposeCtx(tokenSvc, AlgEdDSA, PurposeAccess, build); err != nil {
+		t.Fatalf("token service must sign: %v", err)
+	}
+	if _, err := km.SignCtx(other, AlgEdDSA, build); err != nil {
+		t.Fatalf("default purpose must stay open: %v", err)
+	}
+
+	if _, err := km.ExportCtx(other); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("export including access keys must be denied, got %v", err)
+	}
+
+	kid := km.activeKeyFor(context.Background(), keySlot{alg: AlgEdDSA, purpose: PurposeAccess}).key.KID
+	if _, err := km.Signer(kid); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("handing out the signer without a caller must be denied, got %v", err)
+	}
+	if err := km.RevokeCtx(other, kid); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("other caller must not revoke access keys, got %v", err)
+	}
+	if err := km.RevokeCtx(tokenSvc, kid); err != nil {
+		t.Fatalf("token service must revoke: %v", err)
+	}
+}
+
+func TestAuthorizer_ReceivesRequest(t *testing.T) {
+	var got []AccessRequest
+	authz := AuthorizerFunc(func(ctx context.Context, req AccessRequest) error {
+		got = append(got, req)
+		return nil
+	})
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithAuthorizer(authz))
+	_ = km.RotateWithPurposeCtx(WithCaller(context.Background(), "svc"), AlgEdDSA, PurposeWebhook)
+	_, _ = km.SignWebhookCtx(WithCaller(context.Background(), "svc"), []byte("x"))
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 requests, got %+v", got)
+	}
+	if r := got[0]; r.Action != ActionRotate || r.Caller != "svc" || r.Purpose != PurposeWebhook || r.KID != "" {
+		t.Fatalf("unexpected rotate request %+v", r)
+	}
+	if r := got[1]; r.Action != ActionSign || r.KID == "" || r.Alg != AlgEdDSA {
+		t.Fatalf("unexpected sign request %+v", r)
+	}
+}
@@ -0,0 +1,90 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// background tracks goroutines owned by the manager.
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+func (b *background) goFunc(fn func(ctx context.Context)) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn(b.ctx)
+	}()
+}
+
+func (b *background) stop() {
+	b.cancel()
+	b.wg.Wait()
+}
+
+// stopCtx is stop bounded by ctx; goroutines that are still running when
+// ctx is done are left to exit on their own.
+func (b *background) stopCtx(ctx context.Context) error {
+	b.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refreshLoop reloads the cache every interval. Readers keep using the
+// current cache while a refresh is running, and a failed refresh leaves it
+// in place.
+func (km *KeyManager) refreshLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			km.reportError(OpBackgroundRefresh, "", km.reload(withReloadCause(ctx, ReloadScheduled)))
+		}
+	}
+}
+
+// rotateLoop rotates expired active keys every interval. Failures are
+// logged and retried on the next tick.
+func (km *KeyManager) rotateLoop(ctx context.Context, interval time.Duration, match func(Purpose) bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := km.rotateExpired(ctx, match, func(kid string, err error) {
+				km.reportError(OpScheduledRotation, kid, err)
+			})
+			if err != nil && !errors.Is(err, ErrClosed) {
+				km.logger().WarnContext(ctx, "scheduled rotation failed", "err", err)
+			}
+		}
+	}
+}
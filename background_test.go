@@ -0,0 +1,111 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type flakyStore struct {
+	*MockStore
+	fail atomic.Bool
+}
+
+func (s *flakyStore) List() ([]*Key, error) {
+	if s.fail.Load() {
+		return nil, errors.New("list failed")
+	}
+	return s.MockStore.List()
+}
+
+func TestBackgroundRefresh_PicksUpExternalRotation(t *testing.T) {
+	store := NewMockStore()
+
+	other, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy,
+		WithBackgroundRefresh(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	if err := other.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if km.snapshot().active[keySlot{alg: AlgEdDSA}] != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background refresh did not pick up the new key")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBackgroundRefresh_FailureKeepsCache(t *testing.T) {
+	store := &flakyStore{MockStore: NewMockStore()}
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy,
+		WithBackgroundRefresh(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	store.fail.Store(true)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := km.Sign(AlgEdDSA, func(_ string) ([]byte, error) {
+		return []byte("data"), nil
+	}); err != nil {
+		t.Fatalf("cache must survive failed refreshes: %v", err)
+	}
+}
+
+func TestBackground_StopWaitsForGoroutines(t *testing.T) {
+	b := newBackground()
+
+	exited := make(chan struct{})
+	b.goFunc(func(ctx context.Context) {
+		<-ctx.Done()
+		close(exited)
+	})
+
+	b.stop()
+
+	select {
+	case <-exited:
+	default:
+		t.Fatalf("stop returned before goroutine exited")
+	}
+}
+
+func TestRotationSchedule_RotatesExpiredKeys(t *testing.T) {
+	store := NewMockStore()
+
+	past := time.Now().Add(-time.Minute)
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	_ = store.Save(makeTestKey("expired", AlgEdDSA, true, &past, MockEncryptor{}, priv))
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy,
+		WithRotationSchedule(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	waitFor(t, func() bool {
+		ck := km.activeKey(AlgEdDSA)
+		return ck != nil && ck.key.KID != "expired"
+	})
+}
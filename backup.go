@@ -0,0 +1,220 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	backupFormat  = "keys-manager-backup"
+	backupVersion = 1
+
+	backupKDF        = "pbkdf2-sha256"
+	backupIterations = 600_000
+	backupSaltSize   = 16
+
+	// Restore refuses archives outside these bounds: too few iterations
+	// weaken the passphrase, too many let a crafted archive pin a CPU.
+	minBackupIterations = 100_000
+	maxBackupIterations = 10_000_000
+)
+
+// backupArchive is the on-disk backup. The keyset inside is a snapshot
+// whose key material is encrypted under the passphrase-derived key instead
+// of the manager's Encryptor, so a backup restores without the original
+// master key.
+type backupArchive struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func backupEncryptor(passphrase string, salt []byte, iterations int) (*AESGCMEncryptor, error) {
+	if passphrase == "" {
+		return nil, errors.New("backup: empty passphrase")
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("backup: derive key: %w", err)
+	}
+	return NewAESGCMEncryptor(key)
+}
+
+// Backup writes every stored key to w as an authenticated archive
+// encrypted with a key derived from passphrase. Private keys are decrypted
+// with the Encryptor and re-encrypted under the backup key. Every key is
+// authorized for ActionExport.
+func (km *KeyManager) Backup(w io.Writer, passphrase string) error {
+	if km.verifyOnly {
+		return ErrVerifyOnly
+	}
+
+	ctx := context.Background()
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, backupSaltSize)
+	if _, err := io.ReadFull(km.entropy(), salt); err != nil {
+		return err
+	}
+
+	enc, err := backupEncryptor(passphrase, salt, backupIterations)
+	if err != nil {
+		return err
+	}
+
+	snap := snapshot{
+		Version:    snapshotVersion,
+		ExportedAt: km.clock.Now().UTC(),
+		Keys:       make([]snapshotKey, 0, len(keys)),
+	}
+
+	for _, k := range keys {
+		if err := km.authorize(ctx, ActionExport, k); err != nil {
+			return err
+		}
+
+		plain, err := km.decrypt(ctx, k)
+		if err != nil {
+			return &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+		}
+
+		ek, err := enc.Encrypt(plain)
+		wipeBytes(plain)
+		if err != nil {
+			return err
+		}
+
+		snap.Keys = append(snap.Keys, toSnapshotKey(k, ek))
+	}
+
+	sort.Slice(snap.Keys, func(i, j int) bool {
+		a, b := snap.Keys[i], snap.Keys[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.KID < b.KID
+	})
+
+	plain, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("marshal backup: %w", err)
+	}
+
+	sealed, err := enc.Encrypt(plain)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(backupArchive{
+		Format:     backupFormat,
+		Version:    backupVersion,
+		KDF:        backupKDF,
+		Iterations: backupIterations,
+		Salt:       salt,
+		Nonce:      sealed.Nonce,
+		Ciphertext: sealed.Ciphertext,
+	})
+}
+
+// Restore reads an archive written by Backup and saves its keys into the
+// store, encrypted with the manager's Encryptor. Keys whose KID is already
+// stored are left untouched. Nothing is saved unless every key in the
+// archive decrypts and parses. A KeyBatchSaver store gets all keys in one
+// transaction; with any other store a failed save deletes the keys already
+// restored, which needs a KeyDeleter. The store must implement KeySaver.
+func (km *KeyManager) Restore(r io.Reader, passphrase string) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	var archive backupArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return fmt.Errorf("parse backup: %w", err)
+	}
+	if archive.Format != backupFormat || archive.Version != backupVersion {
+		return fmt.Errorf("unsupported backup %q version %d", archive.Format, archive.Version)
+	}
+	if archive.KDF != backupKDF {
+		return fmt.Errorf("unsupported backup kdf %q", archive.KDF)
+	}
+	if archive.Iterations < minBackupIterations || archive.Iterations > maxBackupIterations {
+		return fmt.Errorf("unsupported backup iteration count %d", archive.Iterations)
+	}
+
+	enc, err := backupEncryptor(passphrase, archive.Salt, archive.Iterations)
+	if err != nil {
+		return err
+	}
+
+	plain, err := enc.Decrypt(&EncryptedKey{Nonce: archive.Nonce, Ciphertext: archive.Ciphertext})
+	if err != nil {
+		return ErrBackupPassphrase
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(plain, &snap); err != nil {
+		return fmt.Errorf("parse backup: %w", err)
+	}
+
+	ctx := context.Background()
+
+	existing, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, k := range existing {
+		known[k.KID] = true
+	}
+
+	var toSave []*Key
+	for _, sk := range snap.Keys {
+		if known[sk.KID] {
+			continue
+		}
+		if err := km.checkEnvironment(sk.envKey()); err != nil {
+			return err
+		}
+
+		material, err := enc.Decrypt(&EncryptedKey{Nonce: sk.Nonce, Ciphertext: sk.Ciphertext})
+		if err != nil {
+			return &KeyError{KID: sk.KID, Alg: sk.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+		}
+
+		ek, err := km.encrypt(ctx, material)
+		wipeBytes(material)
+		if err != nil {
+			return err
+		}
+
+		k := sk.key(ek)
+		if _, err := km.loadKey(ctx, k); err != nil {
+			return err
+		}
+
+		toSave = append(toSave, k)
+	}
+
+	if err := km.saveAll(saver, toSave, nil); err != nil {
+		return err
+	}
+
+	return km.ReloadCache()
+}
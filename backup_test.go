@@ -0,0 +1,119 @@
+package keys_manager
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestBackup_RestoresUnderNewMasterKey(t *testing.T) {
+	oldEnc, _ := NewAESGCMEncryptor(bytes.Repeat([]byte{1}, 32))
+	src, _ := NewKeyManager(NewMockStore(), oldEnc, mockPolicy)
+	if err := src.InitKeys([]Alg{AlgEdDSA, AlgES256}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+	_ = src.InitKeysWithPurpose(PurposeCookie, []Alg{AlgA256GCM})
+
+	var buf bytes.Buffer
+	if err := src.Backup(&buf, "correct horse"); err != nil {
+		t.Fatalf("Backup error: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(src.activeKey(AlgEdDSA).key.KID)) {
+		t.Fatalf("backup must not reveal key metadata in the clear")
+	}
+
+	newEnc, _ := NewAESGCMEncryptor(bytes.Repeat([]byte{2}, 32))
+	dst, _ := NewKeyManager(NewMockStore(), newEnc, mockPolicy)
+
+	if err := dst.Restore(bytes.NewReader(buf.Bytes()), "wrong"); !errors.Is(err, ErrBackupPassphrase) {
+		t.Fatalf("expected ErrBackupPassphrase, got %v", err)
+	}
+
+	if err := dst.Restore(bytes.NewReader(buf.Bytes()), "correct horse"); err != nil {
+		t.Fatalf("Restore error: %v", err)
+	}
+
+	for _, alg := range []Alg{AlgEdDSA, AlgES256} {
+		want, got := src.activeKey(alg), dst.activeKey(alg)
+		if got == nil || got.key.KID != want.key.KID {
+			t.Fatalf("active %s key not restored", alg)
+		}
+	}
+
+	sealed, _ := src.EncryptCookie("s", []byte("v"))
+	if _, err := dst.DecryptCookie("s", sealed); err != nil {
+		t.Fatalf("restored cookie key must open cookies: %v", err)
+	}
+
+	if err := dst.Restore(bytes.NewReader(buf.Bytes()), "correct horse"); err != nil {
+		t.Fatalf("restoring again must skip known keys: %v", err)
+	}
+	if n := len(dst.Keys()); n != 3 {
+		t.Fatalf("expected 3 keys, got %d", n)
+	}
+}
+
+func TestBackup_RejectsTamperedArchive(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	var buf bytes.Buffer
+	if err := km.Backup(&buf, "pass"); err != nil {
+		t.Fatalf("Backup error: %v", err)
+	}
+
+	b := buf.Bytes()
+	i := bytes.Index(b, []byte(`"ciphertext":"`)) + len(`"ciphertext":"`)
+	if b[i] == 'A' {
+		b[i] = 'B'
+	} else {
+		b[i] = 'A'
+	}
+
+	dst, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err := dst.Restore(bytes.NewReader(b), "pass"); !errors.Is(err, ErrBackupPassphrase) {
+		t.Fatalf("tampered archive must be rejected, got %v", err)
+	}
+	if len(dst.Keys()) != 0 {
+		t.Fatalf("nothing must be restored from a tampered archive")
+	}
+}
+
+func TestBackup_RejectsIterationsOutOfBounds(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	var buf bytes.Buffer
+	if err := km.Backup(&buf, "pass"); err != nil {
+		t.Fatalf("Backup error: %v", err)
+	}
+
+	for _, n := range []string{"0", "1000", "2000000000"} {
+		b := bytes.Replace(buf.Bytes(), []byte(`"iterations":600000`), []byte(`"iterations":`+n), 1)
+
+		dst, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+		err := dst.Restore(bytes.NewReader(b), "pass")
+		if err == nil || errors.Is(err, ErrBackupPassphrase) {
+			t.Fatalf("%s iterations must be refused before deriving a key, got %v", n, err)
+		}
+	}
+}
+
+func TestBackup_RestoreRollsBackFailedSave(t *testing.T) {
+	src, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = src.InitKeys([]Alg{AlgEdDSA, AlgES256, AlgRS256})
+
+	var buf bytes.Buffer
+	if err := src.Backup(&buf, "pass"); err != nil {
+		t.Fatalf("Backup error: %v", err)
+	}
+
+	store := &unbatchedStore{mock: NewMockStore(), failAt: 2}
+	dst, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err := dst.Restore(bytes.NewReader(buf.Bytes()), "pass"); err == nil {
+		t.Fatalf("expected Restore to fail")
+	}
+	if keys, _ := store.mock.List(); len(keys) != 0 {
+		t.Fatalf("a failed restore must leave no keys behind, got %d", len(keys))
+	}
+}
@@ -0,0 +1,209 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrStateMismatch = errors.New("key state does not match expectations")
+
+// StateSpec describes the key state infrastructure code expects to find,
+// e.g. rows provisioned by Terraform and the KMS key protecting them.
+type StateSpec struct {
+	// EncryptorRef must equal the KeyRef of the manager's encryptor, such
+	// as a KMS key ARN. The encryptor must implement KeyReferencer.
+	EncryptorRef string `json:"encryptor_ref,omitempty"`
+
+	Keys  []ExpectedKey  `json:"keys,omitempty"`
+	Slots []ExpectedSlot `json:"slots,omitempty"`
+
+	// CreateMissing lets ImportState give slots without an active key a
+	// new one instead of reporting them.
+	CreateMissing bool `json:"create_missing,omitempty"`
+}
+
+// ExpectedKey is a key that must already be stored. Empty fields are not
+// checked; Labels must be present with the given values but the key may
+// carry others.
+type ExpectedKey struct {
+	KID     string            `json:"kid"`
+	Alg     Alg               `json:"alg,omitempty"`
+	Purpose Purpose           `json:"purpose,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Active  *bool             `json:"active,omitempty"`
+
+	// PublicKeySHA256 is the hex SHA-256 of the PKIX encoded public key.
+	PublicKeySHA256 string `json:"public_key_sha256,omitempty"`
+}
+
+// ExpectedSlot is a line of keys that must have an active key.
+type ExpectedSlot struct {
+	Alg     Alg               `json:"alg"`
+	Purpose Purpose           `json:"purpose,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// KeyReferencer is implemented by encryptors that can name the external
+// key they use, e.g. a KMS key ARN.
+type KeyReferencer interface {
+	KeyRef() string
+}
+
+// StateDiff is one difference between a StateSpec and the store.
+type StateDiff struct {
+	Subject string `json:"subject"`
+	Field   string `json:"field"`
+	Want    string `json:"want"`
+	Got     string `json:"got"`
+}
+
+func (d StateDiff) String() string {
+	return fmt.Sprintf("%s: %s: want %s, got %s", d.Subject, d.Field, d.Want, d.Got)
+}
+
+// StateError lists every difference found by ImportState.
+type StateError struct {
+	Diffs []StateDiff
+}
+
+func (e *StateError) Error() string {
+	lines := make([]string, len(e.Diffs))
+	for i, d := range e.Diffs {
+		lines[i] = "  " + d.String()
+	}
+	return fmt.Sprintf("%v:\n%s", ErrStateMismatch, strings.Join(lines, "\n"))
+}
+
+func (e *StateError) Unwrap() error {
+	return ErrStateMismatch
+}
+
+// PlanState compares spec with the store and returns the differences
+// without changing anything. Slots that ImportState would fill because of
+// CreateMissing are not reported.
+func (km *KeyManager) PlanState(ctx context.Context, spec StateSpec) ([]StateDiff, error) {
+	diffs, _, err := km.planState(ctx, spec)
+	return diffs, err
+}
+
+// ImportState reconciles the manager with spec on first boot. It fails
+// with a *StateError listing every difference, and changes nothing in
+// that case. Otherwise, with CreateMissing set, it creates active keys
+// for the expected slots that lack one.
+func (km *KeyManager) ImportState(ctx context.Context, spec StateSpec) error {
+	diffs, missing, err := km.planState(ctx, spec)
+	if err != nil {
+		return err
+	}
+	if len(diffs) > 0 {
+		return &StateError{Diffs: diffs}
+	}
+
+	for _, s := range missing {
+		if err := km.authorizedRotate(ctx, s.Alg, s.Purpose, s.Labels); err != nil {
+			return fmt.Errorf("create key for %s: %w", slotOfExpected(s), err)
+		}
+	}
+
+	return nil
+}
+
+func (km *KeyManager) planState(ctx context.Context, spec StateSpec) ([]StateDiff, []ExpectedSlot, error) {
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var diffs []StateDiff
+	add := func(subject, field, want, got string) {
+		diffs = append(diffs, StateDiff{Subject: subject, Field: field, Want: want, Got: got})
+	}
+
+	if spec.EncryptorRef != "" {
+		ref, ok := km.encryptor.(KeyReferencer)
+		switch {
+		case !ok:
+			add("encryptor", "key_ref", spec.EncryptorRef, fmt.Sprintf("unsupported by %T", km.encryptor))
+		case ref.KeyRef() != spec.EncryptorRef:
+			add("encryptor", "key_ref", spec.EncryptorRef, ref.KeyRef())
+		}
+	}
+
+	byKID := make(map[string]*Key, len(keys))
+	active := make(map[keySlot]*Key)
+	for _, k := range keys {
+		byKID[k.KID] = k
+		if k.IsActive && k.RevokedAt == nil {
+			active[slotOf(k)] = k
+		}
+	}
+
+	for _, want := range spec.Keys {
+		subject := "key " + want.KID
+
+		k := byKID[want.KID]
+		if k == nil {
+			add(subject, "present", "true", "false")
+			continue
+		}
+
+		if want.Alg != "" && want.Alg != k.Alg {
+			add(subject, "alg", string(want.Alg), string(k.Alg))
+		}
+		if want.Purpose != "" && want.Purpose != k.Purpose {
+			add(subject, "purpose", string(want.Purpose), string(k.Purpose))
+		}
+		for name, v := range want.Labels {
+			if got, ok := k.Labels[name]; !ok || got != v {
+				add(subject, "label "+name, v, labelOrNone(got, ok))
+			}
+		}
+		if want.Active != nil && *want.Active != (k.IsActive && k.RevokedAt == nil) {
+			add(subject, "active", fmt.Sprint(*want.Active), fmt.Sprint(k.IsActive && k.RevokedAt == nil))
+		}
+		if want.PublicKeySHA256 != "" {
+			got := "none"
+			if len(k.PublicKey) > 0 {
+				sum := sha256.Sum256(k.PublicKey)
+				got = hex.EncodeToString(sum[:])
+			}
+			if !strings.EqualFold(got, want.PublicKeySHA256) {
+				add(subject, "public_key_sha256", want.PublicKeySHA256, got)
+			}
+		}
+	}
+
+	var missing []ExpectedSlot
+	for _, want := range spec.Slots {
+		if _, ok := active[slotOfExpected(want)]; ok {
+			continue
+		}
+		if spec.CreateMissing && !km.verifyOnly {
+			missing = append(missing, want)
+			continue
+		}
+		add(slotOfExpected(want).String(), "active key", "present", "none")
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Subject < diffs[j].Subject
+	})
+
+	return diffs, missing, nil
+}
+
+func slotOfExpected(s ExpectedSlot) keySlot {
+	return keySlot{alg: s.Alg, purpose: s.Purpose, labels: formatLabels(s.Labels)}
+}
+
+func labelOrNone(v string, ok bool) string {
+	if !ok {
+		return "none"
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type refEncryptor struct {
+	MockEncryptor
+	ref string
+}
+
+func (e refEncryptor) KeyRef() string { return e.ref }
+
+func TestImportState_MatchesProvisionedKeys(t *testing.T) {
+	enc := refEncryptor{ref: "arn:aws:kms:eu-west-1:1:key/a"}
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	k := makeTestKey("provisioned", AlgEdDSA, true, nil, enc, priv)
+	k.Labels = map[string]string{"tenant": "acme"}
+	k.PublicKey, _ = marshalPublicKey(priv.Public())
+	_ = store.Save(k)
+	sum := sha256.Sum256(k.PublicKey)
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	active := true
+	spec := StateSpec{
+		EncryptorRef: enc.ref,
+		Keys: []ExpectedKey{{
+			KID:             "provisioned",
+			Alg:             AlgEdDSA,
+			Labels:          map[string]string{"tenant": "acme"},
+			Active:          &active,
+			PublicKeySHA256: hex.EncodeToString(sum[:]),
+		}},
+		Slots: []ExpectedSlot{{Alg: AlgEdDSA, Labels: map[string]string{"tenant": "acme"}}},
+	}
+
+	if err := km.ImportState(context.Background(), spec); err != nil {
+		t.Fatalf("ImportState error: %v", err)
+	}
+}
+
+func TestImportState_ReportsEveryDiff(t *testing.T) {
+	store := NewMockStore()
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	_ = store.Save(makeTestKey("k1", AlgEdDSA, false, nil, MockEncryptor{}, priv))
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	active := true
+	spec := StateSpec{
+		EncryptorRef: "arn:aws:kms:eu-west-1:1:key/a",
+		Keys: []ExpectedKey{
+			{KID: "k1", Alg: AlgRS256, Active: &active},
+			{KID: "missing"},
+		},
+		Slots: []ExpectedSlot{{Alg: AlgES256}},
+	}
+
+	err := km.ImportState(context.Background(), spec)
+	if !errors.Is(err, ErrStateMismatch) {
+		t.Fatalf("expected ErrStateMismatch, got %v", err)
+	}
+
+	var se *StateError
+	if !errors.As(err, &se) || len(se.Diffs) != 5 {
+		t.Fatalf("expected 5 diffs, got %v", err)
+	}
+	for _, want := range []string{"encryptor: key_ref", "key k1: alg", "key k1: active", "key missing: present", "active key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error must mention %q:\n%v", want, err)
+		}
+	}
+
+	if keys, _ := store.List(); len(keys) != 1 {
+		t.Fatalf("failed import must not change the store, got %d keys", len(keys))
+	}
+}
+
+func TestImportState_CreateMissing(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	spec := StateSpec{
+		Slots:         []ExpectedSlot{{Alg: AlgEdDSA, Purpose: "id_token"}},
+		CreateMissing: true,
+	}
+
+	diffs, err := km.PlanState(context.Background(), spec)
+	if err != nil || len(diffs) != 0 {
+		t.Fatalf("slots to be created must not be reported, got %v %v", diffs, err)
+	}
+
+	if err := km.ImportState(context.Background(), spec); err != nil {
+		t.Fatalf("ImportState error: %v", err)
+	}
+	if km.activeKeyFor(context.Background(), keySlot{alg: AlgEdDSA, purpose: "id_token"}) == nil {
+		t.Fatalf("missing slot must be created")
+	}
+
+	if err := km.ImportState(context.Background(), spec); err != nil {
+		t.Fatalf("second ImportState error: %v", err)
+	}
+	if keys, _ := store.List(); len(keys) != 1 {
+		t.Fatalf("ImportState must be idempotent, got %d keys", len(keys))
+	}
+}
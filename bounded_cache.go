@@ -0,0 +1,158 @@
+package keys_manager
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// KeyGetter is implemented by stores that can fetch a single key. It is
+// required by WithBoundedCache. Get returns an error wrapping
+// ErrKeyNotFound when kid does not exist.
+type KeyGetter interface {
+	Get(ctx context.Context, kid string) (*Key, error)
+}
+
+// boundedCache is an LRU of inactive keys loaded on demand by KID.
+type boundedCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newBoundedCache(size int) *boundedCache {
+	return &boundedCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *boundedCache) get(kid string) *CachedKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el := c.entries[kid]
+	if el == nil {
+		return nil
+	}
+
+	c.order.MoveToFront(el)
+	return el.Value.(*CachedKey)
+}
+
+// add caches ck and returns the entries it pushed out, which the caller
+// must wipe: nothing else references their material.
+func (c *boundedCache) add(ck *CachedKey) []*CachedKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el := c.entries[ck.key.KID]; el != nil {
+		replaced := el.Value.(*CachedKey)
+		el.Value = ck
+		c.order.MoveToFront(el)
+		return []*CachedKey{replaced}
+	}
+
+	c.entries[ck.key.KID] = c.order.PushFront(ck)
+
+	var evicted []*CachedKey
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		ck := oldest.Value.(*CachedKey)
+		delete(c.entries, ck.key.KID)
+		evicted = append(evicted, ck)
+	}
+	return evicted
+}
+
+// retain drops every entry for which keep returns false and returns them
+// for the caller to wipe.
+func (c *boundedCache) retain(keep func(ck *CachedKey) bool) []*CachedKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var dropped []*CachedKey
+	for kid, el := range c.entries {
+		ck := el.Value.(*CachedKey)
+		if !keep(ck) {
+			c.order.Remove(el)
+			delete(c.entries, kid)
+			dropped = append(dropped, ck)
+		}
+	}
+	return dropped
+}
+
+func (c *boundedCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// fetchKey loads an inactive key by KID from the store into the bounded
+// cache. It returns nil when the key does not exist or cannot be loaded.
+func (km *KeyManager) fetchKey(ctx context.Context, kid string) *CachedKey {
+	getter, ok := km.store.(KeyGetter)
+	if !ok {
+		return nil
+	}
+
+	spanCtx, span := km.startSpan(ctx, SpanStoreGet)
+	span.SetAttribute("kid", kid)
+	k, err := getter.Get(spanCtx, kid)
+	span.End(err)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		km.reportError(OpFetchKey, kid, err)
+	}
+	if err != nil || k == nil || k.KID != kid {
+		return nil
+	}
+	if err := km.checkEnvironment(k); err != nil {
+		km.reportError(OpFetchKey, kid, err)
+		return nil
+	}
+	if km.pastRetention(k) {
+		return nil
+	}
+
+	ck, err := km.cachedKey(ctx, k, nil, nil, k.IsActive)
+	if err != nil {
+		km.reportError(OpFetchKey, kid, err)
+		return nil
+	}
+	ck.usage = &keyUsage{}
+
+	km.wipeKeys(km.bounded.add(ck))
+	return ck
+}
+
+// boundedJWK prepares an inactive key for the JWKS without keeping it in
+// the cache. Keys without a stored public key are left out.
+func boundedJWK(k *Key) (*CachedKey, error) {
+	if len(k.PublicKey) == 0 {
+		return nil, nil
+	}
+
+	pub, err := parsePublicKey(k.PublicKey)
+	if err != nil {
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+	}
+
+	return &CachedKey{key: k, pub: pub}, nil
+}
+
+func (km *KeyManager) checkBoundedStore() error {
+	if km.bounded == nil {
+		return nil
+	}
+	if _, ok := km.store.(KeyGetter); !ok {
+		return fmt.Errorf("%w: bounded cache requires KeyGetter", ErrStoreUnsupported)
+	}
+	return nil
+}
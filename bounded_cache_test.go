@@ -0,0 +1,139 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+type gettableStore struct {
+	*MockStore
+	gets atomic.Int64
+}
+
+func (s *gettableStore) Get(_ context.Context, kid string) (*Key, error) {
+	s.gets.Add(1)
+
+	keys, _ := s.MockStore.List()
+	for _, k := range keys {
+		if k.KID == kid {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+func newBoundedManager(t *testing.T, size, rotations int) (*KeyManager, *gettableStore, [][]byte, []string) {
+	t.Helper()
+
+	store := &gettableStore{MockStore: NewMockStore()}
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithBoundedCache(size))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	var sigs [][]byte
+	var kids []string
+	for i := 0; i < rotations; i++ {
+		if err := km.Rotate(AlgEdDSA); err != nil {
+			t.Fatalf("Rotate error: %v", err)
+		}
+
+		var kid string
+		sig, err := km.Sign(AlgEdDSA, func(k string) ([]byte, error) {
+			kid = k
+			return []byte("payload"), nil
+		})
+		if err != nil {
+			t.Fatalf("Sign error: %v", err)
+		}
+		sigs = append(sigs, sig)
+		kids = append(kids, kid)
+	}
+
+	return km, store, sigs, kids
+}
+
+func TestBoundedCache_OnlyActiveKeysResident(t *testing.T) {
+	km, store, sigs, kids := newBoundedManager(t, 2, 3)
+
+	if n := len(km.snapshot().cache); n != 1 {
+		t.Fatalf("expected only the active key resident, got %d", n)
+	}
+
+	if err := km.Verify(kids[0], []byte("payload"), sigs[0]); err != nil {
+		t.Fatalf("Verify of historical key error: %v", err)
+	}
+	if err := km.Verify(kids[0], []byte("payload"), sigs[0]); err != nil {
+		t.Fatalf("Verify of historical key error: %v", err)
+	}
+
+	if got := store.gets.Load(); got != 1 {
+		t.Fatalf("expected a single fetch for repeated lookups, got %d", got)
+	}
+}
+
+func TestBoundedCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	km, store, _, kids := newBoundedManager(t, 1, 3)
+
+	km.keyByKID(kids[0])
+	km.keyByKID(kids[1])
+	km.keyByKID(kids[0])
+
+	if got := store.gets.Load(); got != 3 {
+		t.Fatalf("expected evicted key to be fetched again, got %d fetches", got)
+	}
+	if n := km.bounded.len(); n != 1 {
+		t.Fatalf("expected LRU bounded to 1 entry, got %d", n)
+	}
+}
+
+func TestBoundedCache_WipesEvictedKeys(t *testing.T) {
+	km, _, _, kids := newBoundedManager(t, 1, 3)
+
+	evicted := km.keyByKID(kids[0])
+	if evicted == nil || signerWiped(evicted.priv) {
+		t.Fatalf("fetched key must carry live material")
+	}
+
+	km.keyByKID(kids[1])
+	if !signerWiped(evicted.priv) {
+		t.Fatalf("evicted key material must be wiped")
+	}
+}
+
+func TestBoundedCache_JWKSIncludesInactiveKeys(t *testing.T) {
+	km, _, _, kids := newBoundedManager(t, 1, 3)
+
+	body, err := km.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS error: %v", err)
+	}
+
+	var jwks JWKS
+	if err := json.Unmarshal(body, &jwks); err != nil {
+		t.Fatalf("unmarshal jwks: %v", err)
+	}
+
+	if len(jwks.Keys) != len(kids) {
+		t.Fatalf("expected %d keys in JWKS, got %d", len(kids), len(jwks.Keys))
+	}
+}
+
+func TestBoundedCache_UnknownKID(t *testing.T) {
+	km, _, _, _ := newBoundedManager(t, 1, 1)
+
+	if err := km.Verify("missing", []byte("payload"), nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestBoundedCache_RequiresKeyGetter(t *testing.T) {
+	_, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithBoundedCache(10))
+	if !errors.Is(err, ErrStoreUnsupported) {
+		t.Fatalf("expected ErrStoreUnsupported, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"testing"
+	"time"
 )
 
 func isBase64URL(t *testing.T, s string) {
@@ -150,3 +151,30 @@ func TestBuildJWKS_SkipNilKeys(t *testing.T) {
 		t.Fatalf("expected 0 keys, got %d", len(jwks.Keys))
 	}
 }
+
+func TestBuildJWKS_StableOrder(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	t0 := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cache := make(map[string]*CachedKey)
+	for _, k := range []*Key{
+		{KID: "old", CreatedAt: t0},
+		{KID: "b", CreatedAt: t0.Add(time.Hour)},
+		{KID: "a", CreatedAt: t0.Add(time.Hour)},
+		{KID: "active", CreatedAt: t0.Add(2 * time.Hour), IsActive: true},
+		{KID: "first-active", CreatedAt: t0.Add(-time.Hour), IsActive: true},
+	} {
+		k.Alg = AlgEdDSA
+		cache[k.KID] = &CachedKey{key: k, pub: priv.Public()}
+	}
+
+	want := []string{"first-active", "active", "old", "a", "b"}
+	for range 20 {
+		jwks := buildJWKS(cache)
+		for i, k := range jwks.Keys {
+			if k.Kid != want[i] {
+				t.Fatalf("unexpected order at %d: got %s, want %v", i, k.Kid, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// WrapAlgorithm names a key import wrapping scheme. Both supported schemes
+// follow PKCS#11 CKM_RSA_AES_KEY_WRAP: a fresh AES key is encrypted with
+// RSA-OAEP under the wrapping key, followed by the PKCS#8 private key
+// wrapped under that AES key with AES Key Wrap with Padding (RFC 5649).
+type WrapAlgorithm string
+
+const (
+	WrapRSAAESKeyWrapSHA256 WrapAlgorithm = "RSA_AES_KEY_WRAP_SHA_256"
+	WrapRSAAESKeyWrapSHA1   WrapAlgorithm = "RSA_AES_KEY_WRAP_SHA_1"
+)
+
+func (a WrapAlgorithm) oaepHash() (crypto.Hash, func() hash.Hash, error) {
+	switch a {
+	case WrapRSAAESKeyWrapSHA256:
+		return crypto.SHA256, sha256.New, nil
+	case WrapRSAAESKeyWrapSHA1:
+		return crypto.SHA1, sha1.New, nil
+	}
+	return 0, nil, fmt.Errorf("%w: wrapping algorithm %q", ErrUnsupportedAlg, a)
+}
+
+// WrappingSpec describes how imported material was wrapped and where the
+// resulting key goes. WrappingKey holds the RSA private key whose public
+// half was handed to the key owner; it may live in an HSM.
+type WrappingSpec struct {
+	Algorithm   WrapAlgorithm
+	WrappingKey crypto.Decrypter
+	Alg         Alg
+	Purpose     Purpose
+	Labels      map[string]string
+	Activate    bool
+}
+
+// ImportWrappedKey unwraps customer supplied key material and stores it
+// like ImportKey, so the private key never travels in plaintext. It
+// returns the new KID.
+func (km *KeyManager) ImportWrappedKey(wrapped []byte, spec WrappingSpec) (string, error) {
+	return km.ImportWrappedKeyCtx(context.Background(), wrapped, spec)
+}
+
+func (km *KeyManager) ImportWrappedKeyCtx(ctx context.Context, wrapped []byte, spec WrappingSpec) (string, error) {
+	if spec.WrappingKey == nil {
+		return "", errors.New("import wrapped key: no wrapping key")
+	}
+	pub, ok := spec.WrappingKey.Public().(*rsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("import wrapped key: wrapping key must be RSA, got %T", spec.WrappingKey.Public())
+	}
+	h, _, err := spec.Algorithm.oaepHash()
+	if err != nil {
+		return "", err
+	}
+
+	if len(wrapped) <= pub.Size() {
+		return "", fmt.Errorf("%w: wrapped key too short", ErrDecryptFailed)
+	}
+
+	kek, err := spec.WrappingKey.Decrypt(rand.Reader, wrapped[:pub.Size()], &rsa.OAEPOptions{Hash: h})
+	if err != nil {
+		return "", fmt.Errorf("%w: unwrap aes key: %w", ErrDecryptFailed, err)
+	}
+	defer wipeBytes(kek)
+
+	der, err := keyUnwrapPad(kek, wrapped[pub.Size():])
+	if err != nil {
+		return "", fmt.Errorf("%w: unwrap private key: %w", ErrDecryptFailed, err)
+	}
+	defer wipeBytes(der)
+
+	priv, err := parsePrivateKey(der)
+	if err != nil {
+		return "", fmt.Errorf("import wrapped key: %w", err)
+	}
+	defer wipeSigner(priv)
+
+	return km.importKey(ctx, keyImport{
+		alg:      spec.Alg,
+		purpose:  spec.Purpose,
+		labels:   spec.Labels,
+		priv:     priv,
+		activate: spec.Activate,
+	})
+}
+
+// WrapKeyForImport wraps priv for ImportWrappedKey under the public
+// wrapping key, as done on the key owner's side.
+func WrapKeyForImport(wrappingKey *rsa.PublicKey, algorithm WrapAlgorithm, priv crypto.Signer) ([]byte, error) {
+	_, newHash, err := algorithm.oaepHash()
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := marshalPKCS8(priv)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(der)
+
+	kek := make([]byte, 32)
+	if _, err := rand.Read(kek); err != nil {
+		return nil, err
+	}
+	defer wipeBytes(kek)
+
+	head, err := rsa.EncryptOAEP(newHash(), rand.Reader, wrappingKey, kek, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tail, err := keyWrapPad(kek, der)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(head, tail...), nil
+}
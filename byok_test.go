@@ -0,0 +1,74 @@
+package keys_manager
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestImportWrappedKey(t *testing.T) {
+	wrappingKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	customerKey, _ := generatePrivateKey(AlgES256)
+
+	wrapped, err := WrapKeyForImport(&wrappingKey.PublicKey, WrapRSAAESKeyWrapSHA256, customerKey)
+	if err != nil {
+		t.Fatalf("WrapKeyForImport error: %v", err)
+	}
+
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	kid, err := km.ImportWrappedKey(wrapped, WrappingSpec{
+		Algorithm:   WrapRSAAESKeyWrapSHA256,
+		WrappingKey: wrappingKey,
+		Alg:         AlgES256,
+		Purpose:     PurposeAccess,
+		Labels:      map[string]string{"tenant": "acme"},
+		Activate:    true,
+	})
+	if err != nil {
+		t.Fatalf("ImportWrappedKey error: %v", err)
+	}
+
+	k := store.data[kid]
+	if k.Purpose != PurposeAccess || k.Labels["tenant"] != "acme" || !k.IsActive {
+		t.Fatalf("unexpected stored key %+v", k)
+	}
+
+	want, _ := marshalPublicKey(customerKey.Public())
+	if !bytes.Equal(k.PublicKey, want) {
+		t.Fatalf("imported key must be the customer's key")
+	}
+}
+
+func TestImportWrappedKey_Rejects(t *testing.T) {
+	wrappingKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	customerKey, _ := generatePrivateKey(AlgEdDSA)
+
+	wrapped, _ := WrapKeyForImport(&wrappingKey.PublicKey, WrapRSAAESKeyWrapSHA1, customerKey)
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	spec := WrappingSpec{Algorithm: WrapRSAAESKeyWrapSHA256, WrappingKey: wrappingKey, Alg: AlgEdDSA}
+	if _, err := km.ImportWrappedKey(wrapped, spec); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("mismatched OAEP hash must fail to unwrap, got %v", err)
+	}
+
+	spec.Algorithm = WrapRSAAESKeyWrapSHA1
+	spec.Alg = AlgES256
+	if _, err := km.ImportWrappedKey(wrapped, spec); err == nil {
+		t.Fatalf("material of another alg must be rejected")
+	}
+
+	spec.Alg = AlgEdDSA
+	tampered := bytes.Clone(wrapped)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := km.ImportWrappedKey(tampered, spec); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("tampered material must fail to unwrap, got %v", err)
+	}
+
+	if _, err := km.ImportWrappedKey(wrapped, spec); err != nil {
+		t.Fatalf("ImportWrappedKey error: %v", err)
+	}
+}
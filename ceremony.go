@@ -0,0 +1,230 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CeremonySpec describes the initial keys of a root-key ceremony and the
+// operators present.
+type CeremonySpec struct {
+	Purpose   Purpose
+	Algs      []Alg
+	Operators []string
+}
+
+// CeremonyKey is a key generated during a ceremony. Fingerprint is in the
+// KeyFingerprint.Hex form and is empty for symmetric keys.
+type CeremonyKey struct {
+	KID         string    `json:"kid"`
+	Alg         Alg       `json:"alg"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TranscriptSignature is a signature over Transcript.Text by one of the
+// keys generated in the ceremony, made the same way as Sign.
+type TranscriptSignature struct {
+	KID       string `json:"kid"`
+	Alg       Alg    `json:"alg"`
+	Signature []byte `json:"signature"`
+}
+
+// Transcript is the archival record of a ceremony.
+type Transcript struct {
+	Text       string                `json:"text"`
+	Keys       []CeremonyKey         `json:"keys"`
+	Signatures []TranscriptSignature `json:"signatures"`
+}
+
+// String renders the transcript text followed by its signatures.
+func (t *Transcript) String() string {
+	var b strings.Builder
+	b.WriteString(t.Text)
+	b.WriteString("\nSignatures:\n")
+	for _, s := range t.Signatures {
+		fmt.Fprintf(&b, "  %s %s %s\n", s.Alg, s.KID, b64(s.Signature))
+	}
+	return b.String()
+}
+
+// Verify checks every signature of the transcript against the keys known
+// to km.
+func (t *Transcript) Verify(ctx context.Context, km *KeyManager) error {
+	if len(t.Signatures) == 0 {
+		return fmt.Errorf("%w: transcript is not signed", ErrInvalidCeremony)
+	}
+	for _, s := range t.Signatures {
+		if err := km.VerifyCtx(ctx, s.KID, []byte(t.Text), s.Signature); err != nil {
+			return fmt.Errorf("transcript signature by %s: %w", s.KID, err)
+		}
+	}
+	return nil
+}
+
+// InitCeremony generates the initial keys listed in spec and returns a
+// transcript of the ceremony signed by every new asymmetric key. Unlike
+// InitKeys it refuses to run when any requested slot already has an active
+// key, and it requires at least one operator and one signing algorithm.
+//
+// A ceremony that fails part way revokes the keys it generated, so it can
+// be run again; keys it could not revoke are named in the error.
+func (km *KeyManager) InitCeremony(ctx context.Context, spec CeremonySpec) (_ *Transcript, err error) {
+	if err := km.checkCeremony(spec); err != nil {
+		return nil, err
+	}
+
+	started := km.clock.Now()
+
+	t := &Transcript{}
+	defer func() {
+		if err != nil {
+			err = km.abortCeremony(ctx, t.Keys, err)
+		}
+	}()
+
+	for _, alg := range spec.Algs {
+		s := keySlot{alg: alg, purpose: spec.Purpose}
+		km.initSlots.Store(s, struct{}{})
+
+		if err := km.RotateWithPurposeCtx(ctx, alg, spec.Purpose); err != nil {
+			return nil, fmt.Errorf("ceremony key for %s: %w", s, err)
+		}
+
+		ck := km.snapshot().active[s]
+		if ck == nil {
+			return nil, fmt.Errorf("%w: no active key for %s after generation", ErrInvalidCeremony, s)
+		}
+
+		t.Keys = append(t.Keys, CeremonyKey{
+			KID:         ck.key.KID,
+			Alg:         alg,
+			Fingerprint: fingerprint(ck.key),
+			CreatedAt:   ck.key.CreatedAt,
+		})
+	}
+
+	t.Text = km.transcriptText(spec, started, km.clock.Now(), t.Keys)
+
+	for _, k := range t.Keys {
+		if k.Alg.symmetric() {
+			continue
+		}
+
+		ck := km.keyByKIDCtx(ctx, k.KID)
+		if ck == nil {
+			return nil, fmt.Errorf("%w: ceremony key %s disappeared", ErrInvalidCeremony, k.KID)
+		}
+
+		sig, err := km.sign(ctx, ck, func(string) ([]byte, error) {
+			return []byte(t.Text), nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sign transcript with %s: %w", k.KID, err)
+		}
+		t.Signatures = append(t.Signatures, TranscriptSignature{KID: k.KID, Alg: k.Alg, Signature: sig})
+	}
+
+	return t, nil
+}
+
+// abortCeremony revokes the keys generated by a failed ceremony and
+// annotates err with the outcome.
+func (km *KeyManager) abortCeremony(ctx context.Context, keys []CeremonyKey, err error) error {
+	if len(keys) == 0 {
+		return err
+	}
+
+	// The ceremony may have failed because ctx was cancelled; the
+	// cleanup must still run.
+	ctx = context.WithoutCancel(ctx)
+
+	var revoked, left []string
+	var errs []error
+	for _, k := range keys {
+		if rerr := km.RevokeCtx(ctx, k.KID); rerr != nil {
+			left = append(left, k.KID)
+			errs = append(errs, rerr)
+			continue
+		}
+		revoked = append(revoked, k.KID)
+	}
+
+	if len(left) == 0 {
+		return fmt.Errorf("%w; revoked ceremony keys %s", err, strings.Join(revoked, ", "))
+	}
+	return fmt.Errorf("%w; could not revoke ceremony keys %s, revoke them before running the ceremony again: %w",
+		err, strings.Join(left, ", "), errors.Join(errs...))
+}
+
+func (km *KeyManager) checkCeremony(spec CeremonySpec) error {
+	if len(spec.Operators) == 0 {
+		return fmt.Errorf("%w: no operators", ErrInvalidCeremony)
+	}
+	if km.verifyOnly {
+		return ErrVerifyOnly
+	}
+
+	signing := false
+	active := km.snapshot().active
+	for _, alg := range spec.Algs {
+		s := keySlot{alg: alg, purpose: spec.Purpose}
+		if ck, ok := active[s]; ok {
+			return fmt.Errorf("%w: %s already has active key %s", ErrInvalidCeremony, s, ck.key.KID)
+		}
+		if !alg.symmetric() {
+			signing = true
+		}
+	}
+	if !signing {
+		return fmt.Errorf("%w: no signing algorithm to sign the transcript", ErrInvalidCeremony)
+	}
+
+	return nil
+}
+
+func (km *KeyManager) transcriptText(spec CeremonySpec, started, completed time.Time, keys []CeremonyKey) string {
+	var b strings.Builder
+
+	b.WriteString("KEY CEREMONY TRANSCRIPT\n\n")
+	fmt.Fprintf(&b, "Started:     %s\n", started.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Completed:   %s\n", completed.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Purpose:     %s\n", purposeName(spec.Purpose))
+	if km.environment != "" {
+		fmt.Fprintf(&b, "Environment: %s\n", km.environment)
+	}
+
+	b.WriteString("\nOperators:\n")
+	for _, op := range spec.Operators {
+		fmt.Fprintf(&b, "  %s\n", op)
+	}
+
+	b.WriteString("\nKeys:\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  %s %s\n", k.Alg, k.KID)
+		fmt.Fprintf(&b, "    created:     %s\n", k.CreatedAt.UTC().Format(time.RFC3339))
+		if k.Fingerprint != "" {
+			fmt.Fprintf(&b, "    fingerprint: %s\n", k.Fingerprint)
+		}
+	}
+
+	return b.String()
+}
+
+func purposeName(p Purpose) string {
+	if p == PurposeDefault {
+		return "default"
+	}
+	return string(p)
+}
+
+func fingerprint(k *Key) string {
+	if len(k.PublicKey) == 0 {
+		return ""
+	}
+	return KeyFingerprint(sha256.Sum256(k.PublicKey)).Hex()
+}
@@ -0,0 +1,111 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInitCeremony_SignedTranscript(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithEnvironment("prod"))
+
+	tr, err := km.InitCeremony(context.Background(), CeremonySpec{
+		Purpose:   PurposeAccess,
+		Algs:      []Alg{AlgES256, AlgEdDSA, AlgA256GCM},
+		Operators: []string{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("InitCeremony error: %v", err)
+	}
+
+	if len(tr.Keys) != 3 {
+		t.Fatalf("expected 3 ceremony keys, got %d", len(tr.Keys))
+	}
+	if len(tr.Signatures) != 2 {
+		t.Fatalf("expected signatures by the 2 asymmetric keys, got %d", len(tr.Signatures))
+	}
+
+	for _, want := range []string{"alice", "bob", "Purpose:     access", "Environment: prod"} {
+		if !strings.Contains(tr.Text, want) {
+			t.Fatalf("transcript missing %q:\n%s", want, tr.Text)
+		}
+	}
+	for _, k := range tr.Keys {
+		if !strings.Contains(tr.Text, k.KID) {
+			t.Fatalf("transcript missing kid %s", k.KID)
+		}
+		if k.Alg.symmetric() != (k.Fingerprint == "") {
+			t.Fatalf("unexpected fingerprint %q for %s", k.Fingerprint, k.Alg)
+		}
+		if k.Fingerprint != "" && !strings.Contains(tr.Text, k.Fingerprint) {
+			t.Fatalf("transcript missing fingerprint of %s", k.KID)
+		}
+	}
+
+	if err := tr.Verify(context.Background(), km); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+
+	tr.Text = strings.Replace(tr.Text, "alice", "mallory", 1)
+	if err := tr.Verify(context.Background(), km); err == nil {
+		t.Fatalf("tampered transcript must not verify")
+	}
+}
+
+func TestInitCeremony_Requirements(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	ctx := context.Background()
+
+	cases := map[string]CeremonySpec{
+		"no operators": {Algs: []Alg{AlgEdDSA}},
+		"no signing":   {Algs: []Alg{AlgA256GCM}, Operators: []string{"alice"}},
+	}
+	for name, spec := range cases {
+		if _, err := km.InitCeremony(ctx, spec); !errors.Is(err, ErrInvalidCeremony) {
+			t.Fatalf("%s: expected ErrInvalidCeremony, got %v", name, err)
+		}
+	}
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	_, err := km.InitCeremony(ctx, CeremonySpec{Algs: []Alg{AlgES256, AlgEdDSA}, Operators: []string{"alice"}})
+	if !errors.Is(err, ErrInvalidCeremony) {
+		t.Fatalf("expected ErrInvalidCeremony for an existing active key, got %v", err)
+	}
+	if km.activeKey(AlgES256) != nil {
+		t.Fatalf("no key must be generated when the ceremony is refused")
+	}
+}
+
+type rotateCountdownStore struct {
+	*MockStore
+	failAt, rotates int
+}
+
+func (s *rotateCountdownStore) Rotate(new, old *Key) error {
+	s.rotates++
+	if s.rotates == s.failAt {
+		return errors.New("rotate failed")
+	}
+	return s.MockStore.Rotate(new, old)
+}
+
+func TestInitCeremony_RevokesKeysOnFailure(t *testing.T) {
+	store := &rotateCountdownStore{MockStore: NewMockStore(), failAt: 2}
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	spec := CeremonySpec{Algs: []Alg{AlgES256, AlgEdDSA}, Operators: []string{"alice"}}
+	if _, err := km.InitCeremony(context.Background(), spec); err == nil {
+		t.Fatalf("expected the failed rotation to fail the ceremony")
+	}
+
+	keys, _ := store.List()
+	if len(keys) != 1 || keys[0].RevokedAt == nil || keys[0].IsActive {
+		t.Fatalf("the key generated before the failure must be revoked, got %+v", keys)
+	}
+
+	if _, err := km.InitCeremony(context.Background(), spec); err != nil {
+		t.Fatalf("the ceremony must be able to run again, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// noWellDefinedExpiry is the RFC 5280 NotAfter for certificates of keys
+// that never expire.
+var noWellDefinedExpiry = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// KeyPair is a signing key with its certificate, the form SAML and
+// XML-DSig libraries expect.
+type KeyPair struct {
+	KID         string
+	Signer      crypto.Signer
+	Certificate *x509.Certificate
+}
+
+// TLSCertificate returns the pair as a tls.Certificate, which XML-DSig
+// libraries accept as a key store.
+func (p *KeyPair) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{p.Certificate.Raw},
+		PrivateKey:  p.Signer,
+		Leaf:        p.Certificate,
+	}
+}
+
+// issueCertificate returns a self-signed certificate for the PKCS#8 key
+// privDER, or nil when certificates are not enabled.
+func (km *KeyManager) issueCertificate(alg Alg, privDER []byte, kid string, notBefore, notAfter time.Time) ([]byte, error) {
+	if km.certSubject == nil || alg.symmetric() {
+		return nil, nil
+	}
+
+	signer, err := parsePrivateKey(privDER)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeSigner(signer)
+
+	serial, err := rand.Int(km.entropy(), new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	if !notAfter.After(notBefore) {
+		notAfter = noWellDefinedExpiry
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               *km.certSubject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	if tmpl.Subject.CommonName == "" {
+		tmpl.Subject.CommonName = kid
+	}
+
+	der, err := x509.CreateCertificate(km.entropy(), tmpl, tmpl, signer.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate: %w", err)
+	}
+
+	return der, nil
+}
+
+func (km *KeyManager) ActiveKeyPair(alg Alg, purpose Purpose) (*KeyPair, error) {
+	return km.ActiveKeyPairCtx(context.Background(), alg, purpose)
+}
+
+// ActiveKeyPairCtx returns the active key of alg and purpose with its
+// stored certificate.
+func (km *KeyManager) ActiveKeyPairCtx(ctx context.Context, alg Alg, purpose Purpose) (*KeyPair, error) {
+	s := keySlot{alg: alg, purpose: purpose}
+
+	ck := km.signingKey(ctx, s)
+	if ck == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoActiveKey, s)
+	}
+	if ck.priv == nil {
+		return nil, ErrVerifyOnly
+	}
+	if err := km.checkExpiry(ck.key); err != nil {
+		return nil, err
+	}
+	if err := km.authorize(ctx, ActionSign, ck.key); err != nil {
+		return nil, err
+	}
+
+	cert, err := keyCertificate(ck)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := km.handOut(ck)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{KID: ck.key.KID, Signer: signer, Certificate: cert}, nil
+}
+
+// Certificates returns the certificates relying parties should trust for
+// alg and purpose: the active key's first, then those of retired and
+// upcoming keys that are neither expired nor revoked, newest first.
+// Publishing all of them in metadata lets peers follow a rollover. Keys
+// without a stored certificate are skipped.
+func (km *KeyManager) Certificates(alg Alg, purpose Purpose) ([]*x509.Certificate, error) {
+	now := km.clock.Now()
+
+	var keys []*CachedKey
+	for _, ck := range km.snapshot().cache {
+		k := ck.key
+		if k.Alg != alg || k.Purpose != purpose || len(k.Labels) > 0 {
+			continue
+		}
+		if k.RevokedAt != nil || km.expired(k, now) || len(k.Certificate) == 0 {
+			continue
+		}
+		keys = append(keys, ck)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i].key, keys[j].key
+		if a.IsActive != b.IsActive {
+			return a.IsActive
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	out := make([]*x509.Certificate, 0, len(keys))
+	for _, ck := range keys {
+		cert, err := keyCertificate(ck)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cert)
+	}
+
+	return out, nil
+}
+
+// keyCertificate parses the stored certificate of ck and checks that it
+// certifies the key's own public key.
+func keyCertificate(ck *CachedKey) (*x509.Certificate, error) {
+	if len(ck.key.Certificate) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCertificate, ck.key.KID)
+	}
+
+	cert, err := x509.ParseCertificate(ck.key.Certificate)
+	if err != nil {
+		return nil, &KeyError{KID: ck.key.KID, Alg: ck.key.Alg, Err: fmt.Errorf("parse certificate: %w", err)}
+	}
+
+	pub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(ck.pub) {
+		return nil, &KeyError{KID: ck.key.KID, Alg: ck.key.Alg, Err: errors.New("certificate does not match key")}
+	}
+
+	return cert, nil
+}
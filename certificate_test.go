@@ -0,0 +1,71 @@
+package keys_manager
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCertificates_IssuedOnRotateAndRolledOver(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithClock(clock), WithSelfSignedCertificates(pkix.Name{Organization: []string{"Example"}}))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.RotateWithPurpose(AlgRS256, "saml"); err != nil {
+		t.Fatalf("rotate error: %v", err)
+	}
+
+	first, err := km.ActiveKeyPair(AlgRS256, "saml")
+	if err != nil {
+		t.Fatalf("ActiveKeyPair error: %v", err)
+	}
+	if first.Certificate.Subject.CommonName != first.KID {
+		t.Fatalf("common name must default to the KID, got %q", first.Certificate.Subject.CommonName)
+	}
+	if !first.Certificate.NotAfter.Equal(clock.now.Add(time.Hour)) {
+		t.Fatalf("certificate must expire with the key, got %v", first.Certificate.NotAfter)
+	}
+	c := first.Certificate
+	if err := c.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature); err != nil {
+		t.Fatalf("certificate must be self-signed: %v", err)
+	}
+	if tc := first.TLSCertificate(); tc.PrivateKey != first.Signer || tc.Leaf != first.Certificate {
+		t.Fatalf("unexpected tls certificate %+v", tc)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_ = km.RotateWithPurpose(AlgRS256, "saml")
+
+	certs, err := km.Certificates(AlgRS256, "saml")
+	if err != nil {
+		t.Fatalf("Certificates error: %v", err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected active and retired certificate, got %d", len(certs))
+	}
+	if !certs[1].Equal(first.Certificate) {
+		t.Fatalf("retired certificate must follow the active one")
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	certs, _ = km.Certificates(AlgRS256, "saml")
+	if len(certs) != 1 {
+		t.Fatalf("expired certificate must be dropped, got %d", len(certs))
+	}
+}
+
+func TestCertificates_MissingCertificate(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	if _, err := km.ActiveKeyPair(AlgEdDSA, PurposeDefault); !errors.Is(err, ErrNoCertificate) {
+		t.Fatalf("expected ErrNoCertificate, got %v", err)
+	}
+	if certs, err := km.Certificates(AlgEdDSA, PurposeDefault); err != nil || len(certs) != 0 {
+		t.Fatalf("keys without certificates must be skipped, got %v %v", certs, err)
+	}
+}
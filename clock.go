@@ -0,0 +1,34 @@
+package keys_manager
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+}
+
+// TimerClock is a Clock that also drives timers. With one, delayed work
+// such as staged activations waits on the injected clock rather than the
+// wall clock.
+type TimerClock interface {
+	Clock
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now returns the time on the manager's clock, see WithClock.
+func (km *KeyManager) Now() time.Time {
+	return km.clock.Now()
+}
+
+// after fires once d has passed on the manager's clock.
+func (km *KeyManager) after(d time.Duration) <-chan time.Time {
+	if tc, ok := km.clock.(TimerClock); ok {
+		return tc.After(d)
+	}
+	return time.After(d)
+}
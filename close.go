@@ -0,0 +1,56 @@
+package keys_manager
+
+import (
+	"context"
+)
+
+// Close stops background refresh, store watching and key pre-generation,
+// then drops the cache and wipes the private keys it held. Calls after
+// the first return nil. Once closed, reloads fail with ErrClosed and
+// signing fails with ErrNoActiveKey.
+//
+// If ctx is done before background goroutines exit, Close returns the
+// context error after wiping; the goroutines exit on their own.
+func (km *KeyManager) Close(ctx context.Context) error {
+	if !km.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	stopErr := km.bg.stopCtx(ctx)
+
+	if km.usageInterval > 0 {
+		km.reportError(OpUsageFlush, "", km.flushUsage(ctx))
+	}
+
+	km.mu.Lock()
+	prev := km.snapshot()
+	km.snap.Store(&cacheSnapshot{
+		active: make(map[keySlot]*CachedKey),
+		cache:  make(map[string]*CachedKey),
+	})
+	km.mu.Unlock()
+
+	km.material.Lock()
+	defer km.material.Unlock()
+
+	for _, ck := range prev.cache {
+		if ck.priv != nil {
+			wipeSigner(ck.priv)
+		}
+		wipeBytes(ck.secret)
+	}
+	prev.retired.wipe()
+
+	if km.bounded != nil {
+		for _, ck := range km.bounded.retain(func(*CachedKey) bool { return false }) {
+			if ck.priv != nil {
+				wipeSigner(ck.priv)
+			}
+			wipeBytes(ck.secret)
+		}
+	}
+
+	km.keyPool.drain(wipeSigner)
+
+	return stopErr
+}
@@ -0,0 +1,60 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClose_StopsBackgroundAndWipesKeys(t *testing.T) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithBackgroundRefresh(time.Millisecond), WithKeyPool(AlgEdDSA, 1))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	priv := km.activeKey(AlgEdDSA).priv.(ed25519.PrivateKey)
+
+	if err := km.Close(context.Background()); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	if !isZero(priv) {
+		t.Fatalf("expected cached private key to be wiped")
+	}
+
+	if _, err := km.Sign(AlgEdDSA, func(string) ([]byte, error) { return nil, nil }); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey after Close, got %v", err)
+	}
+	if err := km.ReloadCache(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed from ReloadCache, got %v", err)
+	}
+	if err := km.Rotate(AlgEdDSA); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed from Rotate, got %v", err)
+	}
+
+	if err := km.Close(context.Background()); err != nil {
+		t.Fatalf("second Close must be a no-op, got %v", err)
+	}
+}
+
+func TestClose_HonorsContext(t *testing.T) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	km.bg.goFunc(func(context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := km.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,853 @@
+// Command keysctl manages keys in a keys_manager store from the command
+// line.
+//
+//	keysctl [global flags] <command> [command flags] [args]
+//
+// Commands:
+//
+//	init -alg RS256,ES256     create an active key for each alg that lacks one
+//	rotate -alg ES256         replace the active key of alg
+//	list [-json]              show stored keys
+//	usage [-json]             show the sign and verify totals persisted by
+//	                          managers running WithUsagePersistence
+//	revoke KID                revoke a key
+//	delete [-force] KID       delete a revoked or expired key; -force also
+//	                          deletes active and unexpired keys
+//	approve KID               activate a key pending approval
+//	reject KID                revoke a key pending approval
+//	jwks                      print the public JWKS
+//	test-vectors              print signatures and tokens made with every
+//	                          active key, with the JWKS, for partner verifiers
+//	export-public KID         print a public key as PEM
+//	export-wrapped -wrapping-key-file FILE KID
+//	                          print a private key wrapped with AES Key Wrap
+//	                          with Padding (RFC 5649), base64 encoded
+//	fingerprint KID           print the SHA-256 fingerprint of a public key
+//	import-pem -alg A [-activate] FILE
+//	                          import a PKCS#8 PEM private key
+//	import-jwks [-activate] FILE
+//	                          import the private keys of a JWKS exported by
+//	                          another IdP, keeping their KIDs
+//	re-encrypt -new-master-key-file FILE
+//	                          re-encrypt every key under a new master key
+//	encrypt-plaintext         encrypt keys stored as plaintext PKCS#8 under
+//	                          the master key
+//	backup [-out FILE]        write a passphrase encrypted backup of every key
+//	restore FILE              restore keys missing from the store from a backup
+//	verify [-json]            check every stored key decrypts and the store is
+//	                          consistent; fails when anything is found
+//
+// Backup passphrases are read from -passphrase-file or the environment
+// variable named by -passphrase-env (default KEYSCTL_BACKUP_PASSPHRASE).
+//
+// The store and master key come from flags or a JSON config file with the
+// same names, e.g. {"store": "file:/var/lib/keys.json",
+// "master_key_file": "/etc/keys/master", "ttl": "720h"}. Flags win over the
+// config file. Master keys are 32 bytes, base64 encoded.
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/filestore"
+	"github.com/keylet-auth/keys-manager/testvectors"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "keysctl:", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	Store         string   `json:"store"`
+	MasterKeyFile string   `json:"master_key_file"`
+	MasterKeyEnv  string   `json:"master_key_env"`
+	TTL           duration `json:"ttl"`
+}
+
+// duration is a time.Duration read from a config string such as "24h".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"720h\": %w", err)
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func run(args []string, stdout, stderr io.Writer) (err error) {
+	global := flag.NewFlagSet("keysctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+
+	configPath := global.String("config", "", "JSON config file")
+	store := global.String("store", "", "key store, e.g. file:keys.json")
+	masterKeyFile := global.String("master-key-file", "", "file holding the base64 master key")
+	masterKeyEnv := global.String("master-key-env", "", "environment variable holding the base64 master key (default KEYSCTL_MASTER_KEY)")
+	ttl := global.Duration("ttl", 0, "lifetime of new keys (default 720h)")
+
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return errors.New("missing command")
+	}
+
+	cfg := config{MasterKeyEnv: "KEYSCTL_MASTER_KEY", TTL: duration(720 * time.Hour)}
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("parse config: %w", err)
+		}
+	}
+
+	global.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "store":
+			cfg.Store = *store
+		case "master-key-file":
+			cfg.MasterKeyFile = *masterKeyFile
+		case "master-key-env":
+			cfg.MasterKeyEnv = *masterKeyEnv
+		case "ttl":
+			cfg.TTL = duration(*ttl)
+		}
+	})
+
+	cmd, rest := global.Arg(0), global.Args()[1:]
+
+	c, ok := commands[cmd]
+	if !ok {
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	e := &env{cfg: cfg, fs: fs, stdout: stdout}
+	defer func() { err = errors.Join(err, e.close()) }()
+	return c(e, rest)
+}
+
+type env struct {
+	cfg      config
+	fs       *flag.FlagSet
+	stdout   io.Writer
+	managers []*km.KeyManager
+}
+
+// close closes every manager the command opened, wiping their keys.
+func (e *env) close() error {
+	var errs []error
+	for _, m := range e.managers {
+		errs = append(errs, m.Close(context.Background()))
+	}
+	return errors.Join(errs...)
+}
+
+func (e *env) opened(m *km.KeyManager, err error) (*km.KeyManager, error) {
+	if err != nil {
+		return nil, err
+	}
+	e.managers = append(e.managers, m)
+	return m, nil
+}
+
+var commands = map[string]func(e *env, args []string) error{
+	"init":              cmdInit,
+	"rotate":            cmdRotate,
+	"list":              cmdList,
+	"usage":             cmdUsage,
+	"revoke":            cmdRevoke,
+	"delete":            cmdDelete,
+	"approve":           cmdApprove,
+	"reject":            cmdReject,
+	"jwks":              cmdJWKS,
+	"test-vectors":      cmdTestVectors,
+	"export-public":     cmdExportPublic,
+	"export-wrapped":    cmdExportWrapped,
+	"fingerprint":       cmdFingerprint,
+	"import-pem":        cmdImportPEM,
+	"import-jwks":       cmdImportJWKS,
+	"re-encrypt":        cmdReEncrypt,
+	"encrypt-plaintext": cmdEncryptPlaintext,
+	"backup":            cmdBackup,
+	"restore":           cmdRestore,
+	"verify":            cmdVerify,
+}
+
+func (e *env) store() (km.Store, error) {
+	scheme, target, ok := strings.Cut(e.cfg.Store, ":")
+	if !ok || target == "" {
+		return nil, fmt.Errorf("store must be given as file:PATH, got %q", e.cfg.Store)
+	}
+
+	switch scheme {
+	case "file":
+		return filestore.New(target), nil
+	default:
+		return nil, fmt.Errorf("unsupported store %q", scheme)
+	}
+}
+
+func (e *env) encryptor(file string) (km.Encryptor, error) {
+	var encoded string
+
+	switch {
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	case e.cfg.MasterKeyEnv != "":
+		encoded = os.Getenv(e.cfg.MasterKeyEnv)
+	}
+
+	if strings.TrimSpace(encoded) == "" {
+		return nil, errors.New("no master key: set -master-key-file or " + e.cfg.MasterKeyEnv)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode master key: %w", err)
+	}
+	return km.NewAESGCMEncryptor(key)
+}
+
+func (e *env) manager(opts ...km.Option) (*km.KeyManager, error) {
+	store, err := e.store()
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := e.encryptor(e.cfg.MasterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(e.cfg.TTL)
+	policy := func() (km.RotationConfig, error) {
+		return km.RotationConfig{TTL: ttl}, nil
+	}
+
+	return e.opened(km.NewKeyManager(store, enc, policy, opts...))
+}
+
+// publicManager opens the store for commands that only read public keys.
+func (e *env) publicManager() (*km.KeyManager, error) {
+	store, err := e.store()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.opened(km.NewKeyManager(store, nil, func() (km.RotationConfig, error) {
+		return km.RotationConfig{}, nil
+	}, km.WithVerifyOnly()))
+}
+
+func parseAlgs(s string) ([]km.Alg, error) {
+	if s == "" {
+		return nil, errors.New("-alg is required")
+	}
+
+	var out []km.Alg
+	for _, a := range strings.Split(s, ",") {
+		alg := km.Alg(strings.TrimSpace(a))
+		switch alg {
+		case km.AlgRS256, km.AlgES256, km.AlgEdDSA:
+			out = append(out, alg)
+		default:
+			return nil, fmt.Errorf("%w: %s", km.ErrUnsupportedAlg, alg)
+		}
+	}
+	return out, nil
+}
+
+func cmdInit(e *env, args []string) error {
+	algFlag := e.fs.String("alg", "", "comma separated algorithms")
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	algs, err := parseAlgs(*algFlag)
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.InitKeys(algs)
+}
+
+func cmdRotate(e *env, args []string) error {
+	algFlag := e.fs.String("alg", "", "algorithm to rotate")
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	algs, err := parseAlgs(*algFlag)
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+
+	for _, alg := range algs {
+		if err := m.Rotate(alg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdList(e *env, args []string) error {
+	asJSON := e.fs.Bool("json", false, "print JSON")
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := e.publicManager()
+	if err != nil {
+		return err
+	}
+
+	keys := m.Keys()
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(keys)
+	}
+
+	tw := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KID\tALG\tPURPOSE\tSTATE\tCREATED\tEXPIRES")
+	for _, k := range keys {
+		expires := "-"
+		if k.ExpiresAt != nil {
+			expires = k.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		purpose := string(k.Purpose)
+		if purpose == "" {
+			purpose = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			k.KID, k.Alg, purpose, k.State, k.CreatedAt.UTC().Format(time.RFC3339), expires)
+	}
+	return tw.Flush()
+}
+
+func cmdUsage(e *env, args []string) error {
+	asJSON := e.fs.Bool("json", false, "print JSON")
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := e.store()
+	if err != nil {
+		return err
+	}
+	ur, ok := store.(km.UsageReader)
+	if !ok {
+		return fmt.Errorf("%w: store does not record usage", km.ErrStoreUnsupported)
+	}
+
+	keys, err := store.List()
+	if err != nil {
+		return err
+	}
+	recorded, err := ur.Usage(context.Background())
+	if err != nil {
+		return err
+	}
+
+	byKID := make(map[string]km.UsageDelta, len(recorded))
+	for _, u := range recorded {
+		byKID[u.KID] = u
+	}
+
+	usage := make([]km.UsageDelta, len(keys))
+	for i, k := range keys {
+		usage[i] = byKID[k.KID]
+		usage[i].KID = k.KID
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(usage)
+	}
+
+	tw := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KID\tSIGNS\tVERIFIES\tLAST SIGNED\tLAST VERIFIED")
+	for _, u := range usage {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n",
+			u.KID, u.Signs, u.Verifies, formatTime(u.LastSignedAt), formatTime(u.LastVerifiedAt))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func oneArg(fs *flag.FlagSet, args []string, name string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("expected %s", name)
+	}
+	return fs.Arg(0), nil
+}
+
+func cmdRevoke(e *env, args []string) error {
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.Revoke(kid)
+}
+
+func cmdDelete(e *env, args []string) error {
+	force := e.fs.Bool("force", false, "delete active and unexpired keys too")
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.DeleteKey(kid, *force)
+}
+
+func cmdApprove(e *env, args []string) error {
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.ApproveKey(context.Background(), kid)
+}
+
+func cmdReject(e *env, args []string) error {
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.RejectKey(context.Background(), kid)
+}
+
+func cmdJWKS(e *env, args []string) error {
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := e.publicManager()
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.JWKSTo(e.stdout); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(e.stdout)
+	return err
+}
+
+func cmdTestVectors(e *env, args []string) error {
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+
+	set, err := testvectors.Generate(context.Background(), m)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(set)
+}
+
+func cmdExportPublic(e *env, args []string) error {
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+
+	m, err := e.publicManager()
+	if err != nil {
+		return err
+	}
+
+	pub, err := m.GetPublicKey(kid)
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	return pem.Encode(e.stdout, &pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func cmdExportWrapped(e *env, args []string) error {
+	keyFile := e.fs.String("wrapping-key-file", "", "file holding the base64 AES wrapping key")
+
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+	if *keyFile == "" {
+		return errors.New("-wrapping-key-file is required")
+	}
+
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return err
+	}
+	kek, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	clear(data)
+	if err != nil {
+		return fmt.Errorf("decode wrapping key: %w", err)
+	}
+	defer clear(kek)
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+
+	wrapped, err := m.ExportWrapped(kid, kek)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(e.stdout, base64.StdEncoding.EncodeToString(wrapped))
+	return err
+}
+
+func cmdFingerprint(e *env, args []string) error {
+	kid, err := oneArg(e.fs, args, "KID")
+	if err != nil {
+		return err
+	}
+
+	m, err := e.publicManager()
+	if err != nil {
+		return err
+	}
+
+	f, err := m.Fingerprint(kid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.stdout, f.Hex())
+	fmt.Fprintln(e.stdout, f)
+	return nil
+}
+
+func cmdImportPEM(e *env, args []string) error {
+	algFlag := e.fs.String("alg", "", "algorithm of the key")
+	activate := e.fs.Bool("activate", false, "make the key the active key of alg")
+
+	path, err := oneArg(e.fs, args, "PEM file")
+	if err != nil {
+		return err
+	}
+
+	algs, err := parseAlgs(*algFlag)
+	if err != nil {
+		return err
+	}
+	if len(algs) != 1 {
+		return errors.New("-alg takes a single algorithm")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return errors.New("expected a PKCS#8 \"PRIVATE KEY\" PEM block")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	clear(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	priv, ok := parsed.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+
+	kid, err := m.ImportKey(algs[0], priv, *activate)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(e.stdout, kid)
+	return err
+}
+
+func cmdImportJWKS(e *env, args []string) error {
+	activate := e.fs.Bool("activate", false, "make the first ACTIVE key of each alg its active key")
+
+	path, err := oneArg(e.fs, args, "JWKS file")
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	defer clear(data)
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+
+	report, err := m.ImportJWKS(context.Background(), data, km.JWKSImportOptions{Activate: *activate})
+	if err != nil {
+		return err
+	}
+
+	for _, kid := range report.Imported {
+		fmt.Fprintln(e.stdout, kid)
+	}
+	for _, s := range report.Skipped {
+		fmt.Fprintf(e.stdout, "skipped %s: %s\n", s.KID, s.Reason)
+	}
+	return nil
+}
+
+func cmdReEncrypt(e *env, args []string) error {
+	newKeyFile := e.fs.String("new-master-key-file", "", "file holding the new base64 master key")
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+	if *newKeyFile == "" {
+		return errors.New("-new-master-key-file is required")
+	}
+
+	to, err := e.encryptor(*newKeyFile)
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.ReEncrypt(to)
+}
+
+func cmdEncryptPlaintext(e *env, args []string) error {
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Lenient, as the plaintext keys would stop the manager from opening.
+	m, err := e.manager(km.WithLenientReload())
+	if err != nil {
+		return err
+	}
+
+	kids, err := m.EncryptPlaintextKeys()
+	for _, kid := range kids {
+		fmt.Fprintln(e.stdout, kid)
+	}
+	return err
+}
+
+func passphraseFlags(fs *flag.FlagSet) func() (string, error) {
+	file := fs.String("passphrase-file", "", "file holding the backup passphrase")
+	env := fs.String("passphrase-env", "KEYSCTL_BACKUP_PASSPHRASE", "environment variable holding the backup passphrase")
+
+	return func() (string, error) {
+		var passphrase string
+		if *file != "" {
+			data, err := os.ReadFile(*file)
+			if err != nil {
+				return "", err
+			}
+			passphrase = strings.TrimRight(string(data), "\r\n")
+		} else {
+			passphrase = os.Getenv(*env)
+		}
+
+		if passphrase == "" {
+			return "", errors.New("no backup passphrase: set -passphrase-file or " + *env)
+		}
+		return passphrase, nil
+	}
+}
+
+func cmdBackup(e *env, args []string) error {
+	out := e.fs.String("out", "", "write the backup to FILE instead of stdout")
+	passphrase := passphraseFlags(e.fs)
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	pass, err := passphrase()
+	if err != nil {
+		return err
+	}
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return m.Backup(e.stdout, pass)
+	}
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := m.Backup(f, pass); err != nil {
+		f.Close()
+		os.Remove(*out)
+		return err
+	}
+	return f.Close()
+}
+
+func cmdRestore(e *env, args []string) error {
+	passphrase := passphraseFlags(e.fs)
+
+	path, err := oneArg(e.fs, args, "backup file")
+	if err != nil {
+		return err
+	}
+
+	pass, err := passphrase()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m, err := e.manager()
+	if err != nil {
+		return err
+	}
+	return m.Restore(f, pass)
+}
+
+func cmdVerify(e *env, args []string) error {
+	asJSON := e.fs.Bool("json", false, "print JSON")
+	if err := e.fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Lenient, so keys that fail to load are reported instead of stopping
+	// the manager from opening.
+	m, err := e.manager(km.WithLenientReload())
+	if err != nil {
+		return err
+	}
+
+	report, err := m.VerifyStoreIntegrity()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tKID\tDETAIL\tREPAIR")
+		for _, f := range report.Findings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Kind, f.KID, f.Detail, f.Repair)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("%d findings in %d keys", len(report.Findings), report.Checked)
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/filestore"
+	"github.com/keylet-auth/keys-manager/testvectors"
+)
+
+func keysctl(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	if err := run(args, &out, &errOut); err != nil {
+		t.Fatalf("keysctl %v: %v\n%s", args, err, errOut.String())
+	}
+	return out.String()
+}
+
+func writeMasterKey(t *testing.T, dir, name string, b byte) string {
+	t.Helper()
+
+	key := bytes.Repeat([]byte{b}, 32)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func listKeys(t *testing.T, store string) []km.KeyInfo {
+	t.Helper()
+
+	var keys []km.KeyInfo
+	if err := json.Unmarshal([]byte(keysctl(t, "-store", store, "list", "-json")), &keys); err != nil {
+		t.Fatalf("parse list output: %v", err)
+	}
+	return keys
+}
+
+func TestKeysctl_Lifecycle(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "ES256,EdDSA")
+	keysctl(t, "-store", store, "-master-key-file", master, "rotate", "-alg", "EdDSA")
+
+	keys := listKeys(t, store)
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d", len(keys))
+	}
+
+	var jwks km.JWKS
+	if err := json.Unmarshal([]byte(keysctl(t, "-store", store, "jwks")), &jwks); err != nil || len(jwks.Keys) != 3 {
+		t.Fatalf("expected JWKS with 3 keys, got %v (%v)", jwks, err)
+	}
+
+	kid := keys[0].KID
+	if block, _ := pem.Decode([]byte(keysctl(t, "-store", store, "export-public", kid))); block == nil || block.Type != "PUBLIC KEY" {
+		t.Fatalf("expected PEM public key for %s", kid)
+	}
+
+	keysctl(t, "-store", store, "-master-key-file", master, "revoke", kid)
+	for _, k := range listKeys(t, store) {
+		if k.KID == kid && k.State != km.KeyStateRevoked {
+			t.Fatalf("expected %s to be revoked, got %s", kid, k.State)
+		}
+	}
+
+	table := keysctl(t, "-store", store, "list")
+	if !strings.Contains(table, "KID") || !strings.Contains(table, kid) {
+		t.Fatalf("unexpected list table:\n%s", table)
+	}
+}
+
+func TestKeysctl_ApproveAndReject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+	store := "file:" + path
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "ES256,EdDSA")
+
+	fs := filestore.New(path)
+	stored, _ := fs.List()
+	for _, k := range stored {
+		k.IsActive = false
+		k.Pending = true
+		if err := fs.Save(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keysctl(t, "-store", store, "-master-key-file", master, "approve", stored[0].KID)
+	keysctl(t, "-store", store, "-master-key-file", master, "reject", stored[1].KID)
+
+	want := map[string]km.KeyState{stored[0].KID: km.KeyStateActive, stored[1].KID: km.KeyStateRevoked}
+	for _, k := range listKeys(t, store) {
+		if k.State != want[k.KID] {
+			t.Fatalf("expected %s to be %s, got %s", k.KID, want[k.KID], k.State)
+		}
+	}
+}
+
+func TestKeysctl_EncryptPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+	store := "file:" + path
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "EdDSA")
+
+	// Rewrite the key as a legacy row holding plaintext PKCS#8.
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+
+	fs := filestore.New(path)
+	stored, _ := fs.List()
+	stored[0].EncryptedKey = &km.EncryptedKey{Ciphertext: der}
+	stored[0].PublicKey = pubDER
+	if err := fs.Save(stored[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	if err := run([]string{"-store", store, "-master-key-file", master, "rotate", "-alg", "EdDSA"}, &out, &errOut); !errors.Is(err, km.ErrPlaintextKey) {
+		t.Fatalf("expected ErrPlaintextKey, got %v", err)
+	}
+
+	kids := keysctl(t, "-store", store, "-master-key-file", master, "encrypt-plaintext")
+	if strings.TrimSpace(kids) != stored[0].KID {
+		t.Fatalf("expected %s to be encrypted, got %q", stored[0].KID, kids)
+	}
+
+	keysctl(t, "-store", store, "-master-key-file", master, "verify")
+}
+
+func TestKeysctl_ExportWrapped(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+	kek := writeMasterKey(t, dir, "kek", 2)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "EdDSA")
+	kid := listKeys(t, store)[0].KID
+
+	out := keysctl(t, "-store", store, "-master-key-file", master, "export-wrapped", "-wrapping-key-file", kek, kid)
+	wrapped, err := base64.StdEncoding.DecodeString(strings.TrimSpace(out))
+	if err != nil || len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		t.Fatalf("expected a base64 RFC 5649 blob, got %q", out)
+	}
+}
+
+func TestKeysctl_Delete(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "EdDSA")
+	kid := listKeys(t, store)[0].KID
+
+	var out, errOut bytes.Buffer
+	if err := run([]string{"-store", store, "-master-key-file", master, "delete", kid}, &out, &errOut); !errors.Is(err, km.ErrKeyInUse) {
+		t.Fatalf("expected ErrKeyInUse for the active key, got %v", err)
+	}
+
+	keysctl(t, "-store", store, "-master-key-file", master, "delete", "-force", kid)
+	if keys := listKeys(t, store); len(keys) != 0 {
+		t.Fatalf("expected no keys after forced delete, got %d", len(keys))
+	}
+}
+
+func TestKeysctl_Fingerprint(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "ES256")
+	kid := listKeys(t, store)[0].KID
+
+	lines := strings.Split(strings.TrimSpace(keysctl(t, "-store", store, "fingerprint", kid)), "\n")
+	if len(lines) != 2 || strings.Count(lines[0], ":") != 31 || !strings.HasPrefix(lines[1], "SHA256:") {
+		t.Fatalf("unexpected fingerprint output %q", lines)
+	}
+}
+
+func TestKeysctl_ConfigTTL(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+
+	cfg := filepath.Join(dir, "config.json")
+	data := `{"store": "` + store + `", "master_key_file": "` + master + `", "ttl": "24h"}`
+	if err := os.WriteFile(cfg, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keysctl(t, "-config", cfg, "init", "-alg", "EdDSA")
+
+	k := listKeys(t, store)[0]
+	if k.ExpiresAt == nil || k.ExpiresAt.Sub(k.CreatedAt) != 24*time.Hour {
+		t.Fatalf("expected a 24h key lifetime from the config, got %v", k.ExpiresAt)
+	}
+
+	if err := os.WriteFile(cfg, []byte(`{"ttl": 86400}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var out, errOut bytes.Buffer
+	if err := run([]string{"-config", cfg, "list"}, &out, &errOut); err == nil {
+		t.Fatalf("expected a numeric ttl to be rejected")
+	}
+}
+
+func TestKeysctl_ClosesManagers(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "EdDSA")
+
+	e := &env{cfg: config{Store: store, MasterKeyFile: master}}
+	m, err := e.manager()
+	if err != nil {
+		t.Fatalf("manager error: %v", err)
+	}
+	if err := e.close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	if err := m.ReloadCache(); !errors.Is(err, km.ErrClosed) {
+		t.Fatalf("expected the manager to be closed, got %v", err)
+	}
+}
+
+func TestKeysctl_ImportPEMAndReEncrypt(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	oldMaster := writeMasterKey(t, dir, "old", 1)
+	newMaster := writeMasterKey(t, dir, "new", 2)
+
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pemPath := filepath.Join(dir, "key.pem")
+	_ = os.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+
+	t.Setenv("KEYSCTL_MASTER_KEY", strings.TrimSpace(readFile(t, oldMaster)))
+
+	kid := strings.TrimSpace(keysctl(t, "-store", store, "import-pem", "-alg", "ES256", "-activate", pemPath))
+	if kid == "" {
+		t.Fatalf("expected import to print the new KID")
+	}
+
+	keysctl(t, "-store", store, "re-encrypt", "-new-master-key-file", newMaster)
+
+	config := filepath.Join(dir, "config.json")
+	_ = os.WriteFile(config, []byte(`{"store": "`+store+`", "master_key_file": "`+newMaster+`"}`), 0o600)
+
+	keysctl(t, "-config", config, "rotate", "-alg", "ES256")
+
+	var out, errOut bytes.Buffer
+	if err := run([]string{"-store", store, "rotate", "-alg", "ES256"}, &out, &errOut); err == nil {
+		t.Fatalf("expected the old master key to be rejected after re-encrypt")
+	}
+}
+
+func TestKeysctl_ImportJWKS(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	jwk := map[string]any{
+		"kty": "OKP", "crv": "Ed25519", "kid": "idp-1", "status": "ACTIVE",
+		"x": base64.RawURLEncoding.EncodeToString(pub),
+		"d": base64.RawURLEncoding.EncodeToString(priv.Seed()),
+	}
+	data, _ := json.Marshal(map[string]any{"keys": []any{jwk}})
+	path := filepath.Join(dir, "jwks.json")
+	_ = os.WriteFile(path, data, 0o600)
+
+	out := keysctl(t, "-store", store, "-master-key-file", master, "import-jwks", "-activate", path)
+	if strings.TrimSpace(out) != "idp-1" {
+		t.Fatalf("expected the imported KID, got %q", out)
+	}
+
+	keys := listKeys(t, store)
+	if len(keys) != 1 || keys[0].KID != "idp-1" || keys[0].State != km.KeyStateActive {
+		t.Fatalf("unexpected keys after import: %+v", keys)
+	}
+
+	out = keysctl(t, "-store", store, "-master-key-file", master, "import-jwks", path)
+	if !strings.Contains(out, "skipped idp-1") {
+		t.Fatalf("expected the stored KID to be skipped, got %q", out)
+	}
+}
+
+func TestKeysctl_BackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	restored := "file:" + filepath.Join(dir, "restored.json")
+	master := writeMasterKey(t, dir, "master", 1)
+	drillMaster := writeMasterKey(t, dir, "drill", 2)
+	backup := filepath.Join(dir, "keys.backup")
+	t.Setenv("KEYSCTL_BACKUP_PASSPHRASE", "drill passphrase")
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "ES256,EdDSA")
+	keysctl(t, "-store", store, "-master-key-file", master, "backup", "-out", backup)
+	keysctl(t, "-store", restored, "-master-key-file", drillMaster, "restore", backup)
+
+	if got, want := listKeys(t, restored), listKeys(t, store); len(got) != len(want) || got[0].KID != want[0].KID {
+		t.Fatalf("restored keys %+v do not match %+v", got, want)
+	}
+
+	keysctl(t, "-store", restored, "-master-key-file", drillMaster, "rotate", "-alg", "ES256")
+	keysctl(t, "-store", restored, "-master-key-file", drillMaster, "verify")
+
+	var verifyOut, verifyErr bytes.Buffer
+	if err := run([]string{"-store", restored, "-master-key-file", master, "verify"}, &verifyOut, &verifyErr); err == nil ||
+		!strings.Contains(verifyOut.String(), "decrypt_failed") {
+		t.Fatalf("verify with the wrong master key must report findings, got %v\n%s", err, verifyOut.String())
+	}
+
+	var out, errOut bytes.Buffer
+	if err := run([]string{"-store", store, "-master-key-file", master, "backup", "-out", backup}, &out, &errOut); err == nil {
+		t.Fatalf("expected an existing backup file not to be overwritten")
+	}
+}
+
+func TestKeysctl_Errors(t *testing.T) {
+	var out, errOut bytes.Buffer
+
+	if err := run([]string{"bogus"}, &out, &errOut); err == nil {
+		t.Fatalf("expected unknown command error")
+	}
+	if err := run([]string{"-store", "s3:bucket", "list"}, &out, &errOut); err == nil {
+		t.Fatalf("expected unsupported store error")
+	}
+	if err := run([]string{"-store", "file:x", "init", "-alg", "HS256"}, &out, &errOut); err == nil {
+		t.Fatalf("expected unsupported alg error")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestKeysctl_Usage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+	store := "file:" + path
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "EdDSA,ES256")
+	kid := listKeys(t, store)[0].KID
+
+	_ = filestore.New(path).AddUsage(context.Background(), []km.UsageDelta{{KID: kid, Signs: 4}})
+
+	var usage []km.UsageDelta
+	if err := json.Unmarshal([]byte(keysctl(t, "-store", store, "usage", "-json")), &usage); err != nil {
+		t.Fatalf("parse usage output: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("expected a row per key, got %+v", usage)
+	}
+	for _, u := range usage {
+		want := uint64(0)
+		if u.KID == kid {
+			want = 4
+		}
+		if u.Signs != want {
+			t.Fatalf("unexpected usage for %s: %+v", u.KID, u)
+		}
+	}
+}
+
+func TestKeysctl_TestVectors(t *testing.T) {
+	dir := t.TempDir()
+	store := "file:" + filepath.Join(dir, "keys.json")
+	master := writeMasterKey(t, dir, "master", 1)
+
+	keysctl(t, "-store", store, "-master-key-file", master, "init", "-alg", "EdDSA")
+
+	var set testvectors.Set
+	out := keysctl(t, "-store", store, "-master-key-file", master, "test-vectors")
+	if err := json.Unmarshal([]byte(out), &set); err != nil {
+		t.Fatalf("parse test-vectors output: %v", err)
+	}
+	if len(set.Tokens) != 1 || set.Tokens[0].KID != listKeys(t, store)[0].KID {
+		t.Fatalf("unexpected vectors: %+v", set.Tokens)
+	}
+}
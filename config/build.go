@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/filestore"
+)
+
+// StoreOpener opens the store named by a driver-specific DSN.
+type StoreOpener func(dsn string) (km.Store, error)
+
+// EncryptorOpener builds an encryptor from its configuration.
+type EncryptorOpener func(cfg EncryptorConfig) (km.Encryptor, error)
+
+var (
+	registryMu sync.RWMutex
+	stores     = map[string]StoreOpener{
+		"file":   openFileStore,
+		"memory": openMemoryStore,
+	}
+	encryptors = map[string]EncryptorOpener{
+		"aesgcm": openAESGCM,
+	}
+)
+
+// RegisterStore makes a store driver available to Build. It panics if
+// driver is already registered.
+func RegisterStore(driver string, open StoreOpener) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := stores[driver]; dup {
+		panic("config: store driver registered twice: " + driver)
+	}
+	stores[driver] = open
+}
+
+// RegisterEncryptor makes an encryptor type available to Build. It panics
+// if typ is already registered.
+func RegisterEncryptor(typ string, open EncryptorOpener) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := encryptors[typ]; dup {
+		panic("config: encryptor type registered twice: " + typ)
+	}
+	encryptors[typ] = open
+}
+
+func lookup[T any](m map[string]T, kind, name string) (T, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	open, ok := m[name]
+	if !ok {
+		names := make([]string, 0, len(m))
+		for n := range m {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return open, fmt.Errorf("config: unknown %s %q (have %s)", kind, name, strings.Join(names, ", "))
+	}
+	return open, nil
+}
+
+// Build opens the configured store and encryptor and returns a manager
+// with the configured schedules. Unless the config is verify-only, every
+// listed alg is given an active key. opts are applied after the options
+// derived from cfg.
+func Build(cfg *Config, opts ...km.Option) (*km.KeyManager, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	openStore, err := lookup(stores, "store driver", cfg.Store.Driver)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStore(cfg.Store.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("config: open store: %w", err)
+	}
+
+	var enc km.Encryptor
+	if !cfg.VerifyOnly {
+		openEnc, err := lookup(encryptors, "encryptor type", cfg.Encryptor.Type)
+		if err != nil {
+			return nil, err
+		}
+		if enc, err = openEnc(cfg.Encryptor); err != nil {
+			return nil, fmt.Errorf("config: open encryptor: %w", err)
+		}
+	}
+
+	ttl := time.Duration(cfg.Rotation.TTL)
+	policy := func() (km.RotationConfig, error) {
+		return km.RotationConfig{TTL: ttl}, nil
+	}
+
+	var base []km.Option
+	if cfg.VerifyOnly {
+		base = append(base, km.WithVerifyOnly())
+	}
+	if cfg.Environment != "" {
+		base = append(base, km.WithEnvironment(cfg.Environment))
+	}
+	if d := time.Duration(cfg.Scheduler.Refresh); d > 0 {
+		base = append(base, km.WithBackgroundRefresh(d))
+	}
+	if d := time.Duration(cfg.Scheduler.RotateExpired); d > 0 {
+		base = append(base, km.WithRotationSchedule(d))
+	}
+
+	m, err := km.NewKeyManager(store, enc, policy, append(base, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.VerifyOnly && len(cfg.Algs) > 0 {
+		if err := m.InitKeys(cfg.Algs); err != nil {
+			return nil, errors.Join(err, m.Close(context.Background()))
+		}
+	}
+
+	return m, nil
+}
+
+// Validate reports missing or inconsistent settings before anything is
+// opened.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Store.Driver == "" {
+		errs = append(errs, errors.New("store.driver is required"))
+	}
+	if !c.VerifyOnly {
+		if c.Encryptor.Type == "" {
+			errs = append(errs, errors.New("encryptor.type is required unless verify_only is set"))
+		}
+		if c.Rotation.TTL <= 0 {
+			errs = append(errs, errors.New("rotation.ttl must be positive"))
+		}
+	}
+	for _, alg := range c.Algs {
+		switch alg {
+		case km.AlgRS256, km.AlgES256, km.AlgEdDSA:
+		default:
+			errs = append(errs, fmt.Errorf("%w: %s", km.ErrUnsupportedAlg, alg))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+func openFileStore(dsn string) (km.Store, error) {
+	if dsn == "" {
+		return nil, errors.New("file store needs a path as dsn")
+	}
+	return filestore.New(dsn), nil
+}
+
+func openMemoryStore(string) (km.Store, error) {
+	return km.NewMockStore(), nil
+}
+
+func openAESGCM(cfg EncryptorConfig) (km.Encryptor, error) {
+	var encoded string
+
+	switch {
+	case cfg.MasterKeyFile != "":
+		data, err := os.ReadFile(cfg.MasterKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	case cfg.MasterKeyEnv != "":
+		encoded = os.Getenv(cfg.MasterKeyEnv)
+	}
+
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, errors.New("aesgcm needs master_key_file or master_key_env")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode master key: %w", err)
+	}
+
+	return km.NewAESGCMEncryptor(key)
+}
@@ -0,0 +1,177 @@
+// Package config assembles a KeyManager from a declarative description of
+// its store, encryptor, algorithms, rotation policy and schedules, read
+// from YAML, JSON and environment variables.
+//
+//	store:
+//	  driver: file
+//	  dsn: /var/lib/keys.json
+//	encryptor:
+//	  type: aesgcm
+//	  master_key_env: KEYS_MASTER_KEY
+//	algs: [ES256, EdDSA]
+//	rotation:
+//	  ttl: 720h
+//	scheduler:
+//	  refresh: 1m
+//	  rotate_expired: 10m
+//
+// Store drivers and encryptor types beyond the built-in ones, such as a
+// database or a cloud KMS, are added with RegisterStore and
+// RegisterEncryptor.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+type Config struct {
+	Store       StoreConfig     `json:"store" yaml:"store"`
+	Encryptor   EncryptorConfig `json:"encryptor" yaml:"encryptor"`
+	Algs        []km.Alg        `json:"algs" yaml:"algs"`
+	Rotation    RotationConfig  `json:"rotation" yaml:"rotation"`
+	Scheduler   SchedulerConfig `json:"scheduler" yaml:"scheduler"`
+	VerifyOnly  bool            `json:"verify_only" yaml:"verify_only"`
+	Environment string          `json:"environment" yaml:"environment"`
+}
+
+type StoreConfig struct {
+	Driver string `json:"driver" yaml:"driver"`
+	DSN    string `json:"dsn" yaml:"dsn"`
+}
+
+// EncryptorConfig selects how private keys are encrypted at rest. KeyID
+// names the key in an external KMS; the master key fields are used by the
+// built-in aesgcm type.
+type EncryptorConfig struct {
+	Type          string `json:"type" yaml:"type"`
+	KeyID         string `json:"key_id" yaml:"key_id"`
+	MasterKeyFile string `json:"master_key_file" yaml:"master_key_file"`
+	MasterKeyEnv  string `json:"master_key_env" yaml:"master_key_env"`
+}
+
+type RotationConfig struct {
+	TTL Duration `json:"ttl" yaml:"ttl"`
+}
+
+type SchedulerConfig struct {
+	Refresh       Duration `json:"refresh" yaml:"refresh"`
+	RotateExpired Duration `json:"rotate_expired" yaml:"rotate_expired"`
+}
+
+// Duration is a time.Duration written as a string such as "720h".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Load reads a YAML or JSON file, chosen by extension, and applies
+// environment overrides with the default prefix.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		cfg, err = ParseJSON(data)
+	case ".yaml", ".yml":
+		cfg, err = ParseYAML(data)
+	default:
+		return nil, fmt.Errorf("config: unknown format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ApplyEnv(DefaultEnvPrefix); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func ParseJSON(data []byte) (*Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("config: parse json: %w", err)
+	}
+	return &cfg, nil
+}
+
+func ParseYAML(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("config: parse yaml: %w", err)
+	}
+	return &cfg, nil
+}
+
+const DefaultEnvPrefix = "KEYS_MANAGER"
+
+// ApplyEnv overrides fields from environment variables named after the
+// config path under prefix, e.g. KEYS_MANAGER_STORE_DSN or
+// KEYS_MANAGER_ALGS=ES256,EdDSA. Unset variables leave fields unchanged.
+func (c *Config) ApplyEnv(prefix string) error {
+	str := func(name string, dst *string) {
+		if v, ok := os.LookupEnv(prefix + "_" + name); ok {
+			*dst = v
+		}
+	}
+	dur := func(name string, dst *Duration) error {
+		if v, ok := os.LookupEnv(prefix + "_" + name); ok {
+			if err := dst.UnmarshalText([]byte(v)); err != nil {
+				return fmt.Errorf("config: %s_%s: %w", prefix, name, err)
+			}
+		}
+		return nil
+	}
+
+	str("STORE_DRIVER", &c.Store.Driver)
+	str("STORE_DSN", &c.Store.DSN)
+	str("ENCRYPTOR_TYPE", &c.Encryptor.Type)
+	str("ENCRYPTOR_KEY_ID", &c.Encryptor.KeyID)
+	str("ENCRYPTOR_MASTER_KEY_FILE", &c.Encryptor.MasterKeyFile)
+	str("ENCRYPTOR_MASTER_KEY_ENV", &c.Encryptor.MasterKeyEnv)
+	str("ENVIRONMENT", &c.Environment)
+
+	if v, ok := os.LookupEnv(prefix + "_ALGS"); ok {
+		c.Algs = nil
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				c.Algs = append(c.Algs, km.Alg(a))
+			}
+		}
+	}
+
+	if v, ok := os.LookupEnv(prefix + "_VERIFY_ONLY"); ok {
+		c.VerifyOnly = v == "1" || strings.EqualFold(v, "true")
+	}
+
+	return errors.Join(
+		dur("ROTATION_TTL", &c.Rotation.TTL),
+		dur("SCHEDULER_REFRESH", &c.Scheduler.Refresh),
+		dur("SCHEDULER_ROTATE_EXPIRED", &c.Scheduler.RotateExpired),
+	)
+}
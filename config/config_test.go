@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+const yamlConfig = `
+store:
+  driver: file
+  dsn: KEYS_PATH
+encryptor:
+  type: aesgcm
+  master_key_env: CONFIG_TEST_MASTER_KEY
+algs: [ES256, EdDSA]
+rotation:
+  ttl: 720h
+scheduler:
+  refresh: 1m
+`
+
+func TestLoad_YAMLWithEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.yaml")
+	if err := os.WriteFile(path, []byte(yamlConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KEYS_MANAGER_STORE_DSN", filepath.Join(dir, "keys.json"))
+	t.Setenv("KEYS_MANAGER_ROTATION_TTL", "24h")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	if cfg.Store.Driver != "file" || cfg.Store.DSN != filepath.Join(dir, "keys.json") {
+		t.Fatalf("unexpected store config: %+v", cfg.Store)
+	}
+	if time.Duration(cfg.Rotation.TTL) != 24*time.Hour {
+		t.Fatalf("env must override ttl, got %v", time.Duration(cfg.Rotation.TTL))
+	}
+	if time.Duration(cfg.Scheduler.Refresh) != time.Minute {
+		t.Fatalf("expected refresh 1m, got %v", time.Duration(cfg.Scheduler.Refresh))
+	}
+	if len(cfg.Algs) != 2 || cfg.Algs[1] != km.AlgEdDSA {
+		t.Fatalf("unexpected algs: %v", cfg.Algs)
+	}
+}
+
+func TestParseJSON_MatchesYAML(t *testing.T) {
+	cfg, err := ParseJSON([]byte(`{
+		"store": {"driver": "memory"},
+		"encryptor": {"type": "aesgcm", "key_id": "kms/1"},
+		"algs": ["EdDSA"],
+		"rotation": {"ttl": "1h30m"},
+		"scheduler": {"rotate_expired": "5m"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseJSON error: %v", err)
+	}
+
+	if time.Duration(cfg.Rotation.TTL) != 90*time.Minute || time.Duration(cfg.Scheduler.RotateExpired) != 5*time.Minute {
+		t.Fatalf("unexpected durations: %+v %+v", cfg.Rotation, cfg.Scheduler)
+	}
+	if cfg.Encryptor.KeyID != "kms/1" {
+		t.Fatalf("unexpected encryptor: %+v", cfg.Encryptor)
+	}
+
+	if _, err := ParseJSON([]byte(`{"rotation": {"ttl": "soon"}}`)); err == nil {
+		t.Fatalf("invalid duration must fail")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	t.Setenv("CONFIG_TEST_MASTER_KEY", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+	cfg, err := ParseYAML([]byte(strings.Replace(yamlConfig, "KEYS_PATH", filepath.Join(t.TempDir(), "keys.json"), 1)))
+	if err != nil {
+		t.Fatalf("ParseYAML error: %v", err)
+	}
+
+	m, err := Build(cfg)
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	defer m.Close(context.Background())
+
+	for _, alg := range cfg.Algs {
+		if _, err := m.Sign(alg, func(string) ([]byte, error) { return []byte("x"), nil }); err != nil {
+			t.Fatalf("expected an active %s key: %v", alg, err)
+		}
+	}
+
+	cfg.VerifyOnly = true
+	cfg.Encryptor = EncryptorConfig{}
+
+	v, err := Build(cfg)
+	if err != nil {
+		t.Fatalf("verify-only Build error: %v", err)
+	}
+	defer v.Close(context.Background())
+
+	if len(v.Keys()) != 2 {
+		t.Fatalf("verify-only manager must see the stored keys, got %d", len(v.Keys()))
+	}
+}
+
+func TestBuild_Registry(t *testing.T) {
+	opened := ""
+	RegisterStore("test-registry", func(dsn string) (km.Store, error) {
+		opened = dsn
+		return km.NewMockStore(), nil
+	})
+	RegisterEncryptor("test-kms", func(cfg EncryptorConfig) (km.Encryptor, error) {
+		if cfg.KeyID != "projects/p/keys/k" {
+			return nil, errors.New("unexpected key id")
+		}
+		return km.MockEncryptor{}, nil
+	})
+
+	m, err := Build(&Config{
+		Store:     StoreConfig{Driver: "test-registry", DSN: "db://keys"},
+		Encryptor: EncryptorConfig{Type: "test-kms", KeyID: "projects/p/keys/k"},
+		Algs:      []km.Alg{km.AlgEdDSA},
+		Rotation:  RotationConfig{TTL: Duration(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	defer m.Close(context.Background())
+
+	if opened != "db://keys" {
+		t.Fatalf("store opener must receive the dsn, got %q", opened)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	err := (&Config{Algs: []km.Alg{"HS256"}}).Validate()
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if !errors.Is(err, km.ErrUnsupportedAlg) {
+		t.Fatalf("expected ErrUnsupportedAlg in %v", err)
+	}
+	for _, want := range []string{"store.driver", "encryptor.type", "rotation.ttl"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+
+	if _, err := Build(&Config{Store: StoreConfig{Driver: "nope"}, VerifyOnly: true}); err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Fatalf("unknown driver must list known ones, got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+module github.com/keylet-auth/keys-manager/config
+
+go 1.25.0
+
+require (
+	github.com/keylet-auth/keys-manager v0.1.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,173 @@
+package keys_manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// cookieSlot is the line of keys EncryptCookie uses. Initialize it with
+// InitKeysWithPurpose(PurposeCookie, []Alg{AlgA256GCM}); it rotates under
+// the same policy as signing keys.
+var cookieSlot = keySlot{alg: AlgA256GCM, purpose: PurposeCookie}
+
+func (km *KeyManager) loadSecret(ctx context.Context, k *Key) (*CachedKey, error) {
+	if km.verifyOnly {
+		return &CachedKey{key: k}, nil
+	}
+
+	secret, err := km.decrypt(ctx, k)
+	if err != nil {
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+	}
+	if len(secret) != 32 {
+		wipeBytes(secret)
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("invalid %s secret length %d", k.Alg, len(secret))}
+	}
+
+	return &CachedKey{key: k, secret: secret, ctDigest: digestCiphertext(k.EncryptedKey)}, nil
+}
+
+func (km *KeyManager) EncryptCookie(name string, value []byte) (string, error) {
+	return km.EncryptCookieCtx(context.Background(), name, value)
+}
+
+// EncryptCookieCtx seals value with the active cookie key. The result is a
+// base64url string led by the key's KID, so DecryptCookie keeps accepting
+// cookies sealed before a rotation. name is authenticated with the value,
+// so a cookie cannot be replayed under another name.
+func (km *KeyManager) EncryptCookieCtx(ctx context.Context, name string, value []byte) (string, error) {
+	ck := km.activeKeyFor(ctx, cookieSlot)
+	if ck == nil {
+		return "", fmt.Errorf("%w for %s", ErrNoActiveKey, cookieSlot)
+	}
+	if ck.secret == nil {
+		return "", ErrVerifyOnly
+	}
+
+	kid := ck.key.KID
+	if len(kid) > 255 {
+		return "", fmt.Errorf("cookie: kid %s too long", kid)
+	}
+
+	aead, err := newCookieAEAD(ck.secret)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 0, 1+len(kid)+aead.NonceSize()+len(value)+aead.Overhead())
+	out = append(out, byte(len(kid)))
+	out = append(out, kid...)
+
+	nonce := out[len(out) : len(out)+aead.NonceSize()]
+	if _, err := io.ReadFull(km.entropy(), nonce); err != nil {
+		return "", err
+	}
+	out = out[:len(out)+len(nonce)]
+
+	out = aead.Seal(out, nonce, value, cookieAD(name, kid))
+
+	ck.usage.recordSign(km.clock.Now())
+
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+func (km *KeyManager) DecryptCookie(name, cookie string) ([]byte, error) {
+	return km.DecryptCookieCtx(context.Background(), name, cookie)
+}
+
+// DecryptCookieCtx opens a value sealed by EncryptCookie with the key
+// named in its prefix. Cookies sealed with revoked keys, or keys no longer
+// in the store, are rejected.
+func (km *KeyManager) DecryptCookieCtx(ctx context.Context, name, cookie string) ([]byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil || len(raw) == 0 || len(raw) < 1+int(raw[0]) {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidCookie)
+	}
+
+	kid := string(raw[1 : 1+raw[0]])
+	sealed := raw[1+raw[0]:]
+
+	ck := km.keyByKIDCtx(ctx, kid)
+	if ck == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w: %s", ErrInvalidCookie, ErrKeyNotFound, kid)
+	}
+	if ck.key.Alg != AlgA256GCM || ck.key.Purpose != PurposeCookie {
+		return nil, fmt.Errorf("%w: key %s is not a cookie key", ErrInvalidCookie, kid)
+	}
+	if ck.key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: %w: %s", ErrInvalidCookie, ErrKeyRevoked, kid)
+	}
+	if ck.secret == nil {
+		return nil, ErrVerifyOnly
+	}
+
+	aead, err := newCookieAEAD(ck.secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidCookie)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, cookieAD(name, kid))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCookie, err)
+	}
+
+	ck.usage.recordVerify(km.clock.Now())
+
+	return value, nil
+}
+
+func newCookieAEAD(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func cookieAD(name, kid string) []byte {
+	ad := make([]byte, 0, len(name)+1+len(kid))
+	ad = append(ad, name...)
+	ad = append(ad, 0)
+	return append(ad, kid...)
+}
+
+func selfTestSecret(ck *CachedKey) error {
+	if ck.secret == nil {
+		return nil
+	}
+
+	aead, err := newCookieAEAD(ck.secret)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nil, nonce, selfTestCanary, nil)
+
+	opened, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return fmt.Errorf("open canary: %w", err)
+	}
+	if !bytes.Equal(opened, selfTestCanary) {
+		return errors.New("open canary: mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newCookieManager(t *testing.T) (*KeyManager, *MockStore) {
+	t.Helper()
+
+	store := NewMockStore()
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := km.InitKeysWithPurpose(PurposeCookie, []Alg{AlgA256GCM}); err != nil {
+		t.Fatalf("InitKeysWithPurpose error: %v", err)
+	}
+
+	return km, store
+}
+
+func TestCookie_RoundTripAcrossRotation(t *testing.T) {
+	km, _ := newCookieManager(t)
+
+	sealed, err := km.EncryptCookie("session", []byte("user=42"))
+	if err != nil {
+		t.Fatalf("EncryptCookie error: %v", err)
+	}
+
+	if err := km.RotateWithPurpose(AlgA256GCM, PurposeCookie); err != nil {
+		t.Fatalf("rotate error: %v", err)
+	}
+
+	got, err := km.DecryptCookie("session", sealed)
+	if err != nil {
+		t.Fatalf("cookie sealed before rotation must still open: %v", err)
+	}
+	if string(got) != "user=42" {
+		t.Fatalf("unexpected value %q", got)
+	}
+
+	fresh, _ := km.EncryptCookie("session", []byte("user=42"))
+	if fresh[:20] == sealed[:20] {
+		t.Fatalf("new cookies must be sealed with the rotated key")
+	}
+}
+
+func TestCookie_RejectsTampering(t *testing.T) {
+	km, _ := newCookieManager(t)
+
+	sealed, _ := km.EncryptCookie("session", []byte("user=42"))
+
+	if _, err := km.DecryptCookie("other", sealed); !errors.Is(err, ErrInvalidCookie) {
+		t.Fatalf("cookie must be bound to its name, got %v", err)
+	}
+
+	b := []byte(sealed)
+	b[len(b)-2] ^= 1
+	if _, err := km.DecryptCookie("session", string(b)); !errors.Is(err, ErrInvalidCookie) {
+		t.Fatalf("tampered cookie must be rejected, got %v", err)
+	}
+
+	if _, err := km.DecryptCookie("session", "%%%"); !errors.Is(err, ErrInvalidCookie) {
+		t.Fatalf("malformed cookie must be rejected, got %v", err)
+	}
+}
+
+func TestCookie_KeysAreNotPublishedOrUsedForSigning(t *testing.T) {
+	km, _ := newCookieManager(t)
+
+	raw, _ := km.JWKS()
+	if string(raw) != `{"keys":[]}` {
+		t.Fatalf("cookie keys must not be published, got %s", raw)
+	}
+
+	_, err := km.SignWithPurpose(AlgA256GCM, PurposeCookie, func(kid string) ([]byte, error) {
+		return []byte("x"), nil
+	})
+	if !errors.Is(err, ErrUnsupportedAlg) {
+		t.Fatalf("cookie keys must not sign, got %v", err)
+	}
+
+	if r := km.SelfTest(); len(r.Keys) != 1 || r.Keys[0].Err != nil {
+		t.Fatalf("self test must cover cookie keys, got %+v", r.Keys)
+	}
+}
+
+func TestCookie_RevokedKeyRejected(t *testing.T) {
+	km, _ := newCookieManager(t)
+
+	sealed, _ := km.EncryptCookie("session", []byte("v"))
+	kid := km.activeKeyFor(context.Background(), cookieSlot).key.KID
+
+	_ = km.RotateWithPurpose(AlgA256GCM, PurposeCookie)
+	if err := km.Revoke(kid); err != nil {
+		t.Fatalf("Revoke error: %v", err)
+	}
+
+	if _, err := km.DecryptCookie("session", sealed); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("cookie sealed with a revoked key must be rejected, got %v", err)
+	}
+}
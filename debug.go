@@ -0,0 +1,112 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type reloadStats struct {
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+	lastOK    atomic.Int64
+	lastErr   atomic.Pointer[string]
+	causes    reloadCauses
+}
+
+func (s *reloadStats) record(clock Clock, err error) {
+	if err != nil {
+		s.failed.Add(1)
+		msg := err.Error()
+		s.lastErr.Store(&msg)
+		return
+	}
+
+	s.succeeded.Add(1)
+	if clock != nil {
+		s.lastOK.Store(clock.Now().UnixNano())
+	}
+}
+
+func (s *reloadStats) lastSuccess() time.Time {
+	ns := s.lastOK.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
+
+type DebugKey struct {
+	KeyInfo
+	Loaded bool `json:"loaded"`
+}
+
+type DebugInfo struct {
+	CacheSize       int                    `json:"cache_size"`
+	BoundedCached   int                    `json:"bounded_cached,omitempty"`
+	StoreVersion    string                 `json:"store_version,omitempty"`
+	Generation      uint64                 `json:"generation"`
+	LastReload      time.Time              `json:"last_reload"`
+	Reloads         uint64                 `json:"reloads"`
+	ReloadErrors    uint64                 `json:"reload_errors"`
+	LastReloadError string                 `json:"last_reload_error,omitempty"`
+	ReloadCauses    map[ReloadCause]uint64 `json:"reload_causes,omitempty"`
+	Keys            []DebugKey             `json:"keys"`
+	Quarantined     []QuarantinedKeyHealth `json:"quarantined,omitempty"`
+	DuplicateActive []DuplicateActive      `json:"duplicate_active,omitempty"`
+	Closed          bool                   `json:"closed,omitempty"`
+}
+
+// Debug returns a point-in-time view of the key cache for incident
+// inspection. It never touches the store and never exposes key material.
+func (km *KeyManager) Debug() *DebugInfo {
+	snap := km.snapshot()
+
+	info := &DebugInfo{
+		CacheSize:    len(snap.cache),
+		StoreVersion: snap.version,
+		Generation:   snap.generation,
+		LastReload:   km.reloadStats.lastSuccess(),
+		Reloads:      km.reloadStats.succeeded.Load(),
+		ReloadErrors: km.reloadStats.failed.Load(),
+		ReloadCauses: km.reloadStats.causes.snapshot(),
+		Closed:       km.closed.Load(),
+	}
+
+	if msg := km.reloadStats.lastErr.Load(); msg != nil {
+		info.LastReloadError = *msg
+	}
+
+	if km.bounded != nil {
+		info.BoundedCached = km.bounded.len()
+	}
+
+	for _, ki := range km.Keys() {
+		ck := snap.cache[ki.KID]
+		info.Keys = append(info.Keys, DebugKey{KeyInfo: ki, Loaded: ck != nil && !ck.deferred})
+	}
+
+	info.Quarantined = quarantineHealth(snap.quarantine)
+	info.DuplicateActive = snap.duplicates
+
+	return info
+}
+
+// DebugHandler serves Debug as JSON. It is meant for an internal admin
+// listener, not for public routes.
+func (km *KeyManager) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(km.Debug())
+	})
+}
+
+// PublishExpvar exposes Debug under name in the expvar registry, so it
+// shows up on /debug/vars. Like expvar.Publish it panics if name is
+// already registered.
+func (km *KeyManager) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return km.Debug() }))
+}
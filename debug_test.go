@@ -0,0 +1,65 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebug_CountsReloadsAndErrors(t *testing.T) {
+	store := &FailingStore{MockStore: *NewMockStore()}
+
+	signer, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = signer.InitKeys([]Alg{AlgEdDSA})
+	_ = signer.Rotate(AlgEdDSA)
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithLazyDecrypt())
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	store.FailList = true
+	if err := km.ReloadCache(); err == nil {
+		t.Fatalf("expected reload error")
+	}
+
+	info := km.Debug()
+	if info.CacheSize != 2 || len(info.Keys) != 2 {
+		t.Fatalf("expected 2 cached keys, got %+v", info)
+	}
+	if info.ReloadErrors != 1 || info.LastReloadError == "" {
+		t.Fatalf("expected one recorded reload error, got %+v", info)
+	}
+	if info.Reloads != 1 || info.LastReload.IsZero() {
+		t.Fatalf("expected the initial reload to be recorded, got %+v", info)
+	}
+
+	for _, k := range info.Keys {
+		if loaded := k.State == KeyStateActive; k.Loaded != loaded {
+			t.Fatalf("key %s in state %s: loaded = %v", k.KID, k.State, k.Loaded)
+		}
+	}
+}
+
+func TestDebugHandler(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	rec := httptest.NewRecorder()
+	km.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/keys", nil))
+
+	var info DebugInfo
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(info.Keys) != 1 || info.Keys[0].State != KeyStateActive {
+		t.Fatalf("unexpected debug keys: %+v", info.Keys)
+	}
+
+	km.PublishExpvar("keys_manager_debug_test")
+	if v := expvar.Get("keys_manager_debug_test"); v == nil || v.String() == "" {
+		t.Fatalf("expvar must be published")
+	}
+}
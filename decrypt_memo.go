@@ -0,0 +1,55 @@
+package keys_manager
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+type ciphertextDigest [sha256.Size]byte
+
+// decryptMemo indexes decrypted keys of the previous cache by ciphertext,
+// so a reload does not decrypt the same ciphertext again even when the
+// key's metadata changed or the store does not track UpdatedAt.
+type decryptMemo map[ciphertextDigest]*CachedKey
+
+func newDecryptMemo(cache map[string]*CachedKey) decryptMemo {
+	memo := make(decryptMemo, len(cache))
+	for _, ck := range cache {
+		if (ck.priv != nil || ck.secret != nil) && ck.ctDigest != (ciphertextDigest{}) {
+			memo[ck.ctDigest] = ck
+		}
+	}
+	return memo
+}
+
+// lookup returns a cached entry for the same ciphertext and algorithm.
+func (m decryptMemo) lookup(k *Key) (*CachedKey, ciphertextDigest) {
+	d := digestCiphertext(k.EncryptedKey)
+	if d == (ciphertextDigest{}) {
+		return nil, d
+	}
+
+	ck := m[d]
+	if ck == nil || ck.key.Alg != k.Alg {
+		return nil, d
+	}
+	return ck, d
+}
+
+func digestCiphertext(ek *EncryptedKey) ciphertextDigest {
+	if ek == nil || len(ek.Ciphertext) == 0 {
+		return ciphertextDigest{}
+	}
+
+	h := sha256.New()
+
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(ek.Nonce)))
+	h.Write(n[:])
+	h.Write(ek.Nonce)
+	h.Write(ek.Ciphertext)
+
+	var d ciphertextDigest
+	h.Sum(d[:0])
+	return d
+}
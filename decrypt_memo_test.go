@@ -0,0 +1,73 @@
+package keys_manager
+
+import (
+	"crypto"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDecryptMemo_ReusesSameCiphertext(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	k := makeTestKey("k1", AlgEdDSA, true, nil, enc, priv)
+	k.UpdatedAt = time.Now()
+	store.Save(k)
+
+	km, _ := NewKeyManager(store, enc, mockPolicy)
+
+	// Metadata change with the same ciphertext.
+	updated := *k
+	updated.UpdatedAt = k.UpdatedAt.Add(time.Second)
+	updated.Labels = map[string]string{"tier": "gold"}
+	store.Save(&updated)
+
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+	if got := decrypts.Load(); got != 1 {
+		t.Fatalf("expected ciphertext memo hit, got %d decrypts", got)
+	}
+
+	if _, err := km.Signer("k1"); err != nil {
+		t.Fatalf("Signer error: %v", err)
+	}
+}
+
+func TestDecryptMemo_NewCiphertextDecrypts(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("k1", AlgEdDSA, true, nil, enc, priv))
+
+	km, _ := NewKeyManager(store, enc, mockPolicy)
+
+	other, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("k1", AlgEdDSA, true, nil, enc, other))
+
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+	if got := decrypts.Load(); got != 2 {
+		t.Fatalf("expected changed ciphertext to be decrypted, got %d decrypts", got)
+	}
+
+	pub, _ := km.GetPublicKey("k1")
+	if !other.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(pub) {
+		t.Fatalf("expected cache to hold the new key")
+	}
+}
+
+func TestDigestCiphertext_SeparatesNonceAndCiphertext(t *testing.T) {
+	a := digestCiphertext(&EncryptedKey{Nonce: []byte{1, 2}, Ciphertext: []byte{3}})
+	b := digestCiphertext(&EncryptedKey{Nonce: []byte{1}, Ciphertext: []byte{2, 3}})
+
+	if a == b {
+		t.Fatalf("expected different digests for different nonce/ciphertext splits")
+	}
+}
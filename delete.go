@@ -0,0 +1,90 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// KeyDeleter is implemented by stores that can remove a key for good. It
+// is required by DeleteKey.
+type KeyDeleter interface {
+	Delete(kid string) error
+}
+
+func (km *KeyManager) DeleteKey(kid string, force bool) error {
+	return km.DeleteKeyCtx(context.Background(), kid, force)
+}
+
+// DeleteKeyCtx removes kid from the store and drops it from the cache; its
+// material is wiped once no signature can still be using it.
+// Unless force is set it refuses, with ErrKeyInUse, keys that are active
+// or still in grace: inactive keys that are neither revoked nor past their
+// verification window (ExpiresAt plus WithVerifyGrace), since tokens they
+// signed may still be in use. Revoke such a key first, or wait for it to
+// expire.
+func (km *KeyManager) DeleteKeyCtx(ctx context.Context, kid string, force bool) error {
+	if km.verifyOnly {
+		return ErrVerifyOnly
+	}
+	if km.closed.Load() {
+		return ErrClosed
+	}
+
+	deleter, ok := km.store.(KeyDeleter)
+	if !ok {
+		return fmt.Errorf("%w: requires KeyDeleter", ErrStoreUnsupported)
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.KID != kid {
+			continue
+		}
+		if err := km.authorize(ctx, ActionDelete, k); err != nil {
+			return err
+		}
+		if !force {
+			if err := km.checkDeletable(k); err != nil {
+				return err
+			}
+		}
+
+		if err := deleter.Delete(kid); err != nil {
+			return err
+		}
+
+		km.logger().Warn("key deleted", "kid", kid, "alg", k.Alg, "active", k.IsActive, "forced", force)
+		km.publish(ctx, LifecycleEvent{Type: EventKeyDeleted, KID: kid, Alg: k.Alg, Purpose: k.Purpose})
+
+		err := km.reload(ctx)
+
+		if km.bounded != nil {
+			km.bounded.retain(func(ck *CachedKey) bool { return ck.key.KID != kid })
+		}
+
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+func (km *KeyManager) checkDeletable(k *Key) error {
+	now := km.clock.Now()
+	switch {
+	case k.RevokedAt != nil:
+		return nil
+	case k.IsActive:
+		return fmt.Errorf("%w: %s is active", ErrKeyInUse, k.KID)
+	case !km.expired(k, now):
+		return fmt.Errorf("%w: %s has not expired", ErrKeyInUse, k.KID)
+	}
+	if until := km.verifyUntil(k); until != nil && !until.Before(now) {
+		return fmt.Errorf("%w: %s verifies until %s", ErrKeyInUse, k.KID, until.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package keys_manager
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeleteKey_Interlocks(t *testing.T) {
+	store := NewMockStore()
+	pub := &recordingPublisher{}
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithEventPublisher(pub))
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	first := km.activeKey(AlgEdDSA).key.KID
+	_ = km.Rotate(AlgEdDSA)
+	active := km.activeKey(AlgEdDSA).key.KID
+
+	if err := km.DeleteKey(active, false); !errors.Is(err, ErrKeyInUse) {
+		t.Fatalf("active key: expected ErrKeyInUse, got %v", err)
+	}
+	if err := km.DeleteKey(first, false); !errors.Is(err, ErrKeyInUse) {
+		t.Fatalf("unexpired retired key: expected ErrKeyInUse, got %v", err)
+	}
+
+	_ = km.Revoke(first)
+	priv := km.keyByKID(first).priv.(ed25519.PrivateKey)
+
+	if err := km.DeleteKey(first, false); err != nil {
+		t.Fatalf("revoked key: DeleteKey error: %v", err)
+	}
+	if km.keyByKID(first) != nil {
+		t.Fatalf("deleted key must leave the cache")
+	}
+	if _, ok := store.data[first]; ok {
+		t.Fatalf("deleted key must leave the store")
+	}
+	for _, b := range priv {
+		if b != 0 {
+			t.Fatalf("deleted key material must be wiped")
+		}
+	}
+
+	if err := km.DeleteKey(active, true); err != nil {
+		t.Fatalf("forced DeleteKey error: %v", err)
+	}
+	if km.activeKey(AlgEdDSA) != nil {
+		t.Fatalf("forced delete must remove the active key")
+	}
+
+	var deleted int
+	for _, ev := range pub.events {
+		if ev.Type == EventKeyDeleted {
+			deleted++
+		}
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 key.deleted events, got %d", deleted)
+	}
+
+	if err := km.DeleteKey("missing", true); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestDeleteKey_ExpiredAndUnsupported(t *testing.T) {
+	store := NewMockStore()
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(-time.Hour)
+	_ = store.Save(makeTestKey("expired", AlgEdDSA, false, &exp, MockEncryptor{}, priv))
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err := km.DeleteKey("expired", false); err != nil {
+		t.Fatalf("expired key: DeleteKey error: %v", err)
+	}
+
+	plain, _ := NewKeyManager(listOnlyStore{NewMockStore()}, MockEncryptor{}, mockPolicy)
+	if err := plain.DeleteKey("x", true); !errors.Is(err, ErrStoreUnsupported) {
+		t.Fatalf("expected ErrStoreUnsupported, got %v", err)
+	}
+}
+
+func TestDeleteKey_VerifyGraceNeedsForce(t *testing.T) {
+	store := NewMockStore()
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(-time.Hour)
+	_ = store.Save(makeTestKey("grace", AlgEdDSA, false, &exp, MockEncryptor{}, priv))
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithVerifyGrace(2*time.Hour))
+	if err := km.DeleteKey("grace", false); !errors.Is(err, ErrKeyInUse) {
+		t.Fatalf("expected ErrKeyInUse inside verify grace, got %v", err)
+	}
+	if err := km.DeleteKey("grace", true); err != nil {
+		t.Fatalf("forced DeleteKey error: %v", err)
+	}
+}
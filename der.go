@@ -1,7 +1,7 @@
 package keys_manager
 
 import (
-	"encoding/asn1"
+	"errors"
 	"fmt"
 	"math/big"
 )
@@ -10,18 +10,28 @@ type ecdsaSignature struct {
 	R, S *big.Int
 }
 
+var errMalformedDER = errors.New("malformed ECDSA signature")
+
+// DERToRawECDSA converts an ASN.1 DER ECDSA signature into the fixed-size
+// R||S form used by JWS. It parses the DER by hand since this runs on
+// every ES256 signature.
 func DERToRawECDSA(alg Alg, der []byte) ([]byte, error) {
-	var sig ecdsaSignature
+	seq, rest, ok := readDER(der, 0x30)
+	if !ok || len(rest) != 0 {
+		return nil, fmt.Errorf("asn1 unmarshal: %w", errMalformedDER)
+	}
 
-	_, err := asn1.Unmarshal(der, &sig)
-	if err != nil {
-		return nil, fmt.Errorf("asn1 unmarshal: %w", err)
+	rBytes, seq, ok := readDERInt(seq)
+	if !ok {
+		return nil, fmt.Errorf("asn1 unmarshal: %w", errMalformedDER)
 	}
 
-	var size = 32
+	sBytes, seq, ok := readDERInt(seq)
+	if !ok || len(seq) != 0 {
+		return nil, fmt.Errorf("asn1 unmarshal: %w", errMalformedDER)
+	}
 
-	rBytes := sig.R.Bytes()
-	sBytes := sig.S.Bytes()
+	var size = 32
 
 	if len(rBytes) > size || len(sBytes) > size {
 		return nil, fmt.Errorf("R/S too large for alg %s", alg)
@@ -34,3 +44,62 @@ func DERToRawECDSA(alg Alg, der []byte) ([]byte, error) {
 
 	return raw, nil
 }
+
+// verifyKey checks sig over input with ck; callers apply domainInput. With
+// WithDERSignatures an ES256 signature that fails as R||S is retried as
+// DER, since a DER signature can happen to have the raw length. Failures
+// wrap ErrInvalidSignature.
+func (km *KeyManager) verifyKey(alg Alg, ck *CachedKey, input, sig []byte) error {
+	err := verifySignature(alg, ck.pub, input, sig)
+	if err != nil && km.acceptDER && alg == AlgES256 && len(sig) > 0 && sig[0] == 0x30 {
+		if raw, derr := DERToRawECDSA(alg, sig); derr == nil {
+			err = verifySignature(alg, ck.pub, input, raw)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// readDER reads one element with the given tag and returns its contents.
+func readDER(b []byte, tag byte) (contents, rest []byte, ok bool) {
+	if len(b) < 2 || b[0] != tag {
+		return nil, nil, false
+	}
+
+	n, b := int(b[1]), b[2:]
+	if n&0x80 != 0 {
+		lenBytes := n & 0x7f
+		if lenBytes == 0 || lenBytes > 2 || len(b) < lenBytes {
+			return nil, nil, false
+		}
+
+		n = 0
+		for _, c := range b[:lenBytes] {
+			n = n<<8 | int(c)
+		}
+		b = b[lenBytes:]
+	}
+
+	if len(b) < n {
+		return nil, nil, false
+	}
+
+	return b[:n], b[n:], true
+}
+
+// readDERInt reads a non-negative INTEGER and returns its magnitude
+// without leading zero bytes.
+func readDERInt(b []byte) (val, rest []byte, ok bool) {
+	val, rest, ok = readDER(b, 0x02)
+	if !ok || len(val) == 0 || val[0]&0x80 != 0 {
+		return nil, nil, false
+	}
+
+	for len(val) > 0 && val[0] == 0 {
+		val = val[1:]
+	}
+
+	return val, rest, true
+}
@@ -0,0 +1,29 @@
+package keys_manager
+
+import "encoding/binary"
+
+// DomainInput returns the input actually signed for payload under domain:
+// the uvarint length of domain, domain itself, then payload.
+func DomainInput(domain string, payload []byte) []byte {
+	out := make([]byte, 0, binary.MaxVarintLen64+len(domain)+len(payload))
+	out = binary.AppendUvarint(out, uint64(len(domain)))
+	out = append(out, domain...)
+	return append(out, payload...)
+}
+
+// inputKind says whether the domain of WithSigningDomain applies to a
+// signing input.
+type inputKind int
+
+const (
+	rawInput inputKind = iota // Sign, Verify and webhook payloads
+	jwsInput                  // JWS signing inputs, signed as is for standard verifiers
+)
+
+func (km *KeyManager) domainInput(kind inputKind, k *Key, payload []byte) []byte {
+	domain, ok := km.domains[k.Purpose]
+	if !ok || kind == jwsInput {
+		return payload
+	}
+	return DomainInput(domain, payload)
+}
@@ -0,0 +1,89 @@
+package keys_manager
+
+import (
+	"crypto"
+	"encoding/base64"
+	"testing"
+)
+
+func TestSigningDomain_SeparatesPurposes(t *testing.T) {
+	store := NewMockStore()
+
+	webhooks, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithSigningDomain(PurposeWebhook, "webhook-v1"))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := webhooks.InitKeysWithPurpose(PurposeWebhook, []Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeysWithPurpose error: %v", err)
+	}
+
+	payload := []byte("payload")
+	var kid string
+	sig, err := webhooks.SignWithPurpose(AlgEdDSA, PurposeWebhook, func(k string) ([]byte, error) {
+		kid = k
+		return payload, nil
+	})
+	if err != nil {
+		t.Fatalf("SignWithPurpose error: %v", err)
+	}
+
+	if err := webhooks.Verify(kid, payload, sig); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+
+	ck := webhooks.keyByKID(kid)
+	if err := verifySignature(AlgEdDSA, ck.pub, payload, sig); err == nil {
+		t.Fatalf("signature must not verify over the raw payload")
+	}
+	if err := verifySignature(AlgEdDSA, ck.pub, DomainInput("webhook-v1", payload), sig); err != nil {
+		t.Fatalf("signature must verify over DomainInput, got %v", err)
+	}
+
+	for name, opts := range map[string][]Option{
+		"no domain":    nil,
+		"other domain": {WithSigningDomain(PurposeWebhook, "access-token-v1")},
+	} {
+		other, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, opts...)
+		if err := other.Verify(kid, payload, sig); err == nil {
+			t.Fatalf("%s: signature must not verify in another domain", name)
+		}
+	}
+}
+
+func TestSigningDomain_LeavesJWSPlain(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithSigningDomain(PurposeDefault, "raw-v1"))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	list, err := km.ExportRevocationList(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("ExportRevocationList error: %v", err)
+	}
+	header, payload, sig, _ := splitJWT(list)
+	rawSig, _ := base64.RawURLEncoding.DecodeString(sig)
+	ck := km.activeKey(AlgEdDSA)
+	if err := verifySignature(AlgEdDSA, ck.pub, []byte(header+"."+payload), rawSig); err != nil {
+		t.Fatalf("revocation list must verify over the plain JWS input, got %v", err)
+	}
+	if err := km.ApplyRevocationList(list); err != nil {
+		t.Fatalf("ApplyRevocationList error: %v", err)
+	}
+
+	signer, _ := km.ActiveSigner(AlgEdDSA)
+	input := b64([]byte(`{"alg":"EdDSA","kid":"`+ck.key.KID+`"}`)) + "." + b64([]byte(`{"sub":"alice"}`))
+	tokenSig, _ := signer.Sign(nil, []byte(input), crypto.Hash(0))
+	token := input + "." + b64(tokenSig)
+
+	if _, err := km.VerifyJWT(token); err != nil {
+		t.Fatalf("VerifyJWT must accept a plain JWS, got %v", err)
+	}
+
+	fresh, err := km.ReSign(token, ReSignOptions{})
+	if err != nil {
+		t.Fatalf("ReSign error: %v", err)
+	}
+	header, payload, sig, _ = splitJWT(fresh)
+	rawSig, _ = base64.RawURLEncoding.DecodeString(sig)
+	if err := verifySignature(AlgEdDSA, ck.pub, []byte(header+"."+payload), rawSig); err != nil {
+		t.Fatalf("re-signed token must verify over the plain JWS input, got %v", err)
+	}
+}
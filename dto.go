@@ -1,5 +1,7 @@
 package keys_manager
 
+import "time"
+
 type JWK struct {
 	Kty string `json:"kty"`
 	Kid string `json:"kid"`
@@ -12,8 +14,45 @@ type JWK struct {
 	Crv string `json:"crv,omitempty"`
 	X   string `json:"x,omitempty"`
 	Y   string `json:"y,omitempty"`
+
+	// Attestation is a non-standard member, set with WithJWKSAttestation.
+	Attestation *Attestation `json:"attestation,omitempty"`
 }
 
 type JWKS struct {
 	Keys []JWK `json:"keys"`
 }
+
+type KeyState string
+
+const (
+	KeyStateActive   KeyState = "active"
+	KeyStateInactive KeyState = "inactive"
+	KeyStateRevoked  KeyState = "revoked"
+	KeyStatePending  KeyState = "pending"
+	KeyStateStaged   KeyState = "staged"
+)
+
+type KeyInfo struct {
+	KID         string            `json:"kid"`
+	Alg         Alg               `json:"alg"`
+	Purpose     Purpose           `json:"purpose,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	State       KeyState          `json:"state"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	VerifyUntil *time.Time        `json:"verify_until,omitempty"` // see WithVerifyGrace
+	SignCount   uint64            `json:"sign_count"`
+	VerifyCount uint64            `json:"verify_count"`
+
+	LastSignedAt   *time.Time `json:"last_signed_at,omitempty"`
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+
+	Attestation *Attestation `json:"attestation,omitempty"`
+}
+
+type QuarantinedKey struct {
+	KID string
+	Alg Alg
+	Err error
+}
@@ -0,0 +1,161 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// DuplicateActive reports a slot holding several active keys, usually left
+// behind by concurrent rotations. Winner is the key used for signing; KIDs
+// lists every active key of the slot, the winner first.
+type DuplicateActive struct {
+	Alg     Alg               `json:"alg"`
+	Purpose Purpose           `json:"purpose,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Winner  string            `json:"winner"`
+	KIDs    []string          `json:"kids"`
+}
+
+// ActivePolicy decides which of several active keys of one slot signs. It
+// reports whether a is preferred over b; keys neither preferred over the
+// other are ordered by KID.
+type ActivePolicy func(a, b *Key) bool
+
+// PreferNewest prefers the most recently created key. It is the default.
+func PreferNewest(a, b *Key) bool {
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// PreferLatestExpiry prefers the key that expires last; keys without an
+// expiry outrank any key with one.
+func PreferLatestExpiry(a, b *Key) bool {
+	switch {
+	case a.ExpiresAt == nil:
+		return b.ExpiresAt != nil
+	case b.ExpiresAt == nil:
+		return false
+	}
+	return a.ExpiresAt.After(*b.ExpiresAt)
+}
+
+// PreferPriority prefers the key with the higher Priority, falling back to
+// PreferNewest between equal priorities.
+func PreferPriority(a, b *Key) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return PreferNewest(a, b)
+}
+
+// preferActive reports whether a should sign instead of b when both are
+// active in the same slot.
+func (km *KeyManager) preferActive(a, b *Key) bool {
+	prefer := km.activePolicy
+	if prefer == nil {
+		prefer = PreferNewest
+	}
+
+	switch {
+	case prefer(a, b):
+		return true
+	case prefer(b, a):
+		return false
+	}
+	return a.KID > b.KID
+}
+
+// rankActive sorts the active keys of one slot, the winner first.
+func (km *KeyManager) rankActive(keys []*Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		return km.preferActive(keys[i], keys[j])
+	})
+}
+
+func (km *KeyManager) duplicateActive(bySlot map[keySlot][]*Key) []DuplicateActive {
+	var out []DuplicateActive
+	for _, keys := range bySlot {
+		if len(keys) < 2 || weightedSplit(keys) {
+			continue
+		}
+		km.rankActive(keys)
+
+		d := DuplicateActive{
+			Alg:     keys[0].Alg,
+			Purpose: keys[0].Purpose,
+			Labels:  keys[0].Labels,
+			Winner:  keys[0].KID,
+		}
+		for _, k := range keys {
+			d.KIDs = append(d.KIDs, k.KID)
+		}
+		out = append(out, d)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Winner < out[j].Winner
+	})
+
+	return out
+}
+
+// DuplicateActiveKeys returns the slots that had more than one active key
+// at the last reload.
+func (km *KeyManager) DuplicateActiveKeys() []DuplicateActive {
+	dups := km.snapshot().duplicates
+
+	out := make([]DuplicateActive, len(dups))
+	copy(out, dups)
+
+	return out
+}
+
+// RepairDuplicateActive retires every active key that lost to another
+// active key of the same slot, keeping the key the cache signs with. It
+// reads the store rather than the cache and returns the retired KIDs. The
+// store must implement KeySaver.
+func (km *KeyManager) RepairDuplicateActive() ([]string, error) {
+	saver, err := km.saver()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bySlot := make(map[keySlot][]*Key)
+	for _, k := range keys {
+		if k.IsActive && k.RevokedAt == nil {
+			bySlot[slotOf(k)] = append(bySlot[slotOf(k)], k)
+		}
+	}
+
+	var retired []string
+	for _, d := range km.duplicateActive(bySlot) {
+		for _, k := range bySlot[keySlot{alg: d.Alg, purpose: d.Purpose, labels: formatLabels(d.Labels)}][1:] {
+			loser := *k
+			loser.Labels = maps.Clone(k.Labels)
+			loser.IsActive = false
+			loser.UpdatedAt = km.clock.Now()
+
+			if err := saver.Save(&loser); err != nil {
+				return retired, fmt.Errorf("retire key %s: %w", k.KID, err)
+			}
+			retired = append(retired, k.KID)
+
+			km.logger().Warn("duplicate active key retired", "kid", k.KID, "alg", k.Alg, "winner", d.Winner)
+		}
+	}
+
+	if len(retired) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(retired)
+	return retired, km.ReloadCache()
+}
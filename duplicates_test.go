@@ -0,0 +1,55 @@
+package keys_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuplicateActive_DetectedAndRepaired(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+	now := time.Now()
+
+	for i, kid := range []string{"older", "newest", "middle"} {
+		priv, _ := generatePrivateKey(AlgEdDSA)
+		k := makeTestKey(kid, AlgEdDSA, true, nil, enc, priv)
+		k.CreatedAt = now.Add(time.Duration([]int{0, 2, 1}[i]) * time.Minute)
+		store.data[kid] = k
+	}
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if ck := km.activeKey(AlgEdDSA); ck.key.KID != "newest" {
+		t.Fatalf("newest active key must win, got %s", ck.key.KID)
+	}
+
+	dups := km.Health().DuplicateActive
+	if len(dups) != 1 || dups[0].Winner != "newest" || len(dups[0].KIDs) != 3 || dups[0].KIDs[2] != "older" {
+		t.Fatalf("unexpected duplicates %+v", dups)
+	}
+	if len(km.Debug().DuplicateActive) != 1 {
+		t.Fatalf("duplicates must be in the debug view")
+	}
+
+	retired, err := km.RepairDuplicateActive()
+	if err != nil {
+		t.Fatalf("RepairDuplicateActive error: %v", err)
+	}
+	if len(retired) != 2 || retired[0] != "middle" || retired[1] != "older" {
+		t.Fatalf("unexpected retired keys %v", retired)
+	}
+
+	if len(km.DuplicateActiveKeys()) != 0 {
+		t.Fatalf("duplicates must be gone after repair")
+	}
+	if ck := km.activeKey(AlgEdDSA); ck.key.KID != "newest" {
+		t.Fatalf("repair must keep the winner active, got %s", ck.key.KID)
+	}
+
+	if retired, err := km.RepairDuplicateActive(); err != nil || retired != nil {
+		t.Fatalf("repair without duplicates must be a no-op, got %v %v", retired, err)
+	}
+}
@@ -0,0 +1,40 @@
+// Package echokm verifies bearer JWTs in Echo handlers with a KeyManager.
+package echokm
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/keylet-auth/keys-manager/httpkm"
+)
+
+// ClaimsKey is the echo.Context key holding the verified claims.
+const ClaimsKey = "keys_manager.claims"
+
+// Middleware rejects requests without a valid bearer token with a 401
+// echo.HTTPError. Verified claims are stored under ClaimsKey and in the
+// request context, where httpkm.ClaimsFromContext finds them.
+func Middleware(v httpkm.Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+
+			claims, err := httpkm.Authenticate(v, r)
+			if err != nil {
+				c.Response().Header().Set("WWW-Authenticate", httpkm.Challenge(err))
+				return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+			}
+
+			c.Set(ClaimsKey, claims)
+			c.SetRequest(r.WithContext(httpkm.ContextWithClaims(r.Context(), claims)))
+			return next(c)
+		}
+	}
+}
+
+// Claims returns the claims stored by Middleware.
+func Claims(c echo.Context) (map[string]any, bool) {
+	claims, ok := c.Get(ClaimsKey).(map[string]any)
+	return claims, ok
+}
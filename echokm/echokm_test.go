@@ -0,0 +1,55 @@
+package echokm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/httpkm"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+func TestMiddleware(t *testing.T) {
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store())
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	var input string
+	sig, _ := m.Sign(km.AlgEdDSA, func(kid string) ([]byte, error) {
+		input = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"alg":"EdDSA","kid":%q}`, kid))) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`))
+		return []byte(input), nil
+	})
+	token := input + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	e := echo.New()
+	e.Use(Middleware(m))
+	e.GET("/me", func(c echo.Context) error {
+		claims, _ := Claims(c)
+		fromCtx, _ := httpkm.ClaimsFromContext(c.Request().Context())
+		return c.String(http.StatusOK, fmt.Sprint(claims["sub"], fromCtx["sub"]))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "alicealice" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/me", nil))
+
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("expected 401 challenge, got %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+}
@@ -0,0 +1,28 @@
+package keys_manager
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// entropyReader marks read failures of a custom randomness source with
+// ErrEntropy.
+type entropyReader struct {
+	r io.Reader
+}
+
+func (e entropyReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil {
+		return n, fmt.Errorf("%w: %w", ErrEntropy, err)
+	}
+	return n, nil
+}
+
+func (km *KeyManager) entropy() io.Reader {
+	if km.rand == nil {
+		return rand.Reader
+	}
+	return entropyReader{r: km.rand}
+}
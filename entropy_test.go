@@ -0,0 +1,47 @@
+package keys_manager
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("drbg unavailable")
+}
+
+func TestWithRand_DeterministicKeys(t *testing.T) {
+	publicKey := func(alg Alg) []byte {
+		store := NewMockStore()
+		km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithRand(rand.NewChaCha8([32]byte{1})))
+		if err != nil {
+			t.Fatalf("NewKeyManager error: %v", err)
+		}
+		if err := km.InitKeys([]Alg{alg}); err != nil {
+			t.Fatalf("InitKeys error: %v", err)
+		}
+		return km.activeKey(alg).key.PublicKey
+	}
+
+	for _, alg := range []Alg{AlgEdDSA, AlgES256} {
+		if a, b := publicKey(alg), publicKey(alg); !bytes.Equal(a, b) {
+			t.Fatalf("%s: the same seed must produce the same key", alg)
+		}
+	}
+}
+
+func TestWithRand_FailuresAreTyped(t *testing.T) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithRand(failingReader{}))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	for _, s := range []keySlot{{alg: AlgEdDSA}, {alg: AlgES256}, cookieSlot} {
+		if err := km.RotateWithPurpose(s.alg, s.purpose); !errors.Is(err, ErrEntropy) {
+			t.Fatalf("%s: expected ErrEntropy, got %v", s, err)
+		}
+	}
+}
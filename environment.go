@@ -0,0 +1,15 @@
+package keys_manager
+
+import "fmt"
+
+func (km *KeyManager) checkEnvironment(k *Key) error {
+	if km.environment == "" || k.Environment == "" || k.Environment == km.environment {
+		return nil
+	}
+
+	return &KeyError{
+		KID: k.KID,
+		Alg: k.Alg,
+		Err: fmt.Errorf("%w: tagged %q, manager runs in %q", ErrWrongEnvironment, k.Environment, km.environment),
+	}
+}
@@ -0,0 +1,90 @@
+package keys_manager
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEnvironment_ForeignKeyFailsLoad(t *testing.T) {
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	k := makeTestKey("staging-key", AlgEdDSA, false, nil, MockEncryptor{}, priv)
+	k.Environment = "staging"
+	store.data[k.KID] = k
+
+	_, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithEnvironment("prod"), WithLenientReload())
+	if !errors.Is(err, ErrWrongEnvironment) {
+		t.Fatalf("expected ErrWrongEnvironment even when lenient, got %v", err)
+	}
+
+	var ke *KeyError
+	if !errors.As(err, &ke) || ke.KID != "staging-key" {
+		t.Fatalf("error must name the key, got %v", err)
+	}
+
+	if _, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithEnvironment("staging")); err != nil {
+		t.Fatalf("matching environment must load, got %v", err)
+	}
+}
+
+func TestEnvironment_NewKeysTagged(t *testing.T) {
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.data["legacy"] = makeTestKey("legacy", AlgEdDSA, false, nil, MockEncryptor{}, priv)
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithEnvironment("prod"))
+	if err != nil {
+		t.Fatalf("untagged keys must load, got %v", err)
+	}
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	priv, _ = generatePrivateKey(AlgES256)
+	kid, err := km.ImportKey(AlgES256, priv, false)
+	if err != nil {
+		t.Fatalf("ImportKey error: %v", err)
+	}
+
+	rotated := km.activeKey(AlgEdDSA).key
+	if rotated.Environment != "prod" || store.data[kid].Environment != "prod" {
+		t.Fatalf("generated and imported keys must be tagged, got %q and %q", rotated.Environment, store.data[kid].Environment)
+	}
+}
+
+func TestEnvironment_ImportAndRestoreRejectForeignKeys(t *testing.T) {
+	staging, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithEnvironment("staging"))
+	_ = staging.InitKeys([]Alg{AlgEdDSA})
+
+	bundle, err := staging.Export()
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	var archive bytes.Buffer
+	if err := staging.Backup(&archive, "pass"); err != nil {
+		t.Fatalf("Backup error: %v", err)
+	}
+
+	restores := map[string]func(*KeyManager) error{
+		"import":  func(km *KeyManager) error { return km.Import(bundle) },
+		"restore": func(km *KeyManager) error { return km.Restore(bytes.NewReader(archive.Bytes()), "pass") },
+	}
+	for name, restore := range restores {
+		store := NewMockStore()
+		prod, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithEnvironment("prod"))
+
+		if err := restore(prod); !errors.Is(err, ErrWrongEnvironment) {
+			t.Fatalf("%s: expected ErrWrongEnvironment, got %v", name, err)
+		}
+		if len(store.data) != 0 {
+			t.Fatalf("%s: foreign keys must not reach the store", name)
+		}
+		if err := prod.ReloadCache(); err != nil {
+			t.Fatalf("%s: the store must still load, got %v", name, err)
+		}
+	}
+}
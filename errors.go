@@ -0,0 +1,131 @@
+package keys_manager
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrNoActiveKey      = errors.New("no active key")
+	ErrKeyExpired       = errors.New("key expired")
+	ErrKeyNotYetValid   = errors.New("key not yet valid")
+	ErrKeyRevoked       = errors.New("key revoked")
+	ErrUnsupportedAlg   = errors.New("unsupported algorithm")
+	ErrDecryptFailed    = errors.New("decrypt failed")
+	ErrVerifyOnly       = errors.New("manager is in verify-only mode")
+	ErrStoreUnsupported = errors.New("operation not supported by store")
+	ErrWrongEnvironment = errors.New("key belongs to another environment")
+	ErrEntropy          = errors.New("entropy source failed")
+	ErrKIDCollision     = errors.New("generated kid already exists")
+	ErrNotPending       = errors.New("key is not pending approval")
+	ErrInvalidCeremony  = errors.New("invalid key ceremony")
+	ErrKeyMismatch      = errors.New("public key fingerprint mismatch")
+	ErrKeyInUse         = errors.New("key is active or still in grace")
+	ErrKeyEncoding      = errors.New("unsupported key encoding")
+	ErrPlaintextKey     = errors.New("key material is stored unencrypted")
+	ErrClosed           = errors.New("key manager is closed")
+	ErrRateLimited      = errors.New("signing rate limited")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrTokenReplayed    = errors.New("token replayed")
+	ErrInvalidCookie    = errors.New("invalid cookie")
+	ErrInvalidWebhook   = errors.New("invalid webhook signature")
+	ErrNoCertificate    = errors.New("no certificate stored for key")
+	ErrForbidden        = errors.New("access denied")
+	ErrBackupPassphrase = errors.New("wrong backup passphrase or corrupted backup")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// KeyError reports a failure tied to a specific stored key.
+type KeyError struct {
+	KID string
+	Alg Alg
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("key %s: %v", e.KID, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// SignatureLengthError reports a raw ECDSA signature whose length does not
+// match the curve of the key, which must be rejected before the halves are
+// parsed as integers.
+type SignatureLengthError struct {
+	Alg  Alg
+	Want int
+	Got  int
+}
+
+func (e *SignatureLengthError) Error() string {
+	return fmt.Sprintf("verify: %s signature must be %d bytes, got %d", e.Alg, e.Want, e.Got)
+}
+
+// InitKeysError lists the algs InitKeys could not create a key for.
+type InitKeysError struct {
+	Purpose Purpose
+	Failed  map[Alg]error
+}
+
+func (e *InitKeysError) Error() string {
+	algs := slices.Sorted(maps.Keys(e.Failed))
+
+	parts := make([]string, len(algs))
+	for i, alg := range algs {
+		parts[i] = fmt.Sprintf("%s: %v", keySlot{alg: alg, purpose: e.Purpose}, e.Failed[alg])
+	}
+	return "failed to initialize keys: " + strings.Join(parts, "; ")
+}
+
+func (e *InitKeysError) Unwrap() []error {
+	out := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		out = append(out, err)
+	}
+	return out
+}
+
+// Operation names work the manager does on its own, outside of a call
+// that could return the error to the caller.
+type Operation string
+
+const (
+	OpBackgroundRefresh Operation = "background_refresh"
+	OpWatchReload       Operation = "watch_reload"
+	OpImplicitReload    Operation = "implicit_reload"
+	OpSignalReload      Operation = "signal_reload"
+	OpScheduledRotation Operation = "scheduled_rotation"
+	OpFetchKey          Operation = "fetch_key"
+	OpKeyPool           Operation = "key_pool"
+	OpEventPublish      Operation = "event_publish"
+	OpUsageFlush        Operation = "usage_flush"
+	OpJWKSExport        Operation = "jwks_export"
+	OpActivation        Operation = "activation"
+)
+
+// OperationError reports a failed background operation to the handler
+// set with WithOnError. KID is set when the failure concerns one key.
+type OperationError struct {
+	Op  Operation
+	KID string
+	Err error
+}
+
+func (e *OperationError) Error() string {
+	if e.KID != "" {
+		return fmt.Sprintf("%s: key %s: %v", e.Op, e.KID, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
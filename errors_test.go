@@ -0,0 +1,68 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrors_KeyNotFound(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if err := km.Verify("missing", []byte("x"), []byte("sig")); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Verify: expected ErrKeyNotFound, got %v", err)
+	}
+
+	if _, err := km.Signer("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Signer: expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestErrors_NoActiveKey(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	_, err := km.Sign(AlgRS256, func(_ string) ([]byte, error) {
+		return []byte("data"), nil
+	})
+	if !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey, got %v", err)
+	}
+}
+
+func TestErrors_UnsupportedAlg(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if err := km.Rotate(Alg("HS256")); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Fatalf("Rotate: expected ErrUnsupportedAlg, got %v", err)
+	}
+
+	if _, err := signingOptions(Alg("HS256")); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Fatalf("signingOptions: expected ErrUnsupportedAlg, got %v", err)
+	}
+
+	if err := verifySignature(Alg("HS256"), nil, nil, nil); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Fatalf("verifySignature: expected ErrUnsupportedAlg, got %v", err)
+	}
+}
+
+func TestErrors_DecryptFailed(t *testing.T) {
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgRS256)
+	exp := time.Now().Add(time.Hour)
+	store.Save(makeTestKey("broken", AlgRS256, true, &exp, MockEncryptor{}, priv))
+
+	_, err := NewKeyManager(store, MockEncryptor{ForceDecryptError: true}, mockPolicy)
+	if !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("expected ErrDecryptFailed, got %v", err)
+	}
+
+	var keyErr *KeyError
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("expected *KeyError, got %T", err)
+	}
+
+	if keyErr.KID != "broken" || keyErr.Alg != AlgRS256 {
+		t.Fatalf("unexpected KeyError fields: %+v", keyErr)
+	}
+}
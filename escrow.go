@@ -0,0 +1,203 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	EscrowRSAOAEP = "RSA-OAEP-256"
+	EscrowX25519  = "ECDH-ES+X25519"
+)
+
+const escrowInfo = "keys-manager escrow"
+
+// escrowEnvelope is the JSON stored in Key.Escrow. The key material is
+// sealed with AES-256-GCM under a fresh key that is either RSA-OAEP
+// wrapped or derived from an ephemeral X25519 exchange; the KID is the
+// additional data.
+type escrowEnvelope struct {
+	Alg        string `json:"alg"`
+	KID        string `json:"kid"`
+	Recipient  string `json:"recipient"`
+	WrappedKey []byte `json:"wrapped_key,omitempty"`
+	EPK        []byte `json:"epk,omitempty"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func checkEscrowKey(pub crypto.PublicKey) error {
+	switch pub := pub.(type) {
+	case nil:
+		return nil
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return fmt.Errorf("escrow: rsa recovery key must have at least %d bits", minRSABits)
+		}
+		return nil
+	case *ecdh.PublicKey:
+		if pub.Curve() != ecdh.X25519() {
+			return errors.New("escrow: ecdh recovery key must be X25519")
+		}
+		return nil
+	}
+	return fmt.Errorf("escrow: unsupported recovery key %T", pub)
+}
+
+// sealEscrow returns material wrapped for the escrow recipient, or nil
+// without WithEscrow.
+func (km *KeyManager) sealEscrow(kid string, material []byte) ([]byte, error) {
+	if km.escrowKey == nil {
+		return nil, nil
+	}
+
+	r := km.entropy()
+
+	recipient, err := escrowRecipient(km.escrowKey)
+	if err != nil {
+		return nil, err
+	}
+	env := escrowEnvelope{KID: kid, Recipient: recipient}
+
+	var cek []byte
+	switch pub := km.escrowKey.(type) {
+	case *rsa.PublicKey:
+		env.Alg = EscrowRSAOAEP
+		cek = make([]byte, 32)
+		if _, err := io.ReadFull(r, cek); err != nil {
+			return nil, err
+		}
+		if env.WrappedKey, err = rsa.EncryptOAEP(sha256.New(), r, pub, cek, []byte(kid)); err != nil {
+			return nil, fmt.Errorf("escrow: wrap: %w", err)
+		}
+
+	case *ecdh.PublicKey:
+		env.Alg = EscrowX25519
+		eph, err := ecdh.X25519().GenerateKey(r)
+		if err != nil {
+			return nil, err
+		}
+		env.EPK = eph.PublicKey().Bytes()
+		if cek, err = escrowX25519Key(eph, pub, env.EPK); err != nil {
+			return nil, err
+		}
+	}
+	defer wipeBytes(cek)
+
+	aead, err := escrowAEAD(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(r, env.Nonce); err != nil {
+		return nil, err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, material, []byte(kid))
+
+	return json.Marshal(env)
+}
+
+// OpenEscrow recovers the key material sealed in Key.Escrow with the
+// recovery private key, an *rsa.PrivateKey or X25519 *ecdh.PrivateKey. It
+// returns PKCS#8 DER for signing keys and the raw secret for symmetric
+// keys, along with the KID the material was sealed for.
+func OpenEscrow(escrow []byte, recovery crypto.PrivateKey) (kid string, material []byte, err error) {
+	var env escrowEnvelope
+	if err := json.Unmarshal(escrow, &env); err != nil {
+		return "", nil, fmt.Errorf("escrow: %w", err)
+	}
+
+	signer, ok := recovery.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return "", nil, fmt.Errorf("escrow: unsupported recovery key %T", recovery)
+	}
+	recipient, err := escrowRecipient(signer.Public())
+	if err != nil {
+		return "", nil, err
+	}
+	if recipient != env.Recipient {
+		return "", nil, errors.New("escrow: sealed for another recovery key")
+	}
+
+	var cek []byte
+	switch priv := recovery.(type) {
+	case *rsa.PrivateKey:
+		if env.Alg != EscrowRSAOAEP {
+			return "", nil, fmt.Errorf("escrow: %s envelope needs another key type", env.Alg)
+		}
+		if cek, err = rsa.DecryptOAEP(sha256.New(), nil, priv, env.WrappedKey, []byte(env.KID)); err != nil {
+			return "", nil, fmt.Errorf("%w: escrow: %w", ErrDecryptFailed, err)
+		}
+
+	case *ecdh.PrivateKey:
+		if env.Alg != EscrowX25519 {
+			return "", nil, fmt.Errorf("escrow: %s envelope needs another key type", env.Alg)
+		}
+		epk, err := ecdh.X25519().NewPublicKey(env.EPK)
+		if err != nil {
+			return "", nil, fmt.Errorf("escrow: epk: %w", err)
+		}
+		if cek, err = escrowX25519Key(priv, epk, env.EPK); err != nil {
+			return "", nil, err
+		}
+	}
+	defer wipeBytes(cek)
+
+	aead, err := escrowAEAD(cek)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return "", nil, errors.New("escrow: bad nonce")
+	}
+
+	material, err = aead.Open(nil, env.Nonce, env.Ciphertext, []byte(env.KID))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: escrow: %w", ErrDecryptFailed, err)
+	}
+
+	return env.KID, material, nil
+}
+
+// escrowX25519Key derives the content key from the exchange between priv
+// and pub, salted with the ephemeral public key.
+func escrowX25519Key(priv *ecdh.PrivateKey, pub *ecdh.PublicKey, epk []byte) ([]byte, error) {
+	shared, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: ecdh: %w", err)
+	}
+	defer wipeBytes(shared)
+
+	return hkdf.Key(sha256.New, shared, epk, escrowInfo, 32)
+}
+
+func escrowAEAD(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// escrowRecipient identifies a recovery key by the SHA-256 of its PKIX
+// encoding.
+func escrowRecipient(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("escrow: recovery key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
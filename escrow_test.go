@@ -0,0 +1,78 @@
+package keys_manager
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestEscrow_RecoverRotatedKey(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	x25519Key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	otherKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	cases := map[string]struct {
+		pub  crypto.PublicKey
+		priv crypto.PrivateKey
+	}{
+		"rsa":    {&rsaKey.PublicKey, rsaKey},
+		"x25519": {x25519Key.PublicKey(), x25519Key},
+	}
+
+	for name, tc := range cases {
+		store := NewMockStore()
+		km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithEscrow(tc.pub))
+		if err != nil {
+			t.Fatalf("%s: NewKeyManager error: %v", name, err)
+		}
+		if err := km.Rotate(AlgES256); err != nil {
+			t.Fatalf("%s: Rotate error: %v", name, err)
+		}
+
+		k := km.activeKey(AlgES256).key
+		if len(store.data[k.KID].Escrow) == 0 {
+			t.Fatalf("%s: escrow must be stored with the key", name)
+		}
+
+		kid, der, err := OpenEscrow(k.Escrow, tc.priv)
+		if err != nil {
+			t.Fatalf("%s: OpenEscrow error: %v", name, err)
+		}
+		if kid != k.KID {
+			t.Fatalf("%s: expected kid %s, got %s", name, k.KID, kid)
+		}
+
+		priv, err := parsePrivateKey(der)
+		if err != nil {
+			t.Fatalf("%s: recovered key does not parse: %v", name, err)
+		}
+		pub, _ := marshalPublicKey(priv.Public())
+		if !bytes.Equal(pub, k.PublicKey) {
+			t.Fatalf("%s: recovered key does not match", name)
+		}
+
+		if _, _, err := OpenEscrow(k.Escrow, otherKey); err == nil {
+			t.Fatalf("%s: another recovery key must not open the escrow", name)
+		}
+	}
+}
+
+func TestEscrow_RejectsUnsupportedRecoveryKey(t *testing.T) {
+	p256Key, _ := ecdh.P256().GenerateKey(rand.Reader)
+
+	if _, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithEscrow(p256Key.PublicKey())); err == nil {
+		t.Fatalf("non-X25519 ecdh recovery key must be rejected")
+	}
+}
+
+func TestEscrow_DisabledByDefault(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.Rotate(AlgEdDSA)
+
+	if k := km.activeKey(AlgEdDSA).key; k.Escrow != nil {
+		t.Fatalf("keys must not be escrowed without WithEscrow")
+	}
+}
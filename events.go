@@ -0,0 +1,49 @@
+package keys_manager
+
+import (
+	"context"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventKeyRotated  EventType = "key.rotated"
+	EventKeyRevoked  EventType = "key.revoked"
+	EventKeyImported EventType = "key.imported"
+	EventKeyPending  EventType = "key.pending"
+	EventKeyDeleted  EventType = "key.deleted"
+	EventKeyStaged   EventType = "key.staged"
+)
+
+// LifecycleEvent describes a change this manager made to the store. It
+// carries identifiers only, never key material.
+type LifecycleEvent struct {
+	Type        EventType `json:"type"`
+	KID         string    `json:"kid"`
+	Alg         Alg       `json:"alg"`
+	Purpose     Purpose   `json:"purpose,omitempty"`
+	PreviousKID string    `json:"previous_kid,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// EventPublisher delivers lifecycle events to other replicas and
+// downstream services, typically over a message bus.
+type EventPublisher interface {
+	Publish(ctx context.Context, ev LifecycleEvent) error
+}
+
+// publish is called after the store write succeeded. A failed publish is
+// logged and does not fail the operation: the store is the source of truth
+// and peers converge on their next reload.
+func (km *KeyManager) publish(ctx context.Context, ev LifecycleEvent) {
+	if km.events == nil {
+		return
+	}
+
+	ev.At = km.clock.Now()
+	if err := km.events.Publish(ctx, ev); err != nil {
+		km.logger().WarnContext(ctx, "key lifecycle event not published", "type", ev.Type, "kid", ev.KID, "err", err)
+		km.reportError(OpEventPublish, ev.KID, err)
+	}
+}
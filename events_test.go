@@ -0,0 +1,68 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []LifecycleEvent
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, ev LifecycleEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, ev)
+	return p.err
+}
+
+func TestEventPublisher_LifecycleEvents(t *testing.T) {
+	pub := &recordingPublisher{}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithEventPublisher(pub))
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	first := km.activeKey(AlgEdDSA).key.KID
+
+	_ = km.Rotate(AlgEdDSA)
+	second := km.activeKey(AlgEdDSA).key.KID
+
+	_ = km.Revoke(first)
+
+	priv, _ := generatePrivateKey(AlgES256)
+	imported, _ := km.ImportKey(AlgES256, priv, false)
+
+	want := []LifecycleEvent{
+		{Type: EventKeyRotated, KID: first, Alg: AlgEdDSA},
+		{Type: EventKeyRotated, KID: second, Alg: AlgEdDSA, PreviousKID: first},
+		{Type: EventKeyRevoked, KID: first, Alg: AlgEdDSA},
+		{Type: EventKeyImported, KID: imported, Alg: AlgES256},
+	}
+	if len(pub.events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), pub.events)
+	}
+	for i, ev := range pub.events {
+		if ev.At.IsZero() {
+			t.Fatalf("event %d has no timestamp", i)
+		}
+		ev.At = want[i].At
+		if ev != want[i] {
+			t.Fatalf("event %d: expected %+v, got %+v", i, want[i], ev)
+		}
+	}
+}
+
+func TestEventPublisher_FailureDoesNotFailRotation(t *testing.T) {
+	pub := &recordingPublisher{err: errors.New("bus down")}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithEventPublisher(pub))
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("publish failure must not fail rotation, got %v", err)
+	}
+	if km.activeKey(AlgEdDSA) == nil {
+		t.Fatalf("rotated key must be active")
+	}
+}
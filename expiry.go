@@ -0,0 +1,87 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExpiredKeyPolicy decides what signing does with an active key whose
+// ExpiresAt has passed because rotation has not run yet.
+type ExpiredKeyPolicy int
+
+const (
+	// ExpiredKeyReject fails with ErrKeyExpired. It is the default.
+	ExpiredKeyReject ExpiredKeyPolicy = iota
+
+	// ExpiredKeyRotate rotates the key's slot inline and signs with the
+	// new key. Signing by KID still fails, as another key would not help.
+	ExpiredKeyRotate
+)
+
+func (km *KeyManager) rotatesOnSign() bool {
+	return km.rotateOnSign || km.expiredPolicy == ExpiredKeyRotate
+}
+
+func (km *KeyManager) checkExpiry(k *Key) error {
+	if km.expired(k, km.clock.Now()) {
+		return fmt.Errorf("%w: %s", ErrKeyExpired, k.KID)
+	}
+	return nil
+}
+
+// verifyUntil returns the time after which k no longer verifies under
+// WithVerifyGrace, or nil when it verifies without limit.
+func (km *KeyManager) verifyUntil(k *Key) *time.Time {
+	if km.verifyGrace <= 0 || k.ExpiresAt == nil {
+		return nil
+	}
+	t := k.ExpiresAt.Add(km.verifyGrace)
+	return &t
+}
+
+func (km *KeyManager) checkVerifyUntil(k *Key, now time.Time) error {
+	until := km.verifyUntil(k)
+	if until != nil && until.Before(now.Add(-km.skew)) {
+		return fmt.Errorf("%w: %s verifies only until %s", ErrKeyExpired, k.KID, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// rotateForSign gives s a usable active key when it has none or only an
+// expired one, under ExpiredKeyRotate or WithRotateOnSign. Concurrent
+// signers wait for a single rotation and then share its key. A key that is
+// not valid yet is left alone.
+func (km *KeyManager) rotateForSign(ctx context.Context, s keySlot) (*CachedKey, error) {
+	km.signRotate.Lock()
+	defer km.signRotate.Unlock()
+
+	var labels map[string]string
+	if cur := km.snapshot().active[s]; cur != nil {
+		now := km.clock.Now()
+		if km.notYetValid(cur.key, now) {
+			return nil, fmt.Errorf("%w for %s", ErrNoActiveKey, s)
+		}
+		if !km.expired(cur.key, now) {
+			return cur, nil
+		}
+		labels = cur.key.Labels
+		km.logger().Warn("rotating expired key on sign", "kid", cur.key.KID, "slot", s.String())
+	} else {
+		km.logger().Warn("rotating on sign, no active key", "slot", s.String())
+	}
+
+	if err := km.rotate(ctx, s.alg, s.purpose, labels); err != nil {
+		return nil, fmt.Errorf("rotate on sign for %s: %w", s, err)
+	}
+
+	ck := km.snapshot().active[s]
+	if ck == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoActiveKey, s)
+	}
+	if err := km.checkExpiry(ck.key); err != nil {
+		return nil, err
+	}
+
+	return ck, nil
+}
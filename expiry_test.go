@@ -0,0 +1,152 @@
+package keys_manager
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func expiredKeyStore(t *testing.T) *MockStore {
+	t.Helper()
+
+	store := NewMockStore()
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(-time.Minute)
+	_ = store.Save(makeTestKey("stale", AlgEdDSA, true, &exp, MockEncryptor{}, priv))
+	return store
+}
+
+func TestExpiredKey_RejectedAtSign(t *testing.T) {
+	km, _ := NewKeyManager(expiredKeyStore(t), MockEncryptor{}, mockPolicy)
+	build := func(string) ([]byte, error) { return []byte("x"), nil }
+
+	if _, err := km.Sign(AlgEdDSA, build); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("Sign: expected ErrKeyExpired, got %v", err)
+	}
+	if _, err := km.SignWithKID("stale", build); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("SignWithKID: expected ErrKeyExpired, got %v", err)
+	}
+	if _, _, err := km.SignBatch(AlgEdDSA, [][]byte{[]byte("x")}, 1); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("SignBatch: expected ErrKeyExpired, got %v", err)
+	}
+	if _, err := km.Signer("stale"); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("Signer: expected ErrKeyExpired, got %v", err)
+	}
+	if _, err := km.ActiveSigner(AlgEdDSA); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("ActiveSigner: expected ErrKeyExpired, got %v", err)
+	}
+}
+
+func TestExpiredKey_RotatedOnSign(t *testing.T) {
+	store := expiredKeyStore(t)
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithExpiredKeyPolicy(ExpiredKeyRotate))
+
+	kids := make([]string, 8)
+	var wg sync.WaitGroup
+	for i := range kids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := km.Sign(AlgEdDSA, func(kid string) ([]byte, error) {
+				kids[i] = kid
+				return []byte("x"), nil
+			})
+			if err != nil {
+				t.Errorf("Sign error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, kid := range kids {
+		if kid == "stale" || kid != kids[0] {
+			t.Fatalf("every signer must use the one rotated key, got %v", kids)
+		}
+	}
+	if store.RotateCount != 1 {
+		t.Fatalf("expected a single inline rotation, got %d", store.RotateCount)
+	}
+
+	if _, err := km.SignWithKID("stale", func(string) ([]byte, error) { return nil, nil }); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("signing by kid must still reject the expired key, got %v", err)
+	}
+}
+
+func TestRotateOnSign_CreatesMissingKey(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithRotateOnSign())
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := km.SignWithPurpose(AlgES256, PurposeAccess, func(string) ([]byte, error) {
+				return []byte("x"), nil
+			})
+			if err != nil {
+				t.Errorf("SignWithPurpose error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	keys, _ := store.List()
+	if len(keys) != 1 || keys[0].Purpose != PurposeAccess || !keys[0].IsActive {
+		t.Fatalf("expected a single active access key, got %d keys", len(keys))
+	}
+
+	plain, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if _, err := plain.Sign(AlgES256, func(string) ([]byte, error) { return nil, nil }); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("without WithRotateOnSign expected ErrNoActiveKey, got %v", err)
+	}
+}
+
+func TestRotateOnSign_ReplacesExpiredKey(t *testing.T) {
+	store := expiredKeyStore(t)
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithRotateOnSign())
+
+	_, err := km.Sign(AlgEdDSA, func(kid string) ([]byte, error) {
+		if kid == "stale" {
+			t.Fatalf("expired key must not sign")
+		}
+		return []byte("x"), nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+}
+
+func TestVerifyGrace(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock), WithVerifyGrace(time.Hour))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	var kid string
+	sig, err := km.Sign(AlgEdDSA, func(k string) ([]byte, error) {
+		kid = k
+		return []byte("payload"), nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	info := km.Keys()[0]
+	if info.VerifyUntil == nil || !info.VerifyUntil.Equal(info.ExpiresAt.Add(time.Hour)) {
+		t.Fatalf("expected VerifyUntil one hour after expiry, got %+v", info)
+	}
+
+	clock.now = info.ExpiresAt.Add(30 * time.Minute)
+	if err := km.Verify(kid, []byte("payload"), sig); err != nil {
+		t.Fatalf("expired key must verify within grace, got %v", err)
+	}
+
+	clock.now = info.ExpiresAt.Add(2 * time.Hour)
+	if err := km.Verify(kid, []byte("payload"), sig); !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("expected ErrKeyExpired past grace, got %v", err)
+	}
+	if err := km.VerifyAny(AlgEdDSA, []byte("payload"), sig); err == nil {
+		t.Fatalf("VerifyAny must skip keys past grace")
+	}
+}
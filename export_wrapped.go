@@ -0,0 +1,56 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExportWrapped returns the private key of kid wrapped under wrappingKey,
+// a 16, 24 or 32 byte AES key, with AES Key Wrap with Padding (RFC 5649),
+// as unwrapped by CKM_AES_KEY_WRAP_KWP and KMS import tools. Signing keys
+// are wrapped as PKCS#8, A256GCM keys as the raw secret. The key is
+// decrypted only in memory and is authorized for ActionExport.
+func (km *KeyManager) ExportWrapped(kid string, wrappingKey []byte) ([]byte, error) {
+	return km.ExportWrappedCtx(context.Background(), kid, wrappingKey)
+}
+
+func (km *KeyManager) ExportWrappedCtx(ctx context.Context, kid string, wrappingKey []byte) ([]byte, error) {
+	if km.verifyOnly {
+		return nil, ErrVerifyOnly
+	}
+	switch len(wrappingKey) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("export wrapped: wrapping key must be 16, 24 or 32 bytes, got %d", len(wrappingKey))
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if k.KID != kid {
+			continue
+		}
+		if err := km.authorize(ctx, ActionExport, k); err != nil {
+			return nil, err
+		}
+
+		plain, err := km.decrypt(ctx, k)
+		if err != nil {
+			return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+		}
+		defer wipeBytes(plain)
+
+		wrapped, err := keyWrapPad(wrappingKey, plain)
+		if err != nil {
+			return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+		}
+
+		km.logger().Warn("key exported wrapped", "kid", k.KID, "alg", k.Alg)
+		return wrapped, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
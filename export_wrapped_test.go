@@ -0,0 +1,58 @@
+package keys_manager
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"testing"
+)
+
+func TestExportWrapped(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgES256})
+	ck := km.activeKey(AlgES256)
+
+	kek := bytes.Repeat([]byte{3}, 32)
+	wrapped, err := km.ExportWrapped(ck.key.KID, kek)
+	if err != nil {
+		t.Fatalf("ExportWrapped error: %v", err)
+	}
+
+	der, err := keyUnwrapPad(kek, wrapped)
+	if err != nil {
+		t.Fatalf("unwrap error: %v", err)
+	}
+	priv, err := parsePrivateKey(der)
+	if err != nil {
+		t.Fatalf("unwrapped material must be PKCS#8: %v", err)
+	}
+	if !priv.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(ck.pub) {
+		t.Fatalf("unwrapped key does not match the exported key")
+	}
+
+	if _, err := keyUnwrapPad(bytes.Repeat([]byte{4}, 32), wrapped); err == nil {
+		t.Fatalf("unwrapping with another key must fail")
+	}
+	if _, err := km.ExportWrapped(ck.key.KID, []byte("short")); err == nil {
+		t.Fatalf("expected an error for an invalid wrapping key")
+	}
+	if _, err := km.ExportWrapped("missing", kek); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestExportWrapped_Authorized(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithAuthorizer(AuthorizerFunc(
+		func(_ context.Context, req AccessRequest) error {
+			if req.Action == ActionExport {
+				return errors.New("no exports")
+			}
+			return nil
+		})))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	if _, err := km.ExportWrapped(km.activeKey(AlgEdDSA).key.KID, make([]byte, 16)); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
@@ -0,0 +1,304 @@
+// Package filestore keeps keys in a single JSON file. It suits CLIs, local
+// development and single-host deployments; concurrent writers in separate
+// processes are not coordinated.
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+var (
+	_ km.Store          = (*Store)(nil)
+	_ km.KeySaver       = (*Store)(nil)
+	_ km.KeyBatchSaver  = (*Store)(nil)
+	_ km.KeyGetter      = (*Store)(nil)
+	_ km.KeyDeleter     = (*Store)(nil)
+	_ km.VersionedStore = (*Store)(nil)
+	_ km.UsageStore     = (*Store)(nil)
+	_ km.UsageReader    = (*Store)(nil)
+)
+
+// New returns a store backed by path. The file is created on first write.
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+type file struct {
+	Generation uint64                 `json:"generation"`
+	Keys       []record               `json:"keys"`
+	Usage      map[string]usageRecord `json:"usage,omitempty"`
+}
+
+type usageRecord struct {
+	Signs          uint64     `json:"signs,omitempty"`
+	Verifies       uint64     `json:"verifies,omitempty"`
+	LastSignedAt   *time.Time `json:"last_signed_at,omitempty"`
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+}
+
+// record stores a key in its canonical encoding, encrypted material
+// included, unlike the log-safe Key JSON.
+type record struct {
+	*km.Key
+}
+
+func toRecord(k *km.Key) record {
+	c := *k
+	return record{&c}
+}
+
+func (r record) key() *km.Key {
+	c := *r.Key
+	return &c
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	return r.Key.MarshalBinary()
+}
+
+func (r *record) UnmarshalJSON(data []byte) error {
+	r.Key = new(km.Key)
+	return r.Key.UnmarshalBinary(data)
+}
+
+func (s *Store) read() (*file, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &file{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	return &f, nil
+}
+
+// write replaces the file atomically.
+func (s *Store) write(f *file) error {
+	sort.Slice(f.Keys, func(i, j int) bool { return f.Keys[i].KID < f.Keys[j].KID })
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *Store) update(fn func(f *file)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	fn(f)
+	f.Generation++
+	return s.write(f)
+}
+
+func (s *Store) List() ([]*km.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*km.Key, len(f.Keys))
+	for i, r := range f.Keys {
+		out[i] = r.key()
+	}
+	return out, nil
+}
+
+func (s *Store) Get(_ context.Context, kid string) (*km.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range f.Keys {
+		if r.KID == kid {
+			return r.key(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", km.ErrKeyNotFound, kid)
+}
+
+func (s *Store) Version() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(f.Generation, 10), nil
+}
+
+func (s *Store) Rotate(newKey, oldKey *km.Key) error {
+	return s.update(func(f *file) {
+		if oldKey != nil {
+			put(f, toRecord(oldKey))
+		}
+		save(f, toRecord(newKey))
+	})
+}
+
+func (s *Store) Save(key *km.Key) error {
+	return s.update(func(f *file) {
+		save(f, toRecord(key))
+	})
+}
+
+// SaveAll writes keys as given in a single file replacement.
+func (s *Store) SaveAll(keys []*km.Key) error {
+	return s.update(func(f *file) {
+		for _, k := range keys {
+			put(f, toRecord(k))
+		}
+	})
+}
+
+func (s *Store) Delete(kid string) error {
+	return s.update(func(f *file) {
+		f.Keys = slices.DeleteFunc(f.Keys, func(r record) bool { return r.KID == kid })
+		delete(f.Usage, kid)
+	})
+}
+
+// AddUsage adds deltas to the stored usage totals. The generation is left
+// alone, so usage flushes do not make managers reload.
+func (s *Store) AddUsage(_ context.Context, deltas []km.UsageDelta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if f.Usage == nil {
+		f.Usage = make(map[string]usageRecord, len(deltas))
+	}
+	for _, d := range deltas {
+		u := f.Usage[d.KID]
+		u.Signs += d.Signs
+		u.Verifies += d.Verifies
+		u.LastSignedAt = latest(u.LastSignedAt, d.LastSignedAt)
+		u.LastVerifiedAt = latest(u.LastVerifiedAt, d.LastVerifiedAt)
+		f.Usage[d.KID] = u
+	}
+
+	return s.write(f)
+}
+
+func (s *Store) Usage(_ context.Context) ([]km.UsageDelta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]km.UsageDelta, 0, len(f.Usage))
+	for kid, u := range f.Usage {
+		out = append(out, km.UsageDelta{
+			KID:            kid,
+			Signs:          u.Signs,
+			Verifies:       u.Verifies,
+			LastSignedAt:   u.LastSignedAt,
+			LastVerifiedAt: u.LastVerifiedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].KID < out[j].KID })
+
+	return out, nil
+}
+
+func latest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// save stores r, retiring any other active key of the same slot.
+func save(f *file, r record) {
+	if r.IsActive {
+		for i := range f.Keys {
+			o := &f.Keys[i]
+			if o.KID != r.KID && o.IsActive && sameSlot(*o, r) {
+				o.IsActive = false
+				o.UpdatedAt = r.UpdatedAt
+			}
+		}
+	}
+	put(f, r)
+}
+
+func put(f *file, r record) {
+	for i := range f.Keys {
+		if f.Keys[i].KID == r.KID {
+			f.Keys[i] = r
+			return
+		}
+	}
+	f.Keys = append(f.Keys, r)
+}
+
+func sameSlot(a, b record) bool {
+	return a.Alg == b.Alg && a.Purpose == b.Purpose && maps.Equal(a.Labels, b.Labels)
+}
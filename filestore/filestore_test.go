@@ -0,0 +1,174 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+func policy() (km.RotationConfig, error) {
+	return km.RotationConfig{TTL: time.Hour}, nil
+}
+
+func TestStore_ManagerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	enc, _ := km.NewAESGCMEncryptor(make([]byte, 32))
+
+	m, err := km.NewKeyManager(New(path), enc, policy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA, km.AlgES256}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+	if err := m.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	reopened, err := km.NewKeyManager(New(path), enc, policy)
+	if err != nil {
+		t.Fatalf("reopen error: %v", err)
+	}
+
+	keys := reopened.Keys()
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys after reopen, got %d", len(keys))
+	}
+
+	active := 0
+	for _, k := range keys {
+		if k.State == km.KeyStateActive {
+			active++
+		}
+	}
+	if active != 2 {
+		t.Fatalf("expected one active key per alg, got %d", active)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected key file mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestStore_VersionAndGet(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "keys.json"))
+
+	v0, err := s.Version()
+	if err != nil {
+		t.Fatalf("Version error: %v", err)
+	}
+
+	if err := s.Save(&km.Key{KID: "k1", Alg: km.AlgEdDSA, IsActive: true}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if err := s.Save(&km.Key{KID: "k2", Alg: km.AlgEdDSA, IsActive: true}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	if v, _ := s.Version(); v == v0 {
+		t.Fatalf("expected version to change after writes")
+	}
+
+	k1, err := s.Get(context.Background(), "k1")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if k1.IsActive {
+		t.Fatalf("expected saving k2 to retire k1")
+	}
+
+	if _, err := s.Get(context.Background(), "missing"); !errors.Is(err, km.ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestStore_Usage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	s := New(path)
+	enc, _ := km.NewAESGCMEncryptor(make([]byte, 32))
+
+	m, err := km.NewKeyManager(s, enc, policy, km.WithUsagePersistence(time.Hour))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	var kid string
+	for range 3 {
+		_, err := m.Sign(km.AlgEdDSA, func(k string) ([]byte, error) {
+			kid = k
+			return []byte("x"), nil
+		})
+		if err != nil {
+			t.Fatalf("Sign error: %v", err)
+		}
+	}
+
+	version, _ := s.Version()
+	if err := m.Close(context.Background()); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if v, _ := s.Version(); v != version {
+		t.Fatalf("usage flush must not change the version")
+	}
+
+	usage, err := s.Usage(context.Background())
+	if err != nil {
+		t.Fatalf("Usage error: %v", err)
+	}
+	if len(usage) != 1 || usage[0].KID != kid || usage[0].Signs != 3 || usage[0].LastSignedAt == nil {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+
+	last := *usage[0].LastSignedAt
+	earlier := last.Add(-time.Hour)
+	_ = s.AddUsage(context.Background(), []km.UsageDelta{{KID: kid, Signs: 2, LastSignedAt: &earlier}})
+
+	usage, _ = s.Usage(context.Background())
+	if usage[0].Signs != 5 || !usage[0].LastSignedAt.Equal(last) {
+		t.Fatalf("totals must add up and keep the latest time, got %+v", usage[0])
+	}
+
+	if err := s.Delete(kid); err != nil {
+		t.Fatalf("Delete error: %v", err)
+	}
+	if usage, _ := s.Usage(context.Background()); len(usage) != 0 {
+		t.Fatalf("deleting a key must drop its usage, got %+v", usage)
+	}
+}
+
+func TestStore_ReadsUnversionedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	legacy := `{"generation":3,"keys":[{"kid":"k1","alg":"EdDSA","is_active":true,` +
+		`"created_at":"2030-01-01T00:00:00Z","nonce":"AQ==","ciphertext":"Ag=="}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(path)
+	k, err := s.Get(context.Background(), "k1")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if !k.IsActive || k.EncryptedKey == nil || k.EncryptedKey.Ciphertext[0] != 2 {
+		t.Fatalf("unexpected key: %+v", k)
+	}
+
+	if err := s.Save(&km.Key{KID: "k2", Alg: km.AlgEdDSA}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), fmt.Sprintf(`"version": %d`, km.KeyEncodingVersion)) != 2 {
+		t.Fatalf("records must be rewritten in the versioned encoding:\n%s", data)
+	}
+}
@@ -0,0 +1,82 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// KeyFingerprint is the SHA-256 digest of a PKIX encoded public key, short
+// enough to read out when comparing keys across environments.
+type KeyFingerprint [sha256.Size]byte
+
+// Hex returns the digest as colon separated upper case hex, e.g. "3A:F0:...".
+func (f KeyFingerprint) Hex() string {
+	var b strings.Builder
+	for i, c := range f {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		fmt.Fprintf(&b, "%02X", c)
+	}
+	return b.String()
+}
+
+// Base64 returns the digest as unpadded standard base64, the form OpenSSH
+// prints after "SHA256:".
+func (f KeyFingerprint) Base64() string {
+	return base64.RawStdEncoding.EncodeToString(f[:])
+}
+
+func (f KeyFingerprint) String() string {
+	return "SHA256:" + f.Base64()
+}
+
+// Fingerprint returns the fingerprint of the public key of kid.
+func (km *KeyManager) Fingerprint(kid string) (KeyFingerprint, error) {
+	ck := km.keyByKID(kid)
+	if ck == nil {
+		return KeyFingerprint{}, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+	if ck.key.Alg.symmetric() {
+		return KeyFingerprint{}, fmt.Errorf("%w: %s keys have no public key", ErrUnsupportedAlg, ck.key.Alg)
+	}
+
+	return fingerprintOf(ck.pub)
+}
+
+// CompareWithJWK reports whether jwk holds the same public key as the key
+// of the same kid in the manager. A different key is reported as
+// ErrKeyMismatch with both fingerprints.
+func (km *KeyManager) CompareWithJWK(jwk JWK) error {
+	local, err := km.Fingerprint(jwk.Kid)
+	if err != nil {
+		return err
+	}
+
+	pub, err := jwkToPublicKey(jwk)
+	if err != nil {
+		return err
+	}
+
+	remote, err := fingerprintOf(pub)
+	if err != nil {
+		return err
+	}
+
+	if local != remote {
+		return fmt.Errorf("%w: %s: local %s, jwk %s", ErrKeyMismatch, jwk.Kid, local, remote)
+	}
+
+	return nil
+}
+
+func fingerprintOf(pub crypto.PublicKey) (KeyFingerprint, error) {
+	der, err := marshalPublicKey(pub)
+	if err != nil {
+		return KeyFingerprint{}, err
+	}
+	return sha256.Sum256(der), nil
+}
@@ -0,0 +1,69 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint_Forms(t *testing.T) {
+	var f KeyFingerprint
+	f[0], f[1], f[31] = 0x3a, 0xf0, 0x01
+
+	hex := f.Hex()
+	if !strings.HasPrefix(hex, "3A:F0:00:") || !strings.HasSuffix(hex, ":01") || len(hex) != 32*3-1 {
+		t.Fatalf("unexpected hex form %q", hex)
+	}
+	if b := f.Base64(); len(b) != 43 || strings.HasSuffix(b, "=") {
+		t.Fatalf("unexpected base64 form %q", b)
+	}
+	if s := f.String(); s != "SHA256:"+f.Base64() {
+		t.Fatalf("unexpected string form %q", s)
+	}
+}
+
+func TestFingerprint_MatchesAcrossManagers(t *testing.T) {
+	store := NewMockStore()
+	a, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = a.InitKeys([]Alg{AlgES256, AlgEdDSA, AlgRS256})
+
+	b, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithVerifyOnly())
+
+	var jwks JWKS
+	raw, _ := a.JWKS()
+	if err := json.Unmarshal(raw, &jwks); err != nil {
+		t.Fatalf("unmarshal jwks: %v", err)
+	}
+
+	for _, jwk := range jwks.Keys {
+		fa, err := a.Fingerprint(jwk.Kid)
+		if err != nil {
+			t.Fatalf("Fingerprint error: %v", err)
+		}
+		fb, _ := b.Fingerprint(jwk.Kid)
+		if fa != fb {
+			t.Fatalf("fingerprints of %s differ: %s != %s", jwk.Kid, fa, fb)
+		}
+
+		if err := b.CompareWithJWK(jwk); err != nil {
+			t.Fatalf("CompareWithJWK error: %v", err)
+		}
+	}
+
+	other, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = other.InitKeys([]Alg{AlgEdDSA})
+	otherKey := other.activeKey(AlgEdDSA).key.KID
+	for _, jwk := range jwks.Keys {
+		if jwk.Alg == string(AlgEdDSA) {
+			jwk.Kid = otherKey
+			if err := other.CompareWithJWK(jwk); !errors.Is(err, ErrKeyMismatch) {
+				t.Fatalf("expected ErrKeyMismatch, got %v", err)
+			}
+		}
+	}
+
+	if _, err := a.Fingerprint("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
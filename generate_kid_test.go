@@ -1,7 +1,9 @@
 package keys_manager
 
 import (
+	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -11,7 +13,10 @@ func TestGenerateKID_BasicProperties(t *testing.T) {
 
 	for _, alg := range algs {
 		t.Run(string(alg), func(t *testing.T) {
-			kid := generateKID(alg)
+			kid, err := generateKID(rand.Reader, alg)
+			if err != nil {
+				t.Fatalf("[%s] generateKID error: %v", alg, err)
+			}
 
 			prefix := string(alg) + "_"
 			if !strings.HasPrefix(kid, prefix) {
@@ -21,7 +26,7 @@ func TestGenerateKID_BasicProperties(t *testing.T) {
 
 			b64 := kid[len(prefix):]
 
-			_, err := base64.RawURLEncoding.DecodeString(b64)
+			_, err = base64.RawURLEncoding.DecodeString(b64)
 			if err != nil {
 				t.Fatalf("[%s] kid %q has invalid base64 %q: %v",
 					alg, kid, b64, err)
@@ -39,10 +44,64 @@ func TestGenerateKID_BasicProperties(t *testing.T) {
 }
 
 func TestGenerateKID_Uniqueness(t *testing.T) {
-	kid1 := generateKID(AlgRS256)
-	kid2 := generateKID(AlgRS256)
+	kid1, _ := generateKID(rand.Reader, AlgRS256)
+	kid2, _ := generateKID(rand.Reader, AlgRS256)
 
 	if kid1 == kid2 {
 		t.Fatalf("two KIDs are equal: %q and %q", kid1, kid2)
 	}
 }
+
+func TestGenerateKID_ReadFailure(t *testing.T) {
+	if kid, err := generateKID(failingReader{}, AlgRS256); err == nil {
+		t.Fatalf("read failure must be an error, got kid %q", kid)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestNewKID_RejectsCollisions(t *testing.T) {
+	store := NewMockStore()
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithRand(zeroReader{}))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("first rotation error: %v", err)
+	}
+	if err := km.Rotate(AlgEdDSA); !errors.Is(err, ErrKIDCollision) {
+		t.Fatalf("KID already in the cache must be rejected, got %v", err)
+	}
+}
+
+func TestNewKID_StoreCheck(t *testing.T) {
+	kid, _ := generateKID(zeroReader{}, AlgEdDSA)
+
+	for _, check := range []bool{false, true} {
+		store := NewMockStore()
+
+		opts := []Option{WithRand(zeroReader{})}
+		if check {
+			opts = append(opts, WithKIDCollisionCheck())
+		}
+		km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, opts...)
+
+		priv, _ := generatePrivateKey(AlgEdDSA)
+		store.data[kid] = makeTestKey(kid, AlgEdDSA, false, nil, MockEncryptor{}, priv)
+
+		err := km.Rotate(AlgEdDSA)
+		if check && !errors.Is(err, ErrKIDCollision) {
+			t.Fatalf("KID in the store must be rejected, got %v", err)
+		}
+		if !check && err != nil {
+			t.Fatalf("without the store check rotation must succeed, got %v", err)
+		}
+	}
+}
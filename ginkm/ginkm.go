@@ -0,0 +1,41 @@
+// Package ginkm verifies bearer JWTs in Gin handlers with a KeyManager.
+package ginkm
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/keylet-auth/keys-manager/httpkm"
+)
+
+// ClaimsKey is the gin.Context key holding the verified claims.
+const ClaimsKey = "keys_manager.claims"
+
+// Middleware aborts requests without a valid bearer token with 401.
+// Verified claims are stored under ClaimsKey and in the request context,
+// where httpkm.ClaimsFromContext finds them.
+func Middleware(v httpkm.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := httpkm.Authenticate(v, c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", httpkm.Challenge(err))
+			_ = c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		}
+
+		c.Set(ClaimsKey, claims)
+		c.Request = c.Request.WithContext(httpkm.ContextWithClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+}
+
+// Claims returns the claims stored by Middleware.
+func Claims(c *gin.Context) (map[string]any, bool) {
+	v, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(map[string]any)
+	return claims, ok
+}
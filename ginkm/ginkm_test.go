@@ -0,0 +1,59 @@
+package ginkm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/httpkm"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store())
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	var input string
+	sig, _ := m.Sign(km.AlgEdDSA, func(kid string) ([]byte, error) {
+		input = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"alg":"EdDSA","kid":%q}`, kid))) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`))
+		return []byte(input), nil
+	})
+	token := input + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	r := gin.New()
+	r.Use(Middleware(m))
+	r.GET("/me", func(c *gin.Context) {
+		claims, _ := Claims(c)
+		fromCtx, _ := httpkm.ClaimsFromContext(c.Request.Context())
+		c.String(http.StatusOK, fmt.Sprint(claims["sub"], fromCtx["sub"]))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "alicealice" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token+"x")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != `Bearer error="invalid_token"` {
+		t.Fatalf("expected 401 invalid_token, got %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+}
@@ -0,0 +1,125 @@
+package grpckm
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Client talks to a Server and implements the manager's signing,
+// verification, JWKS and rotation interfaces without holding private keys.
+type Client struct {
+	cc grpc.ClientConnInterface
+
+	mu     sync.Mutex
+	active map[km.Alg]string
+}
+
+var (
+	_ km.TokenSigner   = (*Client)(nil)
+	_ km.TokenVerifier = (*Client)(nil)
+	_ km.KeyRotator    = (*Client)(nil)
+	_ km.JWKSProvider  = (*Client)(nil)
+)
+
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc, active: make(map[km.Alg]string)}
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp any) error {
+	return fromStatus(c.cc.Invoke(ctx, method, req, resp, grpc.CallContentSubtype(codecName)))
+}
+
+func (c *Client) Sign(alg km.Alg, build func(kid string) ([]byte, error)) ([]byte, error) {
+	return c.SignCtx(context.Background(), alg, build)
+}
+
+// SignCtx builds the signing input for the server's active key of alg and
+// has the server sign it. The active KID is cached; if the server rotated
+// in the meantime the input is rebuilt once for the new key.
+func (c *Client) SignCtx(ctx context.Context, alg km.Alg, build func(kid string) ([]byte, error)) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		kid, err := c.activeKID(ctx, alg)
+		if err != nil {
+			return nil, err
+		}
+
+		input, err := build(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp SignResponse
+		err = c.invoke(ctx, methodSign, &SignRequest{Alg: string(alg), KID: kid, Input: input}, &resp)
+		if errors.Is(err, errStaleKID) && attempt == 0 {
+			c.forget(alg)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Signature, nil
+	}
+}
+
+func (c *Client) activeKID(ctx context.Context, alg km.Alg) (string, error) {
+	c.mu.Lock()
+	kid := c.active[alg]
+	c.mu.Unlock()
+
+	if kid != "" {
+		return kid, nil
+	}
+
+	var resp ActiveKeyResponse
+	if err := c.invoke(ctx, methodActiveKey, &ActiveKeyRequest{Alg: string(alg)}, &resp); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.active[alg] = resp.KID
+	c.mu.Unlock()
+
+	return resp.KID, nil
+}
+
+func (c *Client) forget(alg km.Alg) {
+	c.mu.Lock()
+	delete(c.active, alg)
+	c.mu.Unlock()
+}
+
+func (c *Client) Verify(kid string, payload, sig []byte) error {
+	return c.VerifyCtx(context.Background(), kid, payload, sig)
+}
+
+func (c *Client) VerifyCtx(ctx context.Context, kid string, payload, sig []byte) error {
+	return c.invoke(ctx, methodVerify, &VerifyRequest{KID: kid, Payload: payload, Signature: sig}, &VerifyResponse{})
+}
+
+func (c *Client) JWKS() ([]byte, error) {
+	var resp JWKSResponse
+	if err := c.invoke(context.Background(), methodJWKS, &JWKSRequest{}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.JWKS, nil
+}
+
+func (c *Client) Rotate(alg km.Alg) error {
+	defer c.forget(alg)
+	return c.invoke(context.Background(), methodRotate, &RotateRequest{Alg: string(alg)}, &RotateResponse{})
+}
+
+func (c *Client) RotateExpired() error {
+	defer func() {
+		c.mu.Lock()
+		clear(c.active)
+		c.mu.Unlock()
+	}()
+	return c.invoke(context.Background(), methodRotateExpired, &RotateExpiredRequest{}, &RotateExpiredResponse{})
+}
@@ -0,0 +1,95 @@
+package grpckm
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// errStaleKID is returned when a client built its signing input for a key
+// that is no longer active.
+var errStaleKID = errors.New("kid is not the active key")
+
+// errorDomain marks the ErrorInfo details this package attaches.
+const errorDomain = "keys-manager"
+
+// statusCodes maps manager errors to gRPC codes. Several errors share a
+// code, so the reason travels as an ErrorInfo detail and the client maps
+// it back, keeping errors.Is working across the wire. Unmapped errors,
+// such as store failures, become Internal.
+var statusCodes = []struct {
+	err    error
+	code   codes.Code
+	reason string
+}{
+	{errStaleKID, codes.Aborted, "STALE_KID"},
+	{km.ErrKeyNotFound, codes.NotFound, "KEY_NOT_FOUND"},
+	{km.ErrNoActiveKey, codes.FailedPrecondition, "NO_ACTIVE_KEY"},
+	{km.ErrKeyRevoked, codes.FailedPrecondition, "KEY_REVOKED"},
+	{km.ErrKeyExpired, codes.FailedPrecondition, "KEY_EXPIRED"},
+	{km.ErrUnsupportedAlg, codes.InvalidArgument, "UNSUPPORTED_ALG"},
+	{km.ErrVerifyOnly, codes.Unimplemented, "VERIFY_ONLY"},
+	{km.ErrKeyNotYetValid, codes.OutOfRange, "KEY_NOT_YET_VALID"},
+	{km.ErrClosed, codes.Unavailable, "CLOSED"},
+	{km.ErrRateLimited, codes.ResourceExhausted, "RATE_LIMITED"},
+	{km.ErrForbidden, codes.PermissionDenied, "FORBIDDEN"},
+	{km.ErrInvalidSignature, codes.Unauthenticated, "INVALID_SIGNATURE"},
+	{km.ErrInvalidToken, codes.Unauthenticated, "INVALID_TOKEN"},
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	for _, m := range statusCodes {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+		st, derr := status.New(m.code, err.Error()).WithDetails(&errdetails.ErrorInfo{Reason: m.reason, Domain: errorDomain})
+		if derr != nil {
+			return status.Error(m.code, err.Error())
+		}
+		return st.Err()
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+// remoteError keeps the server's message while matching the sentinel for
+// its status code.
+type remoteError struct {
+	msg      string
+	sentinel error
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.sentinel }
+
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != errorDomain {
+			continue
+		}
+		for _, m := range statusCodes {
+			if m.reason == info.Reason {
+				return &remoteError{msg: st.Message(), sentinel: m.err}
+			}
+		}
+	}
+	return err
+}
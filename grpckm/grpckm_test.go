@@ -0,0 +1,235 @@
+package grpckm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+func policy() (km.RotationConfig, error) {
+	return km.RotationConfig{TTL: time.Hour}, nil
+}
+
+func newPair(t *testing.T, opts ...ServerOption) (*km.KeyManager, *Client) {
+	t.Helper()
+
+	m, err := km.NewKeyManager(km.NewMockStore(), km.MockEncryptor{}, policy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	return m, serve(t, m, opts...)
+}
+
+func serve(t *testing.T, m *km.KeyManager, opts ...ServerOption) *Client {
+	t.Helper()
+	return serveWith(t, m, nil, opts...)
+}
+
+func serveWith(t *testing.T, m *km.KeyManager, grpcOpts []grpc.ServerOption, opts ...ServerOption) *Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpcOpts...)
+	NewServer(m, opts...).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient error: %v", err)
+	}
+	t.Cleanup(func() { _ = cc.Close() })
+
+	return NewClient(cc)
+}
+
+func TestClient_SignVerifyRoundTrip(t *testing.T) {
+	m, c := newPair(t)
+
+	var kid string
+	sig, err := c.Sign(km.AlgEdDSA, func(k string) ([]byte, error) {
+		kid = k
+		return []byte("payload"), nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	if err := m.Verify(kid, []byte("payload"), sig); err != nil {
+		t.Fatalf("local Verify of remote signature failed: %v", err)
+	}
+	if err := c.Verify(kid, []byte("payload"), sig); err != nil {
+		t.Fatalf("remote Verify failed: %v", err)
+	}
+	if err := c.Verify(kid, []byte("tampered"), sig); err == nil {
+		t.Fatalf("expected remote Verify to reject tampered payload")
+	}
+
+	body, err := c.JWKS()
+	if err != nil || len(body) == 0 {
+		t.Fatalf("JWKS error: %v", err)
+	}
+}
+
+func TestClient_RebuildsInputAfterServerRotation(t *testing.T) {
+	m, c := newPair(t)
+
+	var kids []string
+	build := func(k string) ([]byte, error) {
+		kids = append(kids, k)
+		return []byte(k), nil
+	}
+
+	if _, err := c.Sign(km.AlgEdDSA, build); err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	if err := m.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	kids = nil
+	sig, err := c.Sign(km.AlgEdDSA, build)
+	if err != nil {
+		t.Fatalf("Sign after rotation error: %v", err)
+	}
+	if len(kids) != 2 || kids[0] == kids[1] {
+		t.Fatalf("expected input to be rebuilt for the new key, got %v", kids)
+	}
+	if err := m.Verify(kids[1], []byte(kids[1]), sig); err != nil {
+		t.Fatalf("Verify with new key failed: %v", err)
+	}
+}
+
+func TestClient_SignsAcrossWeightedSplit(t *testing.T) {
+	store := km.NewMockStore()
+	m, err := km.NewKeyManager(store, km.MockEncryptor{}, policy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	keys, _ := store.List()
+	canary := *keys[0]
+	canary.KID = "canary"
+	canary.Weight = 1
+	keys[0].Weight = 3
+	_ = store.Save(&canary)
+	// MockStore keeps one active key per slot; reactivate the original
+	// in place to form the split.
+	keys[0].IsActive = true
+	if err := m.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+
+	c := serve(t, m)
+	for range 50 {
+		var kids []string
+		_, err := c.Sign(km.AlgEdDSA, func(k string) ([]byte, error) {
+			kids = append(kids, k)
+			return []byte(k), nil
+		})
+		if err != nil {
+			t.Fatalf("Sign error: %v", err)
+		}
+		if len(kids) != 1 {
+			t.Fatalf("input must not be rebuilt within a split, got %v", kids)
+		}
+	}
+}
+
+func TestClient_ErrorsMapToSentinels(t *testing.T) {
+	_, c := newPair(t)
+
+	if err := c.Verify("missing", nil, nil); !errors.Is(err, km.ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	_, err := c.Sign(km.AlgES256, func(string) ([]byte, error) { return nil, nil })
+	if !errors.Is(err, km.ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey, got %v", err)
+	}
+}
+
+func TestStatus_MapsManagerErrors(t *testing.T) {
+	for _, m := range statusCodes {
+		err := toStatus(fmt.Errorf("op: %w", m.err))
+		if got := status.Code(err); got != m.code {
+			t.Fatalf("%v: expected %v, got %v", m.err, m.code, got)
+		}
+		if back := fromStatus(err); !errors.Is(back, m.err) {
+			t.Fatalf("%v: client must map %v back, got %v", m.err, m.code, back)
+		}
+	}
+
+	if got := status.Code(toStatus(errors.New("store unreachable"))); got != codes.Internal {
+		t.Fatalf("unmapped errors must be Internal, got %v", got)
+	}
+}
+
+func TestServer_PassesCallerContext(t *testing.T) {
+	var callers []string
+	authz := km.AuthorizerFunc(func(_ context.Context, req km.AccessRequest) error {
+		callers = append(callers, string(req.Action)+":"+req.Caller)
+		return nil
+	})
+
+	m, err := km.NewKeyManager(km.NewMockStore(), km.MockEncryptor{}, policy, km.WithAuthorizer(authz))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	_ = m.InitKeys([]km.Alg{km.AlgEdDSA})
+
+	tag := grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+		return h(km.WithCaller(ctx, "billing"), req)
+	})
+	c := serveWith(t, m, []grpc.ServerOption{tag}, WithRotation())
+
+	callers = nil
+	if err := c.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if _, err := c.Sign(km.AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil }); err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	if len(callers) != 2 || callers[0] != "rotate:billing" || callers[1] != "sign:billing" {
+		t.Fatalf("the authorizer must see the caller of every call, got %v", callers)
+	}
+}
+
+func TestServer_RotationDisabledByDefault(t *testing.T) {
+	_, c := newPair(t)
+
+	err := c.Rotate(km.AlgEdDSA)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+
+	_, c = newPair(t, WithRotation())
+	if err := c.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("Rotate with rotation enabled failed: %v", err)
+	}
+}
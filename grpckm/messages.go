@@ -0,0 +1,45 @@
+package grpckm
+
+type ActiveKeyRequest struct {
+	Alg string `json:"alg"`
+}
+
+type ActiveKeyResponse struct {
+	KID string `json:"kid"`
+}
+
+// SignRequest carries a signing input built for KID. The server refuses to
+// sign when KID is no longer the active key of Alg.
+type SignRequest struct {
+	Alg   string `json:"alg"`
+	KID   string `json:"kid"`
+	Input []byte `json:"input"`
+}
+
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+type VerifyRequest struct {
+	KID       string `json:"kid"`
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+type VerifyResponse struct{}
+
+type JWKSRequest struct{}
+
+type JWKSResponse struct {
+	JWKS []byte `json:"jwks"`
+}
+
+type RotateRequest struct {
+	Alg string `json:"alg"`
+}
+
+type RotateResponse struct{}
+
+type RotateExpiredRequest struct{}
+
+type RotateExpiredResponse struct{}
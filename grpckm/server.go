@@ -0,0 +1,112 @@
+// Package grpckm serves a KeyManager over gRPC so application pods can sign
+// and verify through a central manager without holding private keys.
+package grpckm
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Server exposes a KeyManager over gRPC. Only the default purpose is
+// served, and signing is limited to the currently active key of an alg.
+type Server struct {
+	m           *km.KeyManager
+	allowRotate bool
+}
+
+type ServerOption func(*Server)
+
+// WithRotation lets clients call Rotate and RotateExpired. Without it
+// both fail with PermissionDenied.
+func WithRotation() ServerOption {
+	return func(s *Server) {
+		s.allowRotate = true
+	}
+}
+
+func NewServer(m *km.KeyManager, opts ...ServerOption) *Server {
+	s := &Server{m: m}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	r.RegisterService(&serviceDesc, s)
+}
+
+func (s *Server) ActiveKey(ctx context.Context, req *ActiveKeyRequest) (*ActiveKeyResponse, error) {
+	info, err := s.m.ActiveKeyInfoCtx(ctx, km.Alg(req.Alg))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &ActiveKeyResponse{KID: info.KID}, nil
+}
+
+// Sign signs with the key the client built its input for, as long as that
+// key is still one Sign may use for the alg; otherwise the client is told
+// to fetch the active key again.
+func (s *Server) Sign(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+	if !s.signable(ctx, km.Alg(req.Alg), req.KID) {
+		return nil, toStatus(errStaleKID)
+	}
+
+	sig, err := s.m.SignWithKIDCtx(ctx, req.KID, func(string) ([]byte, error) {
+		return req.Input, nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &SignResponse{Signature: sig}, nil
+}
+
+// signable reports whether kid is an active default-purpose key of alg.
+// With a weighted split every key of the split qualifies.
+func (s *Server) signable(ctx context.Context, alg km.Alg, kid string) bool {
+	k, err := s.m.KeyInfoByKIDCtx(ctx, kid)
+	if err != nil {
+		return false
+	}
+	return k.Alg == alg && k.Purpose == km.PurposeDefault && len(k.Labels) == 0 && k.State == km.KeyStateActive
+}
+
+func (s *Server) Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	if err := s.m.VerifyCtx(ctx, req.KID, req.Payload, req.Signature); err != nil {
+		return nil, toStatus(err)
+	}
+	return &VerifyResponse{}, nil
+}
+
+func (s *Server) JWKS(context.Context, *JWKSRequest) (*JWKSResponse, error) {
+	body, err := s.m.JWKS()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &JWKSResponse{JWKS: body}, nil
+}
+
+func (s *Server) Rotate(ctx context.Context, req *RotateRequest) (*RotateResponse, error) {
+	if !s.allowRotate {
+		return nil, status.Error(codes.PermissionDenied, "rotation is disabled")
+	}
+	if err := s.m.RotateWithPurposeCtx(ctx, km.Alg(req.Alg), km.PurposeDefault); err != nil {
+		return nil, toStatus(err)
+	}
+	return &RotateResponse{}, nil
+}
+
+func (s *Server) RotateExpired(ctx context.Context, _ *RotateExpiredRequest) (*RotateExpiredResponse, error) {
+	if !s.allowRotate {
+		return nil, status.Error(codes.PermissionDenied, "rotation is disabled")
+	}
+	if err := s.m.RotateExpiredCtx(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &RotateExpiredResponse{}, nil
+}
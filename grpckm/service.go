@@ -0,0 +1,55 @@
+package grpckm
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const serviceName = "keys_manager.v1.KeyManager"
+
+const (
+	methodActiveKey     = "/" + serviceName + "/ActiveKey"
+	methodSign          = "/" + serviceName + "/Sign"
+	methodVerify        = "/" + serviceName + "/Verify"
+	methodJWKS          = "/" + serviceName + "/JWKS"
+	methodRotate        = "/" + serviceName + "/Rotate"
+	methodRotateExpired = "/" + serviceName + "/RotateExpired"
+)
+
+// unaryHandler adapts a typed server method to a grpc.MethodDesc handler.
+func unaryHandler[Req, Resp any](
+	fullMethod string,
+	call func(s *Server, ctx context.Context, req *Req) (*Resp, error),
+) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+
+		s := srv.(*Server)
+		if interceptor == nil {
+			return call(s, ctx, req)
+		}
+
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return call(s, ctx, req.(*Req))
+		})
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "ActiveKey", Handler: unaryHandler(methodActiveKey, (*Server).ActiveKey)},
+		{MethodName: "Sign", Handler: unaryHandler(methodSign, (*Server).Sign)},
+		{MethodName: "Verify", Handler: unaryHandler(methodVerify, (*Server).Verify)},
+		{MethodName: "JWKS", Handler: unaryHandler(methodJWKS, (*Server).JWKS)},
+		{MethodName: "Rotate", Handler: unaryHandler(methodRotate, (*Server).Rotate)},
+		{MethodName: "RotateExpired", Handler: unaryHandler(methodRotateExpired, (*Server).RotateExpired)},
+	},
+	Metadata: "keys_manager.v1",
+}
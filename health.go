@@ -0,0 +1,149 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type ActiveKeyHealth struct {
+	Alg       Alg               `json:"alg"`
+	Purpose   Purpose           `json:"purpose,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	KID       string            `json:"kid,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Expired   bool              `json:"expired,omitempty"`
+}
+
+// Present reports whether the slot has an active key.
+func (a ActiveKeyHealth) Present() bool {
+	return a.KID != ""
+}
+
+type QuarantinedKeyHealth struct {
+	KID string `json:"kid"`
+	Alg Alg    `json:"alg"`
+	Err string `json:"error"`
+}
+
+type HealthReport struct {
+	StoreReachable  bool                   `json:"store_reachable"`
+	StoreErr        string                 `json:"store_error,omitempty"`
+	LastReload      time.Time              `json:"last_reload"`
+	Active          []ActiveKeyHealth      `json:"active"`
+	Quarantined     []QuarantinedKeyHealth `json:"quarantined,omitempty"`
+	DuplicateActive []DuplicateActive      `json:"duplicate_active,omitempty"`
+}
+
+// OK reports whether the store is reachable and every active key slot,
+// including those requested through InitKeys, holds an unexpired key.
+// Quarantined keys and duplicate active keys are reported but do not make
+// the manager unhealthy.
+func (r *HealthReport) OK() bool {
+	if !r.StoreReachable {
+		return false
+	}
+	for _, a := range r.Active {
+		if !a.Present() || a.Expired {
+			return false
+		}
+	}
+	return true
+}
+
+func (km *KeyManager) Health() *HealthReport {
+	return km.HealthCtx(context.Background())
+}
+
+// HealthCtx probes the store and reports the state of the loaded key set.
+// The probe asks a VersionedStore for its version and falls back to listing
+// keys otherwise; the cache itself is not reloaded.
+func (km *KeyManager) HealthCtx(ctx context.Context) *HealthReport {
+	report := &HealthReport{StoreReachable: true}
+
+	if err := km.probeStore(ctx); err != nil {
+		report.StoreReachable = false
+		report.StoreErr = err.Error()
+	}
+
+	report.LastReload = km.reloadStats.lastSuccess()
+
+	snap := km.snapshot()
+	now := km.clock.Now()
+
+	slots := make(map[keySlot]*CachedKey, len(snap.active))
+	for s, ck := range snap.active {
+		slots[s] = ck
+	}
+	km.initSlots.Range(func(s, _ any) bool {
+		if _, ok := slots[s.(keySlot)]; !ok {
+			slots[s.(keySlot)] = nil
+		}
+		return true
+	})
+
+	for s, ck := range slots {
+		a := ActiveKeyHealth{Alg: s.alg, Purpose: s.purpose}
+		if ck != nil {
+			a.Labels = ck.key.Labels
+			a.KID = ck.key.KID
+			a.ExpiresAt = ck.key.ExpiresAt
+			a.Expired = ck.key.ExpiresAt != nil && !now.Before(*ck.key.ExpiresAt)
+		}
+		report.Active = append(report.Active, a)
+	}
+
+	sort.Slice(report.Active, func(i, j int) bool {
+		a, b := report.Active[i], report.Active[j]
+		if a.Alg != b.Alg {
+			return a.Alg < b.Alg
+		}
+		if a.Purpose != b.Purpose {
+			return a.Purpose < b.Purpose
+		}
+		return formatLabels(a.Labels) < formatLabels(b.Labels)
+	})
+
+	report.Quarantined = quarantineHealth(snap.quarantine)
+	report.DuplicateActive = snap.duplicates
+
+	return report
+}
+
+func quarantineHealth(q []QuarantinedKey) []QuarantinedKeyHealth {
+	var out []QuarantinedKeyHealth
+	for _, k := range q {
+		out = append(out, QuarantinedKeyHealth{KID: k.KID, Alg: k.Alg, Err: k.Err.Error()})
+	}
+	return out
+}
+
+func (km *KeyManager) probeStore(ctx context.Context) error {
+	if vs, ok := km.store.(VersionedStore); ok {
+		_, err := vs.Version()
+		return err
+	}
+
+	_, err := km.listKeys(ctx)
+	return err
+}
+
+// HealthHandler serves the health report as JSON for /healthz style
+// endpoints, with status 200 when the report is OK and 503 otherwise.
+func (km *KeyManager) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := km.HealthCtx(r.Context())
+
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,113 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth_ReportsActiveKeysAndMissingSlots(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+	km.initSlots.Store(keySlot{alg: AlgES256}, struct{}{})
+
+	report := km.Health()
+	if !report.StoreReachable {
+		t.Fatalf("store must be reachable, got %q", report.StoreErr)
+	}
+	if !report.LastReload.Equal(clock.now) {
+		t.Fatalf("expected last reload %v, got %v", clock.now, report.LastReload)
+	}
+	if len(report.Active) != 2 {
+		t.Fatalf("expected 2 slots, got %+v", report.Active)
+	}
+	if report.Active[0].Alg != AlgES256 || report.Active[0].Present() {
+		t.Fatalf("ES256 slot must be reported missing, got %+v", report.Active[0])
+	}
+	if report.Active[1].Alg != AlgEdDSA || !report.Active[1].Present() {
+		t.Fatalf("EdDSA slot must be present, got %+v", report.Active[1])
+	}
+	if report.OK() {
+		t.Fatalf("report with a missing slot must not be OK")
+	}
+}
+
+func TestHealth_ExpiredKeyIsUnhealthy(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	if report := km.Health(); !report.OK() {
+		t.Fatalf("fresh key must be healthy, got %+v", report)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+
+	report := km.Health()
+	if !report.Active[0].Expired || report.OK() {
+		t.Fatalf("expired active key must be unhealthy, got %+v", report.Active[0])
+	}
+}
+
+func TestHealth_StoreUnreachable(t *testing.T) {
+	store := &FailingStore{MockStore: *NewMockStore()}
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	store.FailList = true
+
+	report := km.Health()
+	if report.StoreReachable || report.StoreErr == "" {
+		t.Fatalf("list failure must mark the store unreachable, got %+v", report)
+	}
+	if report.OK() {
+		t.Fatalf("unreachable store must not be OK")
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithLenientReload())
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	rec := httptest.NewRecorder()
+	km.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	_ = store.Save(&Key{KID: "broken", Alg: AlgRS256, EncryptedKey: &EncryptedKey{Nonce: []byte{1}, Ciphertext: []byte{2}}})
+	_ = km.ReloadCache()
+	km.initSlots.Store(keySlot{alg: AlgRS256}, struct{}{})
+
+	rec = httptest.NewRecorder()
+	km.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+
+	var report HealthReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(report.Quarantined) != 1 || report.Quarantined[0].KID != "broken" {
+		t.Fatalf("expected broken key to be quarantined, got %+v", report.Quarantined)
+	}
+	if report.Quarantined[0].Err == "" {
+		t.Fatalf("quarantine cause must be reported")
+	}
+}
@@ -0,0 +1,107 @@
+// Package httpkm verifies bearer JWTs in net/http handlers with a
+// KeyManager and puts the claims into the request context. The middleware
+// has the func(http.Handler) http.Handler shape used by chi and most other
+// routers built on net/http.
+package httpkm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Verifier verifies a compact JWT and returns its claims. *km.KeyManager
+// implements it.
+type Verifier interface {
+	VerifyJWTCtx(ctx context.Context, token string) (map[string]any, error)
+}
+
+var (
+	_ Verifier = (*km.KeyManager)(nil)
+	_ Verifier = (*km.Verifier)(nil)
+)
+
+var ErrNoToken = errors.New("no bearer token")
+
+type claimsKey struct{}
+
+func ContextWithClaims(ctx context.Context, claims map[string]any) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored by the middleware.
+func ClaimsFromContext(ctx context.Context) (map[string]any, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(map[string]any)
+	return claims, ok
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer" header.
+func BearerToken(r *http.Request) (string, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrNoToken
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// Authenticate extracts and verifies the bearer token of r. It is the
+// shared core of the middleware in this package and its framework
+// adapters.
+func Authenticate(v Verifier, r *http.Request) (map[string]any, error) {
+	token, err := BearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	return v.VerifyJWTCtx(r.Context(), token)
+}
+
+// Challenge returns the WWW-Authenticate value for a failed request as
+// described in RFC 6750: requests without a token get no error code.
+func Challenge(err error) string {
+	if errors.Is(err, ErrNoToken) {
+		return "Bearer"
+	}
+	return `Bearer error="invalid_token"`
+}
+
+type Option func(*config)
+
+type config struct {
+	onError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// WithErrorHandler replaces the default 401 response for requests without
+// a valid token.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+func unauthorized(w http.ResponseWriter, _ *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", Challenge(err))
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// Middleware rejects requests without a valid bearer token and passes the
+// verified claims to next through the request context.
+func Middleware(v Verifier, opts ...Option) func(http.Handler) http.Handler {
+	cfg := config{onError: unauthorized}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := Authenticate(v, r)
+			if err != nil {
+				cfg.onError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+		})
+	}
+}
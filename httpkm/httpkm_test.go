@@ -0,0 +1,92 @@
+package httpkm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+func token(t *testing.T, m *km.KeyManager, claims string) string {
+	t.Helper()
+
+	var input string
+	sig, err := m.Sign(km.AlgEdDSA, func(kid string) ([]byte, error) {
+		header := fmt.Sprintf(`{"alg":"EdDSA","kid":%q}`, kid)
+		input = base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(claims))
+		return []byte(input), nil
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestMiddleware_Chi(t *testing.T) {
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store())
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := ClaimsFromContext(r.Context())
+		fmt.Fprint(w, claims["sub"])
+	})
+	r := Middleware(m)(mux)
+
+	cases := []struct {
+		name, auth string
+		code       int
+		challenge  string
+		body       string
+	}{
+		{"valid", "Bearer " + token(t, m, `{"sub":"alice"}`), http.StatusOK, "", "alice"},
+		{"missing", "", http.StatusUnauthorized, "Bearer", ""},
+		{"basic", "Basic abc", http.StatusUnauthorized, "Bearer", ""},
+		{"invalid", "Bearer a.b.c", http.StatusUnauthorized, `Bearer error="invalid_token"`, ""},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.code, rec.Code)
+		}
+		if got := rec.Header().Get("WWW-Authenticate"); got != tc.challenge {
+			t.Fatalf("%s: unexpected challenge %q", tc.name, got)
+		}
+		if tc.body != "" && rec.Body.String() != tc.body {
+			t.Fatalf("%s: unexpected body %q", tc.name, rec.Body)
+		}
+	}
+}
+
+func TestMiddleware_ErrorHandler(t *testing.T) {
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store())
+
+	var got error
+	h := Middleware(m, WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+		got = err
+		w.WriteHeader(http.StatusForbidden)
+	}))(http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusForbidden || got != ErrNoToken {
+		t.Fatalf("custom error handler not used: %d %v", rec.Code, got)
+	}
+}
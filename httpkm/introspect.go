@@ -0,0 +1,41 @@
+package httpkm
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// IntrospectionHandler serves OAuth 2.0 token introspection (RFC 7662) for
+// JWTs signed by v's keys. It accepts a POST form with a token parameter
+// and answers {"active": true} plus the token's claims, or just
+// {"active": false} for tokens that do not verify. The RFC requires the
+// endpoint to authenticate its callers; wrap the handler accordingly.
+// Verifying with a manager that has a replay cache consumes the token's jti.
+func IntrospectionHandler(v Verifier) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.PostFormValue("token")
+		if token == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_request"}`))
+			return
+		}
+
+		resp := map[string]any{"active": false}
+		if claims, err := v.VerifyJWTCtx(r.Context(), token); err == nil {
+			resp = claims
+			resp["active"] = true
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,56 @@
+package httpkm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+func TestIntrospectionHandler(t *testing.T) {
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store())
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	h := IntrospectionHandler(m)
+
+	introspect := func(tok string) (int, map[string]any) {
+		form := url.Values{"token": {tok}, "token_type_hint": {"access_token"}}
+		req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	code, body := introspect(token(t, m, `{"sub":"alice","scope":"read"}`))
+	if code != http.StatusOK || body["active"] != true || body["sub"] != "alice" || body["scope"] != "read" {
+		t.Fatalf("expected active token with claims, got %d %v", code, body)
+	}
+
+	code, body = introspect("a.b.c")
+	if code != http.StatusOK || body["active"] != false || len(body) != 1 {
+		t.Fatalf("expected only active=false for an invalid token, got %d %v", code, body)
+	}
+
+	code, body = introspect("")
+	if code != http.StatusBadRequest || body["error"] != "invalid_request" {
+		t.Fatalf("expected invalid_request without a token, got %d %v", code, body)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/introspect", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,173 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Finding kinds reported by VerifyStoreIntegrity.
+const (
+	FindingDuplicateKID      = "duplicate_kid"
+	FindingDuplicateActive   = "duplicate_active"
+	FindingNoMaterial        = "no_material"
+	FindingDecryptFailed     = "decrypt_failed"
+	FindingPlaintext         = "plaintext"
+	FindingParseFailed       = "parse_failed"
+	FindingPublicKeyMismatch = "public_key_mismatch"
+)
+
+// Finding is one problem found in the store, with the repair to apply.
+type Finding struct {
+	Kind    string            `json:"kind"`
+	KID     string            `json:"kid"`
+	Alg     Alg               `json:"alg,omitempty"`
+	Purpose Purpose           `json:"purpose,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Detail  string            `json:"detail"`
+	Repair  string            `json:"repair"`
+}
+
+type IntegrityReport struct {
+	Checked  int       `json:"checked"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+func (r *IntegrityReport) OK() bool {
+	return len(r.Findings) == 0
+}
+
+func (km *KeyManager) VerifyStoreIntegrity() (*IntegrityReport, error) {
+	return km.VerifyStoreIntegrityCtx(context.Background())
+}
+
+// VerifyStoreIntegrityCtx walks every stored key and checks that its
+// material decrypts and parses and matches the stored public key, that no
+// KID is stored twice and that no slot has more than one active key. Only
+// a failing store listing is returned as an error; everything else is a
+// finding.
+func (km *KeyManager) VerifyStoreIntegrityCtx(ctx context.Context) (*IntegrityReport, error) {
+	if km.verifyOnly {
+		return nil, ErrVerifyOnly
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IntegrityReport{Checked: len(keys)}
+	add := func(kind string, k *Key, detail, repair string) {
+		report.Findings = append(report.Findings, Finding{
+			Kind:    kind,
+			KID:     k.KID,
+			Alg:     k.Alg,
+			Purpose: k.Purpose,
+			Labels:  k.Labels,
+			Detail:  detail,
+			Repair:  repair,
+		})
+	}
+
+	seen := make(map[string]int, len(keys))
+	active := make(map[keySlot][]*Key)
+
+	for _, k := range keys {
+		seen[k.KID]++
+		if seen[k.KID] == 2 {
+			add(FindingDuplicateKID, k, "KID is stored more than once", "delete all but one row for the KID")
+		}
+
+		if k.IsActive && k.RevokedAt == nil {
+			active[slotOf(k)] = append(active[slotOf(k)], k)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if kind, err := km.checkKeyMaterial(ctx, k); err != nil {
+			add(kind, k, err.Error(), materialRepair(kind))
+		}
+	}
+
+	for s, dup := range active {
+		if len(dup) < 2 {
+			continue
+		}
+		for _, k := range dup {
+			add(FindingDuplicateActive, k, fmt.Sprintf("%d active keys for %s", len(dup), s), "retire the losing keys with RepairDuplicateActive")
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.KID < b.KID
+	})
+
+	return report, nil
+}
+
+// checkKeyMaterial decrypts and parses k and compares it with the stored
+// public key, returning the finding kind of the first failure.
+func (km *KeyManager) checkKeyMaterial(ctx context.Context, k *Key) (string, error) {
+	if k.EncryptedKey == nil {
+		return FindingNoMaterial, errors.New("no encrypted material")
+	}
+
+	plain, err := km.decrypt(ctx, k)
+	if err != nil {
+		if plaintextMaterial(k) {
+			return FindingPlaintext, ErrPlaintextKey
+		}
+		return FindingDecryptFailed, err
+	}
+	defer wipeBytes(plain)
+
+	if k.Alg.symmetric() {
+		if len(plain) != 32 {
+			return FindingParseFailed, fmt.Errorf("invalid %s secret length %d", k.Alg, len(plain))
+		}
+		return "", nil
+	}
+
+	priv, err := parsePrivateKey(plain)
+	if err != nil {
+		return FindingParseFailed, err
+	}
+	defer wipeSigner(priv)
+
+	if err := checkKeyAlg(k.Alg, priv); err != nil {
+		return FindingParseFailed, err
+	}
+
+	if len(k.PublicKey) > 0 {
+		pub, err := parsePublicKey(k.PublicKey)
+		if err != nil {
+			return FindingPublicKeyMismatch, err
+		}
+		eq, ok := pub.(interface{ Equal(crypto.PublicKey) bool })
+		if !ok || !eq.Equal(priv.Public()) {
+			return FindingPublicKeyMismatch, errors.New("stored public key does not match the private key")
+		}
+	}
+
+	return "", nil
+}
+
+func materialRepair(kind string) string {
+	switch kind {
+	case FindingDecryptFailed:
+		return "check the Encryptor and master key, or revoke the key and rotate"
+	case FindingPlaintext:
+		return "encrypt it with EncryptPlaintextKeys"
+	case FindingPublicKeyMismatch:
+		return "rewrite the public key from the private key, or revoke the key and rotate"
+	default:
+		return "revoke the key and rotate, or restore it from a backup"
+	}
+}
@@ -0,0 +1,72 @@
+package keys_manager
+
+import (
+	"testing"
+)
+
+type listingStore struct {
+	*MockStore
+	extra []*Key
+}
+
+func (s *listingStore) List() ([]*Key, error) {
+	keys, err := s.MockStore.List()
+	return append(keys, s.extra...), err
+}
+
+func TestVerifyStoreIntegrity_Findings(t *testing.T) {
+	enc := MockEncryptor{}
+	store := &listingStore{MockStore: NewMockStore()}
+
+	km, _ := NewKeyManager(store, enc, mockPolicy, WithLenientReload())
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	report, err := km.VerifyStoreIntegrity()
+	if err != nil || !report.OK() || report.Checked != 1 {
+		t.Fatalf("healthy store must have no findings, got %+v %v", report, err)
+	}
+
+	good := km.activeKey(AlgEdDSA).key
+
+	privA, _ := generatePrivateKey(AlgEdDSA)
+	privB, _ := generatePrivateKey(AlgEdDSA)
+	mismatched := makeTestKey("mismatch", AlgEdDSA, false, nil, enc, privA)
+	mismatched.PublicKey, _ = marshalPublicKey(privB.Public())
+
+	dupActive := makeTestKey("dup-active", AlgEdDSA, false, nil, enc, privA)
+	dupActive.IsActive = true
+
+	store.extra = []*Key{
+		{KID: "junk", Alg: AlgES256, EncryptedKey: &EncryptedKey{Ciphertext: []byte("junk")}},
+		{KID: "empty", Alg: AlgES256},
+		mismatched,
+		dupActive,
+		good,
+	}
+
+	report, err = km.VerifyStoreIntegrity()
+	if err != nil {
+		t.Fatalf("VerifyStoreIntegrity error: %v", err)
+	}
+
+	kinds := map[string][]string{}
+	for _, f := range report.Findings {
+		if f.Repair == "" {
+			t.Fatalf("finding %+v must carry a repair", f)
+		}
+		kinds[f.Kind] = append(kinds[f.Kind], f.KID)
+	}
+
+	want := map[string]int{
+		FindingParseFailed:       1,
+		FindingNoMaterial:        1,
+		FindingPublicKeyMismatch: 1,
+		FindingDuplicateKID:      1,
+		FindingDuplicateActive:   3,
+	}
+	for kind, n := range want {
+		if len(kinds[kind]) != n {
+			t.Fatalf("expected %d %s findings, got %v", n, kind, kinds)
+		}
+	}
+}
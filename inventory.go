@@ -0,0 +1,192 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"sort"
+	"time"
+)
+
+// Compliance flags raised on inventory entries.
+const (
+	FlagExpiredActive   = "expired_active"
+	FlagRotationOverdue = "rotation_overdue"
+	FlagWeakKey         = "weak_key"
+	FlagNoExpiry        = "no_expiry"
+	FlagNoPublicKey     = "no_public_key"
+	FlagQuarantined     = "quarantined"
+	FlagDuplicateActive = "duplicate_active"
+)
+
+const minRSABits = 2048
+
+type InventoryKey struct {
+	KID       string            `json:"kid"`
+	Alg       Alg               `json:"alg"`
+	Purpose   Purpose           `json:"purpose,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	State     KeyState          `json:"state"`
+	Bits      int               `json:"bits,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Age       time.Duration     `json:"age_ns"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	RevokedAt *time.Time        `json:"revoked_at,omitempty"`
+	Flags     []string          `json:"flags,omitempty"`
+}
+
+type Inventory struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	PolicyTTL   time.Duration    `json:"policy_ttl_ns"`
+	Keys        []InventoryKey   `json:"keys"`
+	ByAlg       map[Alg]int      `json:"by_alg"`
+	ByState     map[KeyState]int `json:"by_state"`
+	Flagged     int              `json:"flagged"`
+}
+
+// InventoryReport lists every stored key, read from the store rather than
+// the cache, with its size, age and state, and flags keys that break the
+// rotation policy or basic hygiene rules. It is meant for periodic
+// compliance jobs.
+func (km *KeyManager) InventoryReport() (*Inventory, error) {
+	return km.InventoryReportCtx(context.Background())
+}
+
+func (km *KeyManager) InventoryReportCtx(ctx context.Context) (*Inventory, error) {
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := km.rotationConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	ttls := make(map[Purpose]time.Duration, len(km.purposePolicies))
+	for purpose := range km.purposePolicies {
+		c, err := km.rotationConfigFor(purpose)
+		if err != nil {
+			return nil, err
+		}
+		ttls[purpose] = c.TTL
+	}
+
+	now := km.clock.Now()
+	snap := km.snapshot()
+
+	quarantined := make(map[string]bool, len(snap.quarantine))
+	for _, q := range snap.quarantine {
+		quarantined[q.KID] = true
+	}
+
+	activePerSlot := make(map[keySlot]int)
+	for _, k := range keys {
+		if k.IsActive && k.RevokedAt == nil {
+			activePerSlot[slotOf(k)]++
+		}
+	}
+
+	inv := &Inventory{
+		GeneratedAt: now,
+		PolicyTTL:   policy.TTL,
+		ByAlg:       make(map[Alg]int),
+		ByState:     make(map[KeyState]int),
+	}
+
+	for _, k := range keys {
+		e := InventoryKey{
+			KID:       k.KID,
+			Alg:       k.Alg,
+			Purpose:   k.Purpose,
+			Labels:    k.Labels,
+			State:     keyState(k),
+			CreatedAt: k.CreatedAt,
+			Age:       now.Sub(k.CreatedAt),
+			ExpiresAt: k.ExpiresAt,
+			RevokedAt: k.RevokedAt,
+		}
+
+		var pub crypto.PublicKey
+		if len(k.PublicKey) > 0 {
+			pub, _ = parsePublicKey(k.PublicKey)
+		} else if !k.Alg.symmetric() {
+			e.Flags = append(e.Flags, FlagNoPublicKey)
+		}
+		if pub == nil {
+			if ck := snap.cache[k.KID]; ck != nil {
+				pub = ck.pub
+			}
+		}
+		e.Bits = publicKeyBits(pub)
+
+		if e.State == KeyStateActive {
+			switch {
+			case k.ExpiresAt == nil:
+				e.Flags = append(e.Flags, FlagNoExpiry)
+			case km.expired(k, now):
+				e.Flags = append(e.Flags, FlagExpiredActive)
+			}
+			ttl, ok := ttls[k.Purpose]
+			if !ok {
+				ttl = policy.TTL
+			}
+			if ttl > 0 && e.Age > ttl {
+				e.Flags = append(e.Flags, FlagRotationOverdue)
+			}
+			if activePerSlot[slotOf(k)] > 1 {
+				e.Flags = append(e.Flags, FlagDuplicateActive)
+			}
+		}
+		if k.Alg == AlgRS256 && e.Bits > 0 && e.Bits < minRSABits {
+			e.Flags = append(e.Flags, FlagWeakKey)
+		}
+		if quarantined[k.KID] {
+			e.Flags = append(e.Flags, FlagQuarantined)
+		}
+
+		inv.ByAlg[k.Alg]++
+		inv.ByState[e.State]++
+		if len(e.Flags) > 0 {
+			inv.Flagged++
+		}
+		inv.Keys = append(inv.Keys, e)
+	}
+
+	sort.Slice(inv.Keys, func(i, j int) bool {
+		if !inv.Keys[i].CreatedAt.Equal(inv.Keys[j].CreatedAt) {
+			return inv.Keys[i].CreatedAt.After(inv.Keys[j].CreatedAt)
+		}
+		return inv.Keys[i].KID < inv.Keys[j].KID
+	})
+
+	return inv, nil
+}
+
+func keyState(k *Key) KeyState {
+	switch {
+	case k.RevokedAt != nil:
+		return KeyStateRevoked
+	case k.Pending:
+		return KeyStatePending
+	case k.Staged:
+		return KeyStateStaged
+	case k.IsActive:
+		return KeyStateActive
+	}
+	return KeyStateInactive
+}
+
+func publicKeyBits(pub crypto.PublicKey) int {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		return p.N.BitLen()
+	case *ecdsa.PublicKey:
+		return p.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	}
+	return 0
+}
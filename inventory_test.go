@@ -0,0 +1,67 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInventoryReport(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMockStore()
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithClock(clock))
+	_ = km.InitKeys([]Alg{AlgES256, AlgEdDSA})
+	_ = km.Rotate(AlgEdDSA)
+
+	priv, _ := generatePrivateKey(AlgRS256)
+	legacy := makeTestKey("legacy", AlgRS256, true, nil, MockEncryptor{}, priv)
+	legacy.CreatedAt = clock.now.Add(-48 * time.Hour)
+	_ = store.Save(legacy)
+	_ = km.ReloadCache()
+
+	clock.now = clock.now.Add(2 * time.Hour)
+
+	inv, err := km.InventoryReport()
+	if err != nil {
+		t.Fatalf("InventoryReport error: %v", err)
+	}
+
+	if len(inv.Keys) != 4 || inv.ByAlg[AlgEdDSA] != 2 || inv.ByState[KeyStateActive] != 3 {
+		t.Fatalf("unexpected totals: %d keys, by alg %v, by state %v", len(inv.Keys), inv.ByAlg, inv.ByState)
+	}
+	if inv.PolicyTTL != time.Hour {
+		t.Fatalf("expected policy TTL 1h, got %v", inv.PolicyTTL)
+	}
+
+	byKID := make(map[string]InventoryKey)
+	for _, k := range inv.Keys {
+		byKID[k.KID] = k
+	}
+
+	l := byKID["legacy"]
+	if l.Bits != 2048 || l.Age != 50*time.Hour {
+		t.Fatalf("unexpected legacy entry: %+v", l)
+	}
+	for _, f := range []string{FlagNoExpiry, FlagRotationOverdue, FlagNoPublicKey} {
+		if !slices.Contains(l.Flags, f) {
+			t.Fatalf("expected flag %s on legacy key, got %v", f, l.Flags)
+		}
+	}
+
+	es := km.activeKey(AlgES256).key.KID
+	if e := byKID[es]; e.Bits != 256 || !slices.Contains(e.Flags, FlagExpiredActive) {
+		t.Fatalf("expected expired active ES256 key, got %+v", e)
+	}
+
+	for _, k := range inv.Keys {
+		if k.State == KeyStateInactive && len(k.Flags) != 0 {
+			t.Fatalf("retired keys must not be flagged for rotation, got %+v", k)
+		}
+	}
+
+	if _, err := json.Marshal(inv); err != nil {
+		t.Fatalf("inventory must be JSON encodable: %v", err)
+	}
+}
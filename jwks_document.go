@@ -0,0 +1,59 @@
+package keys_manager
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// jwksDocument is the serialized JWKS published for one cache generation.
+type jwksDocument struct {
+	body []byte
+	etag string
+}
+
+// newPurposeJWKS builds a separate document for every purpose in cache.
+func newPurposeJWKS(cache map[string]*CachedKey, attestation bool) (map[Purpose]*jwksDocument, error) {
+	byPurpose := make(map[Purpose]map[string]*CachedKey)
+	for kid, ck := range cache {
+		p := ck.key.Purpose
+		if byPurpose[p] == nil {
+			byPurpose[p] = make(map[string]*CachedKey)
+		}
+		byPurpose[p][kid] = ck
+	}
+
+	out := make(map[Purpose]*jwksDocument, len(byPurpose))
+	for p, keys := range byPurpose {
+		doc, err := newJWKSDocument(keys, attestation)
+		if err != nil {
+			return nil, err
+		}
+		out[p] = doc
+	}
+
+	return out, nil
+}
+
+func newJWKSDocument(cache map[string]*CachedKey, attestation bool) (*jwksDocument, error) {
+	jwks := buildJWKS(cache)
+
+	if attestation {
+		for i := range jwks.Keys {
+			jwks.Keys[i].Attestation = cache[jwks.Keys[i].Kid].key.Attestation
+		}
+	}
+
+	body, err := json.Marshal(jwks)
+	if err != nil {
+		return nil, fmt.Errorf("marshal jwks: %w", err)
+	}
+
+	sum := sha256.Sum256(body)
+
+	return &jwksDocument{
+		body: body,
+		etag: `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package keys_manager
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestJWKSWithETag_StableUntilReloadChangesKeys(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgES256, AlgRS256})
+
+	body1, etag1, err := km.JWKSWithETag()
+	if err != nil {
+		t.Fatalf("JWKSWithETag error: %v", err)
+	}
+
+	if len(etag1) < 3 || etag1[0] != '"' || etag1[len(etag1)-1] != '"' {
+		t.Fatalf("ETag must be a quoted strong validator, got %s", etag1)
+	}
+
+	body2, etag2, _ := km.JWKSWithETag()
+	if etag1 != etag2 || !bytes.Equal(body1, body2) {
+		t.Fatalf("JWKS must be served from the cached document")
+	}
+
+	for i := 0; i < 10; i++ {
+		_ = km.ReloadCache()
+		if _, etag, _ := km.JWKSWithETag(); etag != etag1 {
+			t.Fatalf("ETag must not change when keys are unchanged")
+		}
+	}
+
+	_ = km.Rotate(AlgEdDSA)
+	if _, etag, _ := km.JWKSWithETag(); etag == etag1 {
+		t.Fatalf("ETag must change after rotation")
+	}
+}
+
+func TestJWKS_ReturnsCopy(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	body, _ := km.JWKS()
+	want := string(body)
+	for i := range body {
+		body[i] = 'x'
+	}
+
+	if got, _ := km.JWKS(); string(got) != want {
+		t.Fatalf("modifying a returned JWKS must not change the published one, got %s", got)
+	}
+}
+
+func TestJWKSTo_StreamsDocumentWithoutAllocations(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgRS256, AlgEdDSA})
+
+	var buf bytes.Buffer
+	n, err := km.JWKSTo(&buf)
+	if err != nil {
+		t.Fatalf("JWKSTo error: %v", err)
+	}
+
+	body, _ := km.JWKS()
+	if n != int64(len(body)) || !bytes.Equal(buf.Bytes(), body) {
+		t.Fatalf("JWKSTo must write the cached document")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = km.JWKSTo(io.Discard)
+	})
+
+	if allocs != 0 {
+		t.Fatalf("expected 0 allocations per JWKSTo call, got %v", allocs)
+	}
+}
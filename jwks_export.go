@@ -0,0 +1,107 @@
+package keys_manager
+
+import (
+	"context"
+	"time"
+)
+
+// JWKSExporter receives the published JWKS whenever its content changes,
+// for edge proxies that validate tokens from a file or a pushed keyset.
+type JWKSExporter interface {
+	ExportJWKS(ctx context.Context, jwks []byte, etag string) error
+}
+
+const (
+	jwksExportTimeout  = 30 * time.Second
+	jwksExportRetryMin = time.Second
+	jwksExportRetryMax = time.Minute
+)
+
+type jwksExport struct {
+	exporter JWKSExporter
+
+	// kick wakes the export loop after a reload; it holds at most one
+	// pending wakeup, so bursts of reloads export once.
+	kick chan struct{}
+
+	retryMin, retryMax time.Duration
+}
+
+func newJWKSExport(e JWKSExporter) *jwksExport {
+	return &jwksExport{
+		exporter: e,
+		kick:     make(chan struct{}, 1),
+		retryMin: jwksExportRetryMin,
+		retryMax: jwksExportRetryMax,
+	}
+}
+
+// queueJWKSExport asks the export loop to look at the current snapshot. It
+// never blocks, so reloads do not wait on the exporter's I/O.
+func (km *KeyManager) queueJWKSExport() {
+	e := km.jwksExport
+	if e == nil {
+		return
+	}
+
+	select {
+	case e.kick <- struct{}{}:
+	default:
+	}
+}
+
+// jwksExportLoop hands the newest JWKS to the exporter whenever it changed
+// since the last successful export. Failures are retried with backoff
+// until they succeed or a later reload supersedes them.
+func (km *KeyManager) jwksExportLoop(ctx context.Context) {
+	e := km.jwksExport
+
+	var (
+		last  string
+		delay time.Duration
+		retry *time.Timer
+		wait  <-chan time.Time
+	)
+	defer func() {
+		if retry != nil {
+			retry.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.kick:
+		case <-wait:
+		}
+
+		doc := km.snapshot().jwks
+		if doc == nil || doc.etag == last {
+			continue
+		}
+
+		exportCtx, cancel := context.WithTimeout(ctx, jwksExportTimeout)
+		err := e.exporter.ExportJWKS(exportCtx, doc.body, doc.etag)
+		cancel()
+
+		if err == nil {
+			last, delay, wait = doc.etag, 0, nil
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		km.logger().WarnContext(ctx, "jwks export failed", "err", err)
+		km.reportError(OpJWKSExport, "", err)
+
+		delay = min(max(2*delay, e.retryMin), e.retryMax)
+		if retry == nil {
+			retry = time.NewTimer(delay)
+		} else {
+			retry.Reset(delay)
+		}
+		wait = retry.C
+	}
+}
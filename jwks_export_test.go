@@ -0,0 +1,145 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	etags []string
+	fail  bool
+	block chan struct{}
+}
+
+func (e *recordingExporter) ExportJWKS(ctx context.Context, _ []byte, etag string) error {
+	if e.block != nil {
+		select {
+		case <-e.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.fail {
+		return errors.New("proxy unreachable")
+	}
+	e.etags = append(e.etags, etag)
+	return nil
+}
+
+func (e *recordingExporter) setFail(fail bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fail = fail
+}
+
+// waitExports waits until n exports were made and returns them.
+func (e *recordingExporter) waitExports(t *testing.T, n int) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		e.mu.Lock()
+		etags := append([]string(nil), e.etags...)
+		e.mu.Unlock()
+
+		if len(etags) >= n {
+			return etags
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d exports, got %d", n, len(etags))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitExported waits until etag was exported last.
+func (e *recordingExporter) waitExported(t *testing.T, etag string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		e.mu.Lock()
+		etags := append([]string(nil), e.etags...)
+		e.mu.Unlock()
+
+		if len(etags) > 0 && etags[len(etags)-1] == etag {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be exported last, got %v", etag, etags)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func fastExportRetry(km *KeyManager) {
+	km.jwksExport.retryMin = time.Millisecond
+	km.jwksExport.retryMax = 10 * time.Millisecond
+}
+
+func TestJWKSExporter_ExportsOnChange(t *testing.T) {
+	exp := &recordingExporter{}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithJWKSExporter(exp))
+	defer km.Close(context.Background())
+
+	exp.waitExports(t, 1)
+
+	_ = km.Rotate(AlgEdDSA)
+	_ = km.ReloadCache()
+
+	etags := exp.waitExports(t, 2)
+	if etag := km.snapshot().jwks.etag; etags[len(etags)-1] != etag {
+		t.Fatalf("expected current etag %s to be exported, got %v", etag, etags)
+	}
+}
+
+func TestJWKSExporter_RetriesAfterFailure(t *testing.T) {
+	exp := &recordingExporter{fail: true}
+	onError, errs := collectErrors()
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithJWKSExporter(exp), fastExportRetry, WithOnError(onError))
+	defer km.Close(context.Background())
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("export failure must not fail rotation, got %v", err)
+	}
+	if e := nextError(t, errs); e.Op != OpJWKSExport {
+		t.Fatalf("unexpected operation error: %+v", e)
+	}
+
+	exp.setFail(false)
+
+	etags := exp.waitExports(t, 1)
+	if etags[0] != km.snapshot().jwks.etag {
+		t.Fatalf("failed export must be retried without a reload, got %v", etags)
+	}
+}
+
+func TestJWKSExporter_DoesNotBlockReloads(t *testing.T) {
+	exp := &recordingExporter{block: make(chan struct{})}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithJWKSExporter(exp))
+	defer km.Close(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- km.Rotate(AlgEdDSA) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Rotate error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("rotation must not wait on a stalled exporter")
+	}
+
+	close(exp.block)
+	exp.waitExported(t, km.snapshot().jwks.etag)
+}
@@ -0,0 +1,100 @@
+package keys_manager
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GenerationHeader carries the keyset generation on JWKS responses. The
+// generation counts reloads of one process and differs between replicas,
+// so it is informational only; conditional requests use the ETag.
+const GenerationHeader = "Keyset-Generation"
+
+// NextRotationHeader carries, in RFC 3339, when the first active key of the
+// served set expires and is due for rotation. See WithJWKSRotationHint.
+const NextRotationHeader = "Keyset-Next-Rotation"
+
+// JWKSHandler serves the JWKS with its ETag and generation. A request whose
+// If-None-Match matches the ETag gets 304 Not Modified without a body.
+func (km *KeyManager) JWKSHandler() http.Handler {
+	return km.jwksHandler(func(snap *cacheSnapshot) *jwksDocument {
+		return snap.jwks
+	}, func(Purpose) bool { return true })
+}
+
+// JWKSHandlerForPurpose serves the key set of purpose alone like
+// JWKSHandler, with cache headers computed from the keys of purpose.
+func (km *KeyManager) JWKSHandlerForPurpose(purpose Purpose) http.Handler {
+	empty, _ := newJWKSDocument(nil, false)
+
+	return km.jwksHandler(func(snap *cacheSnapshot) *jwksDocument {
+		if snap.jwks == nil {
+			return nil
+		}
+		if doc := snap.purposes[purpose]; doc != nil {
+			return doc
+		}
+		return empty
+	}, func(p Purpose) bool { return p == purpose })
+}
+
+func (km *KeyManager) jwksHandler(document func(*cacheSnapshot) *jwksDocument, match func(Purpose) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snap := km.snapshot()
+		doc := document(snap)
+		if doc == nil {
+			http.Error(w, "jwks not built", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("ETag", doc.etag)
+		w.Header().Set(GenerationHeader, strconv.FormatUint(snap.generation, 10))
+		km.setJWKSCacheHeaders(w.Header(), snap, match)
+
+		if r.Header.Get("If-None-Match") == doc.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc.body)
+	})
+}
+
+func (km *KeyManager) setJWKSCacheHeaders(h http.Header, snap *cacheSnapshot, match func(Purpose) bool) {
+	if km.jwksMaxAge <= 0 && !km.jwksRotationHint {
+		return
+	}
+
+	next, planned := nextRotation(snap.active, match)
+
+	if km.jwksMaxAge > 0 {
+		maxAge := km.jwksMaxAge
+		if planned {
+			maxAge = max(min(maxAge, next.Sub(km.clock.Now())), 0)
+		}
+		h.Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(maxAge/time.Second), 10))
+	}
+
+	if km.jwksRotationHint && planned {
+		h.Set(NextRotationHeader, next.UTC().Format(time.RFC3339))
+	}
+}
+
+// nextRotation returns the earliest expiry of the published active keys
+// whose purpose satisfies match.
+func nextRotation(active map[keySlot]*CachedKey, match func(Purpose) bool) (time.Time, bool) {
+	var next time.Time
+
+	for s, ck := range active {
+		if s.alg.symmetric() || !match(s.purpose) || ck.key.ExpiresAt == nil {
+			continue
+		}
+		if next.IsZero() || ck.key.ExpiresAt.Before(next) {
+			next = *ck.key.ExpiresAt
+		}
+	}
+
+	return next, !next.IsZero()
+}
@@ -0,0 +1,132 @@
+package keys_manager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGeneration_BumpsOnlyWhenJWKSChanges(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	start := km.Generation()
+	if start != 1 {
+		t.Fatalf("expected generation 1 after the first load, got %d", start)
+	}
+
+	_ = km.ReloadCache()
+	if got := km.Generation(); got != start {
+		t.Fatalf("reload without changes must keep the generation, got %d", got)
+	}
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	if got := km.Generation(); got <= start {
+		t.Fatalf("new key must bump the generation, got %d", got)
+	}
+}
+
+func TestJWKSHandler_NotModified(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgES256})
+	h := km.JWKSHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+
+	body, etag, _ := km.JWKSWithETag()
+	if rec.Code != http.StatusOK || rec.Body.String() != string(body) {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Fatalf("expected ETag %s, got %s", etag, rec.Header().Get("ETag"))
+	}
+	if rec.Header().Get(GenerationHeader) == "" {
+		t.Fatalf("missing %s header", GenerationHeader)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/jwks.json", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d", rec.Code)
+	}
+
+	_ = km.Rotate(AlgES256)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stale ETag must get the new keyset, got %d", rec.Code)
+	}
+}
+
+func TestJWKSHandler_GenerationIsNotAValidator(t *testing.T) {
+	store := NewMockStore()
+	a, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = a.InitKeys([]Alg{AlgES256})
+	_ = a.Rotate(AlgES256)
+	b, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	rec := httptest.NewRecorder()
+	a.JWKSHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+
+	// A generation from another replica can name a different keyset.
+	req := httptest.NewRequest(http.MethodGet, "/jwks.json", nil)
+	req.Header.Set(GenerationHeader, rec.Header().Get(GenerationHeader))
+	other := httptest.NewRecorder()
+	b.JWKSHandler().ServeHTTP(other, req)
+	if other.Code != http.StatusOK {
+		t.Fatalf("generation alone must not yield 304, got %d", other.Code)
+	}
+
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	other = httptest.NewRecorder()
+	b.JWKSHandler().ServeHTTP(other, req)
+	if other.Code != http.StatusNotModified {
+		t.Fatalf("the ETag of the same keyset must validate across replicas, got %d", other.Code)
+	}
+}
+
+func TestJWKSHandler_CacheHeaders(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	sessionPolicy := func() (RotationConfig, error) { return RotationConfig{TTL: 3 * time.Hour}, nil }
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithClock(clock),
+		WithPurposePolicy("session", sessionPolicy),
+		WithJWKSCacheControl(24*time.Hour),
+		WithJWKSRotationHint(),
+	)
+	_ = km.InitKeys([]Alg{AlgES256})
+	_ = km.InitKeysWithPurpose("session", []Alg{AlgEdDSA})
+	clock.now = start.Add(30 * time.Minute)
+
+	get := func(h http.Handler) http.Header {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+		return rec.Header()
+	}
+
+	for _, tc := range []struct {
+		name     string
+		h        http.Handler
+		cache    string
+		rotation string
+	}{
+		{"all", km.JWKSHandler(), "public, max-age=1800", "2030-01-01T01:00:00Z"},
+		{"session", km.JWKSHandlerForPurpose("session"), "public, max-age=9000", "2030-01-01T03:00:00Z"},
+		{"empty", km.JWKSHandlerForPurpose("unused"), "public, max-age=86400", ""},
+	} {
+		hdr := get(tc.h)
+		if hdr.Get("Cache-Control") != tc.cache || hdr.Get(NextRotationHeader) != tc.rotation {
+			t.Fatalf("%s: unexpected headers %q, %q", tc.name, hdr.Get("Cache-Control"), hdr.Get(NextRotationHeader))
+		}
+	}
+
+	clock.now = start.Add(2 * time.Hour)
+	if got := get(km.JWKSHandler()).Get("Cache-Control"); got != "public, max-age=0" {
+		t.Fatalf("overdue rotation must not be cached, got %q", got)
+	}
+}
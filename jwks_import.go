@@ -0,0 +1,282 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// privateJWK is a JWK with its private members (RFC 7518 section 6) and
+// the status and x5c members IdPs such as Keycloak and Auth0 export.
+type privateJWK struct {
+	Kty    string   `json:"kty"`
+	Kid    string   `json:"kid"`
+	Alg    string   `json:"alg"`
+	Use    string   `json:"use"`
+	Status string   `json:"status"`
+	X5c    []string `json:"x5c"`
+
+	N string `json:"n"`
+	E string `json:"e"`
+	D string `json:"d"`
+	P string `json:"p"`
+	Q string `json:"q"`
+
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type JWKSImportOptions struct {
+	// Purpose and Labels are given to every imported key.
+	Purpose Purpose
+	Labels  map[string]string
+
+	// Activate makes the first key of each alg with status ACTIVE, or
+	// without a status, the active key of its slot. Other keys are
+	// imported inactive and only verify.
+	Activate bool
+}
+
+// SkippedJWK is a key ImportJWKS left out, with the reason.
+type SkippedJWK struct {
+	KID    string
+	Reason string
+}
+
+type JWKSImportReport struct {
+	Imported []string
+	Skipped  []SkippedJWK
+}
+
+// ImportJWKS imports the private keys of a JWKS exported by another
+// identity provider, keeping their KIDs so tokens it issued still verify.
+// The private material is re-encrypted with the Encryptor and the first
+// x5c certificate, if any, is kept when it matches the key. Encryption
+// keys, keys with status DISABLED, public-only keys and KIDs already
+// stored are skipped and reported; malformed keys fail the import before
+// anything is stored.
+func (km *KeyManager) ImportJWKS(ctx context.Context, data []byte, opts JWKSImportOptions) (*JWKSImportReport, error) {
+	var set struct {
+		Keys []privateJWK `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("import jwks: %w", err)
+	}
+
+	existing, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(existing))
+	for _, k := range existing {
+		stored[k.KID] = true
+	}
+
+	report := &JWKSImportReport{}
+	var imports []keyImport
+
+	for _, j := range set.Keys {
+		skip := func(reason string) {
+			report.Skipped = append(report.Skipped, SkippedJWK{KID: j.Kid, Reason: reason})
+		}
+
+		switch {
+		case j.Kid == "":
+			return nil, errors.New("import jwks: key without kid")
+		case j.Use != "" && j.Use != "sig":
+			skip("use " + j.Use)
+			continue
+		case strings.EqualFold(j.Status, "DISABLED"):
+			skip("disabled")
+			continue
+		case j.D == "":
+			skip("no private key")
+			continue
+		case stored[j.Kid]:
+			skip("kid already stored")
+			continue
+		}
+
+		in, err := j.keyImport()
+		if err != nil {
+			wipeImports(imports)
+			return nil, &KeyError{KID: j.Kid, Alg: Alg(j.Alg), Err: err}
+		}
+		in.purpose, in.labels = opts.Purpose, opts.Labels
+		in.activate = opts.Activate && (j.Status == "" || strings.EqualFold(j.Status, "ACTIVE"))
+
+		stored[j.Kid] = true
+		imports = append(imports, in)
+	}
+	defer wipeImports(imports)
+
+	// Inactive keys go first, and only the first active key of an alg is
+	// activated, so no import retires another one.
+	activated := make(map[Alg]bool)
+	for i := range imports {
+		if imports[i].activate {
+			imports[i].activate = !activated[imports[i].alg]
+			activated[imports[i].alg] = true
+		}
+	}
+	ordered := make([]keyImport, 0, len(imports))
+	for _, activeLast := range []bool{false, true} {
+		for _, in := range imports {
+			if in.activate == activeLast {
+				ordered = append(ordered, in)
+			}
+		}
+	}
+
+	for _, in := range ordered {
+		if _, err := km.importKey(ctx, in); err != nil {
+			return report, &KeyError{KID: in.kid, Alg: in.alg, Err: err}
+		}
+		report.Imported = append(report.Imported, in.kid)
+	}
+
+	return report, nil
+}
+
+func wipeImports(imports []keyImport) {
+	for _, in := range imports {
+		wipeSigner(in.priv)
+	}
+}
+
+func (j privateJWK) keyImport() (keyImport, error) {
+	priv, alg, err := j.privateKey()
+	if err != nil {
+		return keyImport{}, err
+	}
+
+	cert, err := j.certificate(alg, priv)
+	if err != nil {
+		wipeSigner(priv)
+		return keyImport{}, err
+	}
+
+	return keyImport{kid: j.Kid, alg: alg, priv: priv, cert: cert}, nil
+}
+
+// certificate checks the alg member and returns the first x5c
+// certificate, which must certify priv.
+func (j privateJWK) certificate(alg Alg, priv crypto.Signer) ([]byte, error) {
+	if j.Alg != "" && Alg(j.Alg) != alg {
+		return nil, fmt.Errorf("%w: %s key with alg %s", ErrUnsupportedAlg, j.Kty, j.Alg)
+	}
+	if len(j.X5c) == 0 {
+		return nil, nil
+	}
+
+	der, err := base64.StdEncoding.DecodeString(j.X5c[0])
+	if err != nil {
+		return nil, fmt.Errorf("x5c: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("x5c: %w", err)
+	}
+	pub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(priv.Public()) {
+		return nil, fmt.Errorf("x5c: %w", ErrKeyMismatch)
+	}
+
+	return der, nil
+}
+
+func (j privateJWK) privateKey() (crypto.Signer, Alg, error) {
+	d, err := base64.RawURLEncoding.DecodeString(j.D)
+	if err != nil {
+		return nil, "", fmt.Errorf("d: %w", err)
+	}
+	defer wipeBytes(d)
+
+	var (
+		priv crypto.Signer
+		alg  Alg
+	)
+
+	switch j.Kty {
+	case "RSA":
+		priv, err = j.rsaKey(d)
+		alg = AlgRS256
+
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, "", fmt.Errorf("%w: curve %q", ErrUnsupportedAlg, j.Crv)
+		}
+		priv, err = ecdsa.ParseRawPrivateKey(elliptic.P256(), d)
+		alg = AlgES256
+
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, "", fmt.Errorf("%w: curve %q", ErrUnsupportedAlg, j.Crv)
+		}
+		if len(d) != ed25519.SeedSize {
+			return nil, "", errors.New("invalid Ed25519 seed length")
+		}
+		priv = ed25519.NewKeyFromSeed(d)
+		alg = AlgEdDSA
+
+	default:
+		return nil, "", fmt.Errorf("%w: kty %q", ErrUnsupportedAlg, j.Kty)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	pub, err := jwkToPublicKey(JWK{Kty: j.Kty, Kid: j.Kid, N: j.N, E: j.E, Crv: j.Crv, X: j.X, Y: j.Y})
+	if err != nil {
+		wipeSigner(priv)
+		return nil, "", err
+	}
+	if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(priv.Public()) {
+		wipeSigner(priv)
+		return nil, "", errors.New("public members do not match the private key")
+	}
+
+	return priv, alg, nil
+}
+
+func (j privateJWK) rsaKey(d []byte) (*rsa.PrivateKey, error) {
+	var ints []*big.Int
+	for _, v := range []string{j.N, j.E, j.P, j.Q} {
+		b, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, new(big.Int).SetBytes(b))
+	}
+
+	n, e, p, q := ints[0], ints[1], ints[2], ints[3]
+	if p.Sign() == 0 || q.Sign() == 0 {
+		return nil, errors.New("rsa key without primes")
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("rsa exponent too large")
+	}
+
+	priv := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+		D:         new(big.Int).SetBytes(d),
+		Primes:    []*big.Int{p, q},
+	}
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	priv.Precompute()
+
+	return priv, nil
+}
@@ -0,0 +1,122 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func privateJWKOf(t *testing.T, kid string, priv crypto.Signer) map[string]any {
+	t.Helper()
+
+	j := map[string]any{"kid": kid}
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		j["kty"], j["alg"] = "RSA", "RS256"
+		j["n"], j["e"] = b64(k.N.Bytes()), b64(big.NewInt(int64(k.E)).Bytes())
+		j["d"], j["p"], j["q"] = b64(k.D.Bytes()), b64(k.Primes[0].Bytes()), b64(k.Primes[1].Bytes())
+	case *ecdsa.PrivateKey:
+		d, _ := k.Bytes()
+		pub, _ := k.PublicKey.Bytes()
+		j["kty"], j["crv"] = "EC", "P-256"
+		j["x"], j["y"], j["d"] = b64(pub[1:33]), b64(pub[33:]), b64(d)
+	case ed25519.PrivateKey:
+		j["kty"], j["crv"] = "OKP", "Ed25519"
+		j["x"], j["d"] = b64(k.Public().(ed25519.PublicKey)), b64(k.Seed())
+	}
+	return j
+}
+
+func TestImportJWKS(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := generatePrivateKey(AlgES256)
+	edKey, _ := generatePrivateKey(AlgEdDSA)
+	oldEd, _ := generatePrivateKey(AlgEdDSA)
+
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "idp"}, NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, edKey.Public(), edKey)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+
+	rsaJWK := privateJWKOf(t, "idp-rsa", rsaKey)
+	rsaJWK["status"] = "ACTIVE"
+	edJWK := privateJWKOf(t, "idp-ed", edKey)
+	edJWK["x5c"] = []string{base64.StdEncoding.EncodeToString(cert)}
+	passive := privateJWKOf(t, "idp-ed-old", oldEd)
+	passive["status"] = "PASSIVE"
+	enc := privateJWKOf(t, "idp-enc", ecKey)
+	enc["use"] = "enc"
+	disabled := privateJWKOf(t, "idp-disabled", ecKey)
+	disabled["status"] = "DISABLED"
+	public := privateJWKOf(t, "idp-public", ecKey)
+	delete(public, "d")
+
+	data, _ := json.Marshal(map[string]any{"keys": []any{
+		rsaJWK, edJWK, passive, enc, disabled, public, privateJWKOf(t, "idp-ec", ecKey),
+	}})
+
+	report, err := km.ImportJWKS(t.Context(), data, JWKSImportOptions{Activate: true})
+	if err != nil {
+		t.Fatalf("ImportJWKS error: %v", err)
+	}
+	if len(report.Imported) != 4 || len(report.Skipped) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	for alg, kid := range map[Alg]string{AlgRS256: "idp-rsa", AlgEdDSA: "idp-ed", AlgES256: "idp-ec"} {
+		if ck := km.activeKey(alg); ck == nil || ck.key.KID != kid {
+			t.Fatalf("expected %s active for %s, got %v", kid, alg, ck)
+		}
+	}
+	if ck := km.keyByKID("idp-ed-old"); ck == nil || ck.key.IsActive {
+		t.Fatalf("passive key must be imported inactive")
+	}
+	if ck := km.keyByKID("idp-ed"); string(ck.key.Certificate) != string(cert) {
+		t.Fatalf("x5c certificate must be kept")
+	}
+
+	digest := sha256.Sum256([]byte("issued by the old idp"))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err := km.Verify("idp-rsa", []byte("issued by the old idp"), sig); err != nil {
+		t.Fatalf("tokens of the old idp must verify under their kid: %v", err)
+	}
+
+	report, err = km.ImportJWKS(t.Context(), data, JWKSImportOptions{})
+	if err != nil || len(report.Imported) != 0 {
+		t.Fatalf("re-import must skip stored KIDs, got %+v, %v", report, err)
+	}
+}
+
+func TestImportJWKS_RejectsMismatchedKeys(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	a, _ := generatePrivateKey(AlgEdDSA)
+	b, _ := generatePrivateKey(AlgEdDSA)
+
+	j := privateJWKOf(t, "mixed", a)
+	j["x"] = privateJWKOf(t, "other", b)["x"]
+	data, _ := json.Marshal(map[string]any{"keys": []any{j}})
+
+	var keyErr *KeyError
+	if _, err := km.ImportJWKS(t.Context(), data, JWKSImportOptions{}); !errors.As(err, &keyErr) || keyErr.KID != "mixed" {
+		t.Fatalf("expected KeyError for mismatched public members, got %v", err)
+	}
+	if len(km.Keys()) != 0 {
+		t.Fatalf("nothing must be stored when a key is malformed")
+	}
+}
@@ -0,0 +1,101 @@
+// Package jwksexport provides km.JWKSExporter implementations that keep
+// edge proxies supplied with the current keyset.
+//
+// File suits proxies that read a local JWKS, such as Envoy's jwt_authn
+// filter with local_jwks pointing at a watched file, or Caddy modules
+// reading a keyset from disk. HTTP pushes the keyset to an admin or
+// control-plane endpoint that distributes it further.
+package jwksexport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+var (
+	_ km.JWKSExporter = (*File)(nil)
+	_ km.JWKSExporter = (*HTTP)(nil)
+)
+
+// File writes the JWKS to Path, replacing it atomically so readers never
+// see a partial keyset.
+type File struct {
+	Path string
+	Mode os.FileMode // defaults to 0644
+}
+
+func (f *File) ExportJWKS(_ context.Context, jwks []byte, _ string) error {
+	mode := f.Mode
+	if mode == 0 {
+		mode = 0o644
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(jwks); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// HTTP sends the JWKS as the body of a PUT to URL, with the keyset's ETag
+// in the ETag header so the receiver can ignore repeats. Any 2xx response
+// counts as delivered.
+type HTTP struct {
+	URL    string
+	Client *http.Client      // defaults to http.DefaultClient
+	Header map[string]string // extra headers, e.g. Authorization
+}
+
+func (h *HTTP) ExportJWKS(ctx context.Context, jwks []byte, etag string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, h.URL, bytes.NewReader(jwks))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/jwk-set+json")
+	req.Header.Set("ETag", etag)
+	for k, v := range h.Header {
+		req.Header.Set(k, v)
+	}
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("jwksexport: push to %s: %s", h.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package jwksexport
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+// eventually waits for cond, since exports run in the background.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store(), km.WithJWKSExporter(&File{Path: path}))
+	if err := m.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	var data []byte
+	eventually(t, func() bool {
+		data, _ = os.ReadFile(path)
+		var jwks km.JWKS
+		return json.Unmarshal(data, &jwks) == nil && len(jwks.Keys) == 1
+	})
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("unexpected mode %v", info.Mode())
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+		etags  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer push" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		etags = append(etags, r.Header.Get("ETag"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	b := keytest.New(t, 1)
+	m := b.Manager(b.Store(), km.WithJWKSExporter(&HTTP{
+		URL:    srv.URL,
+		Header: map[string]string{"Authorization": "Bearer push"},
+	}))
+	if err := m.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	jwks, _ := m.JWKS()
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		n := len(bodies)
+		return n > 0 && bodies[n-1] == string(jwks) && etags[n-1] != ""
+	})
+
+	bad := &HTTP{URL: srv.URL}
+	if err := bad.ExportJWKS(t.Context(), jwks, `"x"`); err == nil {
+		t.Fatalf("non-2xx response must fail")
+	}
+}
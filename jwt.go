@@ -0,0 +1,144 @@
+package keys_manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type jwtHeader struct {
+	Alg  Alg       `json:"alg"`
+	Kid  string    `json:"kid"`
+	Crit *[]string `json:"crit"`
+}
+
+// check rejects headers without alg or kid, and any crit header: the
+// manager implements no JWS extensions, so it cannot honour one.
+func (h *jwtHeader) check() error {
+	switch {
+	case h.Alg == "":
+		return fmt.Errorf("%w: missing alg", ErrInvalidToken)
+	case h.Kid == "":
+		return fmt.Errorf("%w: missing kid", ErrInvalidToken)
+	case h.Crit != nil:
+		return fmt.Errorf("%w: unsupported critical header %q", ErrInvalidToken, *h.Crit)
+	}
+	return nil
+}
+
+// VerifyJWT verifies a compact JWS token signed by one of the manager's
+// keys, resolving the key from the kid header, and checks the exp and nbf
+// claims allowing the configured clock skew. With WithReplayCache, a jti
+// seen before fails with ErrTokenReplayed. It returns all claims, with
+// numbers decoded as json.Number.
+func (km *KeyManager) VerifyJWT(token string) (map[string]any, error) {
+	return km.VerifyJWTCtx(context.Background(), token)
+}
+
+func (km *KeyManager) VerifyJWTCtx(ctx context.Context, token string) (map[string]any, error) {
+	header, payload, sig, ok := splitJWT(token)
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed compact serialization", ErrInvalidToken)
+	}
+
+	var h jwtHeader
+	if err := decodeJWTPart(header, &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidToken, err)
+	}
+	if err := h.check(); err != nil {
+		return nil, err
+	}
+
+	rawSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %w", ErrInvalidToken, err)
+	}
+
+	signingInput := token[:len(header)+1+len(payload)]
+	if err := km.verify(ctx, h.Kid, h.Alg, false, jwsInput, []byte(signingInput), rawSig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJWTPart(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %w", ErrInvalidToken, err)
+	}
+
+	if err := km.checkTimeClaims(claims); err != nil {
+		return nil, err
+	}
+
+	if err := km.checkReplay(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func splitJWT(token string) (header, payload, sig string, ok bool) {
+	header, rest, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", "", "", false
+	}
+	payload, sig, ok = strings.Cut(rest, ".")
+	if !ok || strings.Contains(sig, ".") {
+		return "", "", "", false
+	}
+	return header, payload, sig, true
+}
+
+func decodeJWTPart(part string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func (km *KeyManager) checkTimeClaims(claims map[string]any) error {
+	now := km.clock.Now()
+
+	exp, ok, err := numericDate(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if ok && !now.Add(-km.skew).Before(exp) {
+		return fmt.Errorf("%w: expired at %s", ErrTokenExpired, exp.Format(time.RFC3339))
+	}
+
+	nbf, ok, err := numericDate(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(km.skew).Before(nbf) {
+		return fmt.Errorf("%w: valid from %s", ErrTokenNotYetValid, nbf.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func numericDate(claims map[string]any, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%w: %s is not a number", ErrInvalidToken, name)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s: %w", ErrInvalidToken, name, err)
+	}
+
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true, nil
+}
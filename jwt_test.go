@@ -0,0 +1,118 @@
+package keys_manager
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signTestJWT(t *testing.T, km *KeyManager, alg Alg, claims string) string {
+	t.Helper()
+
+	var input string
+	sig, err := km.Sign(alg, func(kid string) ([]byte, error) {
+		header := fmt.Sprintf(`{"alg":%q,"kid":%q,"typ":"JWT"}`, alg, kid)
+		input = base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(claims))
+		return []byte(input), nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyJWT(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(2_000_000_000, 0)}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock), WithSkew(30*time.Second))
+	_ = km.InitKeys([]Alg{AlgES256, AlgEdDSA})
+
+	for _, alg := range []Alg{AlgES256, AlgEdDSA} {
+		token := signTestJWT(t, km, alg, `{"sub":"alice","exp":2000000060,"nbf":2000000010}`)
+
+		claims, err := km.VerifyJWT(token)
+		if err != nil {
+			t.Fatalf("%s: VerifyJWT error: %v", alg, err)
+		}
+		if claims["sub"] != "alice" {
+			t.Fatalf("%s: unexpected claims %v", alg, claims)
+		}
+	}
+
+	token := signTestJWT(t, km, AlgEdDSA, `{"exp":2000000000}`)
+	if _, err := km.VerifyJWT(token); err != nil {
+		t.Fatalf("token expired within skew must verify, got %v", err)
+	}
+
+	token = signTestJWT(t, km, AlgEdDSA, `{"exp":1999999960}`)
+	if _, err := km.VerifyJWT(token); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+
+	token = signTestJWT(t, km, AlgEdDSA, `{"nbf":2000000040}`)
+	if _, err := km.VerifyJWT(token); !errors.Is(err, ErrTokenNotYetValid) {
+		t.Fatalf("expected ErrTokenNotYetValid, got %v", err)
+	}
+}
+
+func TestVerifyJWT_Rejects(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	token := signTestJWT(t, km, AlgEdDSA, `{"sub":"alice"}`)
+	parts := strings.Split(token, ".")
+
+	kid := km.activeKey(AlgEdDSA).key.KID
+	swapped := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","kid":"` + kid + `"}`))
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`))
+
+	cases := map[string]struct {
+		token string
+		want  error
+	}{
+		"malformed":    {"abc.def", ErrInvalidToken},
+		"alg mismatch": {swapped + "." + parts[1] + "." + parts[2], ErrInvalidToken},
+		"no kid":       {base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`)) + "." + parts[1] + "." + parts[2], ErrInvalidToken},
+		"unknown kid":  {base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA","kid":"nope"}`)) + "." + parts[1] + "." + parts[2], ErrKeyNotFound},
+	}
+	for name, tc := range cases {
+		if _, err := km.VerifyJWT(tc.token); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+
+	if _, err := km.VerifyJWT(parts[0] + "." + tampered + "." + parts[2]); err == nil {
+		t.Fatalf("tampered claims must not verify")
+	}
+}
+
+func TestVerifyJWT_RejectsUnsupportedHeaders(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	headers := map[string]string{
+		"no alg":       `{"kid":%q}`,
+		"unknown crit": `{"alg":"EdDSA","kid":%q,"crit":["exp-ext"],"exp-ext":true}`,
+		"empty crit":   `{"alg":"EdDSA","kid":%q,"crit":[]}`,
+	}
+	for name, header := range headers {
+		var input string
+		sig, err := km.Sign(AlgEdDSA, func(kid string) ([]byte, error) {
+			input = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(header, kid))) + "." +
+				base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`))
+			return []byte(input), nil
+		})
+		if err != nil {
+			t.Fatalf("Sign error: %v", err)
+		}
+
+		token := input + "." + base64.RawURLEncoding.EncodeToString(sig)
+		if _, err := km.VerifyJWT(token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package kafkakm publishes keys_manager lifecycle events to a Kafka topic.
+//
+// It does not depend on a particular Kafka client. Producer is small
+// enough to wrap any of them; with segmentio/kafka-go:
+//
+//	type writer struct{ w *kafka.Writer }
+//
+//	func (p writer) Produce(ctx context.Context, topic string, key, value []byte) error {
+//		return p.w.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: value})
+//	}
+package kafkakm
+
+import (
+	"context"
+	"encoding/json"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Producer writes a single record and returns once the client considers
+// it delivered.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Publisher is a km.EventPublisher writing JSON encoded events to topic.
+// Records are keyed by alg and purpose, so the events of one key slot stay
+// ordered within a partition.
+type Publisher struct {
+	p     Producer
+	topic string
+}
+
+func NewPublisher(p Producer, topic string) *Publisher {
+	return &Publisher{p: p, topic: topic}
+}
+
+func (p *Publisher) Publish(ctx context.Context, ev km.LifecycleEvent) error {
+	value, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	key := string(ev.Alg)
+	if ev.Purpose != km.PurposeDefault {
+		key += "/" + string(ev.Purpose)
+	}
+
+	return p.p.Produce(ctx, p.topic, []byte(key), value)
+}
+
+// Decode parses a record value written by Publisher, for consumers that
+// reload or invalidate caches on events.
+func Decode(value []byte) (km.LifecycleEvent, error) {
+	var ev km.LifecycleEvent
+	err := json.Unmarshal(value, &ev)
+	return ev, err
+}
@@ -0,0 +1,67 @@
+package kafkakm
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+type record struct {
+	topic      string
+	key, value []byte
+}
+
+type fakeProducer struct {
+	mu      sync.Mutex
+	records []record
+}
+
+func (p *fakeProducer) Produce(_ context.Context, topic string, key, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = append(p.records, record{topic: topic, key: key, value: value})
+	return nil
+}
+
+func TestPublisher_RotationEvents(t *testing.T) {
+	b := keytest.New(t, 1)
+	prod := &fakeProducer{}
+
+	m := b.Manager(b.Store(), km.WithEventPublisher(NewPublisher(prod, "keys")))
+
+	if err := m.RotateWithPurpose(km.AlgEdDSA, km.PurposeAccess); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	if err := m.RotateWithPurpose(km.AlgEdDSA, km.PurposeAccess); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	if len(prod.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(prod.records))
+	}
+
+	first, err := Decode(prod.records[0].value)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	rec := prod.records[1]
+	if rec.topic != "keys" || string(rec.key) != "EdDSA/access" {
+		t.Fatalf("unexpected record routing: topic %q key %q", rec.topic, rec.key)
+	}
+
+	ev, err := Decode(rec.value)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ev.Type != km.EventKeyRotated || ev.PreviousKID != first.KID || ev.KID == first.KID {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if !ev.At.Equal(keytest.Epoch) {
+		t.Fatalf("event time must come from the manager clock, got %v", ev.At)
+	}
+}
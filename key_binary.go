@@ -0,0 +1,70 @@
+package keys_manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// KeyEncodingVersion is written by Key.MarshalBinary. It is bumped whenever
+// a field is added to Key, so older readers refuse the data instead of
+// dropping the new field.
+const KeyEncodingVersion = 2
+
+type keyBinary struct {
+	Version int `json:"version"`
+	snapshotKey
+}
+
+// MarshalBinary returns the canonical storage form of k, encrypted
+// material included: versioned JSON with times in UTC, so equal keys
+// encode to equal bytes. Stores should persist keys in this form; unlike
+// MarshalJSON it is not safe to log.
+func (k Key) MarshalBinary() ([]byte, error) {
+	ek := k.EncryptedKey
+	if ek == nil {
+		ek = &EncryptedKey{}
+	}
+
+	sk := toSnapshotKey(&k, ek)
+	sk.CreatedAt = sk.CreatedAt.UTC()
+	sk.UpdatedAt = sk.UpdatedAt.UTC()
+	sk.NotBefore = utcPtr(sk.NotBefore)
+	sk.ExpiresAt = utcPtr(sk.ExpiresAt)
+	sk.RevokedAt = utcPtr(sk.RevokedAt)
+
+	return json.Marshal(keyBinary{Version: KeyEncodingVersion, snapshotKey: sk})
+}
+
+// UnmarshalBinary decodes data written by MarshalBinary. Data from a newer
+// encoding version, or with fields this version does not know, fails with
+// ErrKeyEncoding. Data without a version is read as version 1.
+func (k *Key) UnmarshalBinary(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var kb keyBinary
+	if err := dec.Decode(&kb); err != nil {
+		return fmt.Errorf("%w: %w", ErrKeyEncoding, err)
+	}
+	if kb.Version > KeyEncodingVersion {
+		return fmt.Errorf("%w: version %d is newer than %d", ErrKeyEncoding, kb.Version, KeyEncodingVersion)
+	}
+
+	var ek *EncryptedKey
+	if kb.Nonce != nil || kb.Ciphertext != nil {
+		ek = &EncryptedKey{Nonce: kb.Nonce, Ciphertext: kb.Ciphertext}
+	}
+
+	*k = *kb.key(ek)
+	return nil
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
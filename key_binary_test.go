@@ -0,0 +1,99 @@
+package keys_manager
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyBinary_RoundTrip(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*3600)
+	at := func(sec int64) *time.Time {
+		v := time.Unix(sec, 0).In(loc)
+		return &v
+	}
+
+	k := Key{
+		KID:          "k1",
+		Alg:          AlgES256,
+		Purpose:      "session",
+		Labels:       map[string]string{"env": "prod", "service": "billing"},
+		Environment:  "prod",
+		IsActive:     true,
+		Pending:      true,
+		Staged:       true,
+		Priority:     3,
+		Weight:       10,
+		CreatedAt:    *at(1000),
+		UpdatedAt:    *at(2000),
+		NotBefore:    at(1000),
+		ExpiresAt:    at(5000),
+		RevokedAt:    at(4000),
+		EncryptedKey: &EncryptedKey{Nonce: []byte{1, 2}, Ciphertext: []byte{3, 4}},
+		PublicKey:    []byte{5},
+		Certificate:  []byte{6},
+		Attestation:  &Attestation{Origin: "hsm", ProtectionLevel: "hardware"},
+		Escrow:       []byte{8},
+	}
+
+	// Every Key field must be covered above and by the encoding; a new
+	// field needs both, and a bump of KeyEncodingVersion.
+	if n := reflect.TypeOf(k).NumField(); n != 20 {
+		t.Fatalf("Key has %d fields, update MarshalBinary and this test", n)
+	}
+
+	data, err := k.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary error: %v", err)
+	}
+	if !strings.Contains(string(data), `"version":2`) {
+		t.Fatalf("encoding must carry its version: %s", data)
+	}
+
+	var got Key
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary error: %v", err)
+	}
+
+	again, _ := got.MarshalBinary()
+	if string(again) != string(data) {
+		t.Fatalf("encoding must be canonical:\n%s\n%s", data, again)
+	}
+
+	if !got.CreatedAt.Equal(k.CreatedAt) || !got.RevokedAt.Equal(*k.RevokedAt) {
+		t.Fatalf("times must survive the round trip")
+	}
+	got.CreatedAt, got.UpdatedAt = k.CreatedAt, k.UpdatedAt
+	got.NotBefore, got.ExpiresAt, got.RevokedAt = k.NotBefore, k.ExpiresAt, k.RevokedAt
+	if !reflect.DeepEqual(got, k) {
+		t.Fatalf("round trip mismatch:\n%+v\n%+v", got, k)
+	}
+}
+
+func TestKeyBinary_NoEncryptedKey(t *testing.T) {
+	data, _ := Key{KID: "pub", Alg: AlgEdDSA, PublicKey: []byte{1}}.MarshalBinary()
+
+	var got Key
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary error: %v", err)
+	}
+	if got.EncryptedKey != nil {
+		t.Fatalf("expected no encrypted key, got %v", got.EncryptedKey)
+	}
+}
+
+func TestKeyBinary_RejectsUnknownData(t *testing.T) {
+	var k Key
+
+	if err := k.UnmarshalBinary([]byte(`{"version":3,"kid":"k1"}`)); !errors.Is(err, ErrKeyEncoding) {
+		t.Fatalf("expected ErrKeyEncoding for a newer version, got %v", err)
+	}
+	if err := k.UnmarshalBinary([]byte(`{"version":1,"kid":"k1","rotation_group":"a"}`)); !errors.Is(err, ErrKeyEncoding) {
+		t.Fatalf("expected ErrKeyEncoding for an unknown field, got %v", err)
+	}
+	if err := k.UnmarshalBinary([]byte(`{"kid":"legacy","alg":"EdDSA","is_active":true}`)); err != nil || k.KID != "legacy" {
+		t.Fatalf("unversioned data must be read as version 1, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// keyJSON is the log-safe JSON form of Key. Encrypted and escrowed
+// material is never written, only whether it is present.
+type keyJSON struct {
+	KID          string            `json:"kid"`
+	Alg          Alg               `json:"alg"`
+	Purpose      Purpose           `json:"purpose,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Environment  string            `json:"environment,omitempty"`
+	IsActive     bool              `json:"is_active"`
+	Pending      bool              `json:"pending,omitempty"`
+	Staged       bool              `json:"staged,omitempty"`
+	Priority     int               `json:"priority,omitempty"`
+	Weight       int               `json:"weight,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at,omitzero"`
+	NotBefore    *time.Time        `json:"not_before,omitempty"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time        `json:"revoked_at,omitempty"`
+	EncryptedKey string            `json:"encrypted_key,omitempty"`
+	PublicKey    []byte            `json:"public_key,omitempty"`
+	Certificate  []byte            `json:"certificate,omitempty"`
+	Attestation  *Attestation      `json:"attestation,omitempty"`
+	Escrow       string            `json:"escrow,omitempty"`
+}
+
+func (k Key) MarshalJSON() ([]byte, error) {
+	out := keyJSON{
+		KID:         k.KID,
+		Alg:         k.Alg,
+		Purpose:     k.Purpose,
+		Labels:      k.Labels,
+		Environment: k.Environment,
+		IsActive:    k.IsActive,
+		Pending:     k.Pending,
+		Staged:      k.Staged,
+		Priority:    k.Priority,
+		Weight:      k.Weight,
+		CreatedAt:   k.CreatedAt,
+		UpdatedAt:   k.UpdatedAt,
+		NotBefore:   k.NotBefore,
+		ExpiresAt:   k.ExpiresAt,
+		RevokedAt:   k.RevokedAt,
+		PublicKey:   k.PublicKey,
+		Certificate: k.Certificate,
+		Attestation: k.Attestation,
+	}
+
+	if k.EncryptedKey != nil {
+		out.EncryptedKey = redacted
+	}
+	if len(k.Escrow) > 0 {
+		out.Escrow = redacted
+	}
+
+	return json.Marshal(out)
+}
+
+func (e EncryptedKey) String() string {
+	return redacted
+}
+
+func (e EncryptedKey) GoString() string {
+	return "EncryptedKey{" + redacted + "}"
+}
@@ -0,0 +1,74 @@
+package keys_manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyMarshalJSON_RedactsEncryptedKey(t *testing.T) {
+	secret := []byte("very-secret-ciphertext")
+	nonce := []byte("nonce-bytes!")
+	escrow := []byte("wrapped-private-key")
+
+	exp := time.Now().Add(time.Hour)
+	k := &Key{
+		KID:          "k1",
+		Alg:          AlgES256,
+		IsActive:     true,
+		CreatedAt:    time.Now(),
+		ExpiresAt:    &exp,
+		EncryptedKey: &EncryptedKey{Nonce: nonce, Ciphertext: secret},
+		Escrow:       escrow,
+	}
+
+	for _, v := range []any{k, *k, []*Key{k}} {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal error: %v", err)
+		}
+
+		for _, leak := range [][]byte{secret, nonce, escrow} {
+			encoded, _ := json.Marshal(leak)
+			if bytes.Contains(raw, leak) || bytes.Contains(raw, encoded[1:len(encoded)-1]) {
+				t.Fatalf("JSON leaks key material: %s", raw)
+			}
+		}
+
+		if !bytes.Contains(raw, []byte(`"encrypted_key":"[REDACTED]"`)) || !bytes.Contains(raw, []byte(`"escrow":"[REDACTED]"`)) {
+			t.Fatalf("expected redaction marker in %s", raw)
+		}
+		if !bytes.Contains(raw, []byte(`"kid":"k1"`)) {
+			t.Fatalf("expected metadata in %s", raw)
+		}
+	}
+}
+
+func TestEncryptedKeyFormatting_Redacted(t *testing.T) {
+	e := EncryptedKey{Nonce: []byte("nonce"), Ciphertext: []byte("ciphertext")}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+		out := fmt.Sprintf(verb, e)
+		if strings.Contains(out, "ciphertext") || strings.Contains(out, "110") {
+			t.Fatalf("%s leaks key material: %s", verb, out)
+		}
+	}
+}
+
+func TestKeyInfoMarshalJSON(t *testing.T) {
+	info := KeyInfo{KID: "k1", Alg: AlgRS256, State: KeyStateActive, SignCount: 3}
+
+	raw, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	for _, want := range []string{`"kid":"k1"`, `"alg":"RS256"`, `"state":"active"`, `"sign_count":3`} {
+		if !bytes.Contains(raw, []byte(want)) {
+			t.Fatalf("expected %s in %s", want, raw)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package keys_manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func refreshPolicy() (RotationConfig, error) {
+	return RotationConfig{TTL: 30 * 24 * time.Hour}, nil
+}
+
+func TestKeyLines_IndependentTTLAndJWKS(t *testing.T) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithPurposePolicy(PurposeRefresh, refreshPolicy))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	for _, p := range []Purpose{PurposeAccess, PurposeRefresh} {
+		if err := km.InitKeysWithPurpose(p, []Alg{AlgES256}); err != nil {
+			t.Fatalf("InitKeysWithPurpose(%s) error: %v", p, err)
+		}
+	}
+
+	access := km.activeKeyFor(t.Context(), keySlot{alg: AlgES256, purpose: PurposeAccess}).key
+	refresh := km.activeKeyFor(t.Context(), keySlot{alg: AlgES256, purpose: PurposeRefresh}).key
+
+	if ttl := access.ExpiresAt.Sub(access.CreatedAt); ttl != time.Hour {
+		t.Fatalf("access keys must use the manager policy, got TTL %v", ttl)
+	}
+	if ttl := refresh.ExpiresAt.Sub(refresh.CreatedAt); ttl != 30*24*time.Hour {
+		t.Fatalf("refresh keys must use their own policy, got TTL %v", ttl)
+	}
+
+	body, _, err := km.JWKSForPurpose(PurposeRefresh)
+	if err != nil {
+		t.Fatalf("JWKSForPurpose error: %v", err)
+	}
+	if !strings.Contains(string(body), refresh.KID) || strings.Contains(string(body), access.KID) {
+		t.Fatalf("refresh JWKS must hold only refresh keys: %s", body)
+	}
+
+	body, _, _ = km.JWKSForPurpose(PurposeWebhook)
+	if string(body) != `{"keys":[]}` {
+		t.Fatalf("purpose without keys must yield an empty set, got %s", body)
+	}
+}
+
+func TestKeyLines_SeparateRotationSchedules(t *testing.T) {
+	store := NewMockStore()
+
+	past := time.Now().Add(-time.Minute)
+	for kid, p := range map[string]Purpose{"access": PurposeAccess, "refresh": PurposeRefresh} {
+		priv, _ := generatePrivateKey(AlgEdDSA)
+		k := makeTestKey(kid, AlgEdDSA, true, &past, MockEncryptor{}, priv)
+		k.Purpose = p
+		_ = store.Save(k)
+	}
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy,
+		WithRotationSchedule(time.Hour),
+		WithPurposeRotationSchedule(PurposeAccess, 5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	waitFor(t, func() bool {
+		ck := km.activeKeyFor(t.Context(), keySlot{alg: AlgEdDSA, purpose: PurposeAccess})
+		return ck != nil && ck.key.KID != "access"
+	})
+
+	if ck := km.activeKeyFor(t.Context(), keySlot{alg: AlgEdDSA, purpose: PurposeRefresh}); ck.key.KID != "refresh" {
+		t.Fatalf("refresh keys must follow their own schedule, got %s", ck.key.KID)
+	}
+}
@@ -0,0 +1,101 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"io"
+)
+
+// keyPool holds private keys generated ahead of time so Rotate does not
+// pay for key generation on the request path.
+type keyPool struct {
+	ready map[Alg]chan crypto.Signer
+}
+
+func (p *keyPool) setDepth(alg Alg, depth int) {
+	if p.ready == nil {
+		p.ready = make(map[Alg]chan crypto.Signer)
+	}
+	if depth <= 0 {
+		delete(p.ready, alg)
+		return
+	}
+	p.ready[alg] = make(chan crypto.Signer, depth)
+}
+
+// take returns a pooled key, or nil when the pool for alg is empty or
+// not configured.
+func (p *keyPool) take(alg Alg) crypto.Signer {
+	select {
+	case k := <-p.ready[alg]:
+		return k
+	default:
+		return nil
+	}
+}
+
+// drain empties every pool, passing each discarded key to fn.
+func (p *keyPool) drain(fn func(crypto.Signer)) {
+	for _, ready := range p.ready {
+		for {
+			select {
+			case k := <-ready:
+				fn(k)
+				continue
+			default:
+			}
+			break
+		}
+	}
+}
+
+func (p *keyPool) fill(ctx context.Context, alg Alg, r io.Reader) error {
+	ready := p.ready[alg]
+
+	for {
+		priv, err := generatePrivateKeyFrom(r, alg)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case ready <- priv:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// newKeyMaterial returns the PKCS#8 private key and PKIX public key for a
+// new key of alg, or the raw secret and no public key for symmetric algs.
+func (km *KeyManager) newKeyMaterial(alg Alg) (priv, pub []byte, err error) {
+	if alg.symmetric() {
+		secret, err := generateSecret(km.entropy(), alg)
+		return secret, nil, err
+	}
+
+	signer, err := km.newPrivateKey(alg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	priv, err = marshalPKCS8(signer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pub, err = marshalPublicKey(signer.Public())
+	if err != nil {
+		wipeBytes(priv)
+		return nil, nil, err
+	}
+
+	return priv, pub, nil
+}
+
+func (km *KeyManager) newPrivateKey(alg Alg) (crypto.Signer, error) {
+	if priv := km.keyPool.take(alg); priv != nil {
+		return priv, nil
+	}
+	return generatePrivateKeyFrom(km.entropy(), alg)
+}
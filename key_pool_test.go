@@ -0,0 +1,38 @@
+package keys_manager
+
+import "testing"
+
+func TestKeyPool_RotateUsesPooledKey(t *testing.T) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithKeyPool(AlgEdDSA, 2))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	ready := km.keyPool.ready[AlgEdDSA]
+	waitFor(t, func() bool { return len(ready) == 2 })
+
+	// Stop the filler so the pool is not topped up behind our back.
+	km.bg.stop()
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if len(ready) != 1 {
+		t.Fatalf("expected rotate to take a pooled key, pool has %d", len(ready))
+	}
+}
+
+func TestKeyPool_EmptyFallsBackToGenerate(t *testing.T) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.Rotate(AlgES256); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if km.activeKey(AlgES256) == nil {
+		t.Fatalf("expected active key after rotate")
+	}
+}
@@ -0,0 +1,274 @@
+// Package keytest provides deterministic fixtures for tests of code that
+// depends on keys_manager: seeded key material, a controllable clock, and
+// helpers to assemble stores and managers.
+package keytest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Epoch is the initial time of clocks created by New.
+var Epoch = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type Builder struct {
+	tb    testing.TB
+	rng   *rand.ChaCha8
+	clock *Clock
+	enc   km.Encryptor
+	ttl   time.Duration
+}
+
+// New returns a Builder whose key material is fully determined by seed.
+// Keys are encrypted with a MockEncryptor and timestamps come from a Clock
+// starting at Epoch.
+func New(tb testing.TB, seed uint64) *Builder {
+	var s [32]byte
+	binary.LittleEndian.PutUint64(s[:], seed)
+
+	return &Builder{
+		tb:    tb,
+		rng:   rand.NewChaCha8(s),
+		clock: NewClock(Epoch),
+		enc:   km.MockEncryptor{},
+		ttl:   time.Hour,
+	}
+}
+
+func (b *Builder) Clock() *Clock {
+	return b.clock
+}
+
+func (b *Builder) Encryptor() km.Encryptor {
+	return b.enc
+}
+
+func (b *Builder) WithEncryptor(enc km.Encryptor) *Builder {
+	b.enc = enc
+	return b
+}
+
+func (b *Builder) WithTTL(ttl time.Duration) *Builder {
+	b.ttl = ttl
+	return b
+}
+
+func (b *Builder) Policy() km.RotationPolicy {
+	ttl := b.ttl
+	return func() (km.RotationConfig, error) {
+		return km.RotationConfig{TTL: ttl}, nil
+	}
+}
+
+type KeyOption func(*km.Key)
+
+func Inactive() KeyOption {
+	return func(k *km.Key) { k.IsActive = false }
+}
+
+func ExpiresAt(t time.Time) KeyOption {
+	return func(k *km.Key) { k.ExpiresAt = &t }
+}
+
+func CreatedAt(t time.Time) KeyOption {
+	return func(k *km.Key) { k.CreatedAt = t }
+}
+
+func WithPurpose(p km.Purpose) KeyOption {
+	return func(k *km.Key) { k.Purpose = p }
+}
+
+func WithLabels(labels map[string]string) KeyOption {
+	return func(k *km.Key) { k.Labels = labels }
+}
+
+// Key builds an active key created now and expiring after the builder's
+// TTL, with encrypted private and plain public material filled in.
+func (b *Builder) Key(kid string, alg km.Alg, opts ...KeyOption) *km.Key {
+	b.tb.Helper()
+
+	priv := b.PrivateKey(alg)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		b.tb.Fatalf("keytest: marshal pkcs8: %v", err)
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		b.tb.Fatalf("keytest: marshal pkix: %v", err)
+	}
+
+	encrypted, err := b.enc.Encrypt(der)
+	if err != nil {
+		b.tb.Fatalf("keytest: encrypt: %v", err)
+	}
+
+	now := b.clock.Now()
+	exp := now.Add(b.ttl)
+
+	k := &km.Key{
+		KID:          kid,
+		Alg:          alg,
+		IsActive:     true,
+		CreatedAt:    now,
+		ExpiresAt:    &exp,
+		EncryptedKey: encrypted,
+		PublicKey:    pub,
+	}
+
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	return k
+}
+
+func (b *Builder) Store(keys ...*km.Key) *km.MockStore {
+	b.tb.Helper()
+
+	store := km.NewMockStore()
+	for _, k := range keys {
+		if err := store.Save(k); err != nil {
+			b.tb.Fatalf("keytest: save %s: %v", k.KID, err)
+		}
+	}
+
+	return store
+}
+
+// Manager builds a KeyManager over store using the builder's encryptor,
+// policy and clock. Additional options are applied after those defaults.
+// The manager is closed when the test ends.
+func (b *Builder) Manager(store km.Store, opts ...km.Option) *km.KeyManager {
+	b.tb.Helper()
+
+	opts = append([]km.Option{km.WithClock(b.clock)}, opts...)
+
+	m, err := km.NewKeyManager(store, b.enc, b.Policy(), opts...)
+	if err != nil {
+		b.tb.Fatalf("keytest: new manager: %v", err)
+	}
+	b.tb.Cleanup(func() { _ = m.Close(context.Background()) })
+
+	return m
+}
+
+// PrivateKey derives a private key for alg from the builder's seeded stream.
+func (b *Builder) PrivateKey(alg km.Alg) crypto.Signer {
+	b.tb.Helper()
+
+	switch alg {
+	case km.AlgRS256:
+		return b.rsaKey(2048)
+
+	case km.AlgES256:
+		buf := make([]byte, 32)
+		for {
+			b.rng.Read(buf)
+			priv, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), buf)
+			if err == nil {
+				return priv
+			}
+		}
+
+	case km.AlgEdDSA:
+		seed := make([]byte, ed25519.SeedSize)
+		b.rng.Read(seed)
+		return ed25519.NewKeyFromSeed(seed)
+	}
+
+	b.tb.Fatalf("keytest: %v", fmt.Errorf("%w: %s", km.ErrUnsupportedAlg, alg))
+	return nil
+}
+
+func (b *Builder) rsaKey(bits int) *rsa.PrivateKey {
+	e := big.NewInt(65537)
+	one := big.NewInt(1)
+
+	for {
+		p := b.prime(bits / 2)
+		q := b.prime(bits / 2)
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(p, q)
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			continue
+		}
+
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+
+		if err := key.Validate(); err != nil {
+			continue
+		}
+		key.Precompute()
+
+		return key
+	}
+}
+
+func (b *Builder) prime(bits int) *big.Int {
+	buf := make([]byte, bits/8)
+
+	for {
+		b.rng.Read(buf)
+		// Set the two top bits so p*q has the full bit length.
+		buf[0] |= 0xC0
+		buf[len(buf)-1] |= 1
+
+		p := new(big.Int).SetBytes(buf)
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package keytest
+
+import (
+	"bytes"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+func TestBuilder_DeterministicKeys(t *testing.T) {
+	for _, alg := range []km.Alg{km.AlgRS256, km.AlgES256, km.AlgEdDSA} {
+		k1 := New(t, 42).Key("k", alg)
+		k2 := New(t, 42).Key("k", alg)
+		k3 := New(t, 43).Key("k", alg)
+
+		if !bytes.Equal(k1.PublicKey, k2.PublicKey) {
+			t.Fatalf("%s: same seed must produce the same key", alg)
+		}
+		if bytes.Equal(k1.PublicKey, k3.PublicKey) {
+			t.Fatalf("%s: different seeds must produce different keys", alg)
+		}
+
+		if _, err := x509.ParsePKIXPublicKey(k1.PublicKey); err != nil {
+			t.Fatalf("%s: invalid public key: %v", alg, err)
+		}
+	}
+}
+
+func TestBuilder_ManagerSignsAndVerifies(t *testing.T) {
+	b := New(t, 1)
+
+	store := b.Store(
+		b.Key("active", km.AlgEdDSA),
+		b.Key("old", km.AlgEdDSA, Inactive(), CreatedAt(Epoch.Add(-time.Hour))),
+	)
+	m := b.Manager(store)
+
+	data := []byte("payload")
+	var kid string
+	sig, err := m.Sign(km.AlgEdDSA, func(k string) ([]byte, error) {
+		kid = k
+		return data, nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	if kid != "active" {
+		t.Fatalf("expected active key, got %s", kid)
+	}
+
+	if err := m.Verify(kid, data, sig); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+
+	if len(m.Keys()) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(m.Keys()))
+	}
+}
+
+func TestBuilder_ClockDrivesRotation(t *testing.T) {
+	b := New(t, 7)
+	store := b.Store(b.Key("k1", km.AlgES256))
+	m := b.Manager(store)
+
+	if err := m.RotateExpired(); err != nil {
+		t.Fatalf("RotateExpired error: %v", err)
+	}
+	if store.RotateCount != 0 {
+		t.Fatalf("key must not rotate before TTL")
+	}
+
+	b.Clock().Advance(2 * time.Hour)
+
+	if err := m.RotateExpired(); err != nil {
+		t.Fatalf("RotateExpired error: %v", err)
+	}
+	if store.RotateCount != 1 {
+		t.Fatalf("expected rotation after TTL, got %d", store.RotateCount)
+	}
+}
@@ -0,0 +1,129 @@
+package keys_manager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+var errKeyWrap = errors.New("key unwrap failed")
+
+// aesKeyWrap implements the RFC 3394 wrapping function W with initial
+// value iv. plaintext must be at least 16 bytes and a multiple of 8.
+func aesKeyWrap(block cipher.Block, iv [8]byte, plaintext []byte) []byte {
+	n := len(plaintext) / 8
+
+	out := make([]byte, 8+len(plaintext))
+	copy(out, iv[:])
+	copy(out[8:], plaintext)
+
+	var b [16]byte
+	for j := range 6 {
+		for i := 1; i <= n; i++ {
+			copy(b[:8], out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			block.Encrypt(b[:], b[:])
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[8*i:8*i+8], b[8:])
+		}
+	}
+
+	return out
+}
+
+// aesKeyUnwrap inverts aesKeyWrap, returning the initial value it found
+// alongside the plaintext for the caller to check.
+func aesKeyUnwrap(block cipher.Block, ciphertext []byte) (iv [8]byte, plaintext []byte, err error) {
+	if len(ciphertext) < 24 || len(ciphertext)%8 != 0 {
+		return iv, nil, errKeyWrap
+	}
+	n := len(ciphertext)/8 - 1
+
+	a := binary.BigEndian.Uint64(ciphertext[:8])
+	r := make([]byte, 8*n)
+	copy(r, ciphertext[8:])
+
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			binary.BigEndian.PutUint64(b[:8], a^uint64(n*j+i))
+			copy(b[8:], r[8*(i-1):8*i])
+			block.Decrypt(b[:], b[:])
+
+			a = binary.BigEndian.Uint64(b[:8])
+			copy(r[8*(i-1):8*i], b[8:])
+		}
+	}
+
+	binary.BigEndian.PutUint64(iv[:], a)
+	return iv, r, nil
+}
+
+// keyWrapPad wraps key of any length under kek with AES Key Wrap with
+// Padding (RFC 5649).
+func keyWrapPad(kek, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key wrap: empty key")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := [8]byte{0xA6, 0x59, 0x59, 0xA6}
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(key)))
+
+	padded := make([]byte, (len(key)+7)/8*8)
+	copy(padded, key)
+	defer wipeBytes(padded)
+
+	if len(padded) == 8 {
+		out := make([]byte, 16)
+		copy(out, iv[:])
+		copy(out[8:], padded)
+		block.Encrypt(out, out)
+		return out, nil
+	}
+
+	return aesKeyWrap(block, iv, padded), nil
+}
+
+func keyUnwrapPad(kek, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv [8]byte
+	var padded []byte
+	switch {
+	case len(wrapped) == 16:
+		b := make([]byte, 16)
+		block.Decrypt(b, wrapped)
+		copy(iv[:], b[:8])
+		padded = b[8:]
+	default:
+		if iv, padded, err = aesKeyUnwrap(block, wrapped); err != nil {
+			return nil, err
+		}
+	}
+
+	mli := int(binary.BigEndian.Uint32(iv[4:]))
+	valid := subtle.ConstantTimeCompare(iv[:4], []byte{0xA6, 0x59, 0x59, 0xA6}) == 1 &&
+		mli > len(padded)-8 && mli <= len(padded)
+	if valid {
+		for _, c := range padded[mli:] {
+			valid = valid && c == 0
+		}
+	}
+	if !valid {
+		wipeBytes(padded)
+		return nil, errKeyWrap
+	}
+
+	return padded[:mli], nil
+}
@@ -0,0 +1,39 @@
+package keys_manager
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestKeyWrapPad_RFC5649Vectors(t *testing.T) {
+	kek, _ := hex.DecodeString("5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+
+	vectors := []struct{ key, wrapped string }{
+		{"c37b7e6492584340bed12207808941155068f738", "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a"},
+		{"466f7250617369", "afbeb0f07dfbf5419200f2ccb50bb24f"},
+	}
+
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		want, _ := hex.DecodeString(v.wrapped)
+
+		got, err := keyWrapPad(kek, key)
+		if err != nil {
+			t.Fatalf("keyWrapPad error: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("wrap %x: expected %x, got %x", key, want, got)
+		}
+
+		unwrapped, err := keyUnwrapPad(kek, got)
+		if err != nil || !bytes.Equal(unwrapped, key) {
+			t.Fatalf("unwrap %x: got %x, %v", got, unwrapped, err)
+		}
+
+		got[len(got)-1] ^= 1
+		if _, err := keyUnwrapPad(kek, got); err == nil {
+			t.Fatalf("tampered ciphertext must not unwrap")
+		}
+	}
+}
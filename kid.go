@@ -0,0 +1,63 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const kidAttempts = 3
+
+// newKID generates a KID for alg that is not in the cache and, with
+// WithKIDCollisionCheck, not in the store either.
+func (km *KeyManager) newKID(ctx context.Context, alg Alg) (string, error) {
+	for range kidAttempts {
+		kid, err := generateKID(km.entropy(), alg)
+		if err != nil {
+			return "", err
+		}
+
+		taken, err := km.kidTaken(ctx, kid)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return kid, nil
+		}
+
+		km.logger().Warn("generated kid collides with an existing key", "kid", kid)
+	}
+
+	return "", fmt.Errorf("%w after %d attempts", ErrKIDCollision, kidAttempts)
+}
+
+func (km *KeyManager) kidTaken(ctx context.Context, kid string) (bool, error) {
+	if _, ok := km.snapshot().cache[kid]; ok {
+		return true, nil
+	}
+	if !km.kidCheck {
+		return false, nil
+	}
+
+	if getter, ok := km.store.(KeyGetter); ok {
+		k, err := getter.Get(ctx, kid)
+		if errors.Is(err, ErrKeyNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return k != nil, nil
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, k := range keys {
+		if k.KID == kid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,62 @@
+package keys_manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Selector is a set of label requirements, all of which must match.
+type Selector map[string]string
+
+// ParseSelector parses a comma-separated list of key=value pairs, e.g.
+// "env=prod,service=billing". An empty expression matches every key.
+func ParseSelector(expr string) (Selector, error) {
+	sel := Selector{}
+
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(part, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid selector term %q", part)
+		}
+
+		sel[k] = v
+	}
+
+	return sel, nil
+}
+
+func (s Selector) Matches(labels map[string]string) bool {
+	for k, v := range s {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	return formatLabels(s)
+}
+
+// formatLabels renders labels in canonical, sorted form.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + labels[k]
+	}
+
+	return strings.Join(parts, ",")
+}
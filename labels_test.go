@@ -0,0 +1,43 @@
+package keys_manager
+
+import "testing"
+
+func TestParseSelector(t *testing.T) {
+	sel, err := ParseSelector(" env=prod, service = billing ,")
+	if err != nil {
+		t.Fatalf("ParseSelector error: %v", err)
+	}
+
+	if len(sel) != 2 || sel["env"] != "prod" || sel["service"] != "billing" {
+		t.Fatalf("unexpected selector: %v", sel)
+	}
+
+	if sel.String() != "env=prod,service=billing" {
+		t.Fatalf("unexpected canonical form: %s", sel)
+	}
+
+	for _, bad := range []string{"env", "=prod", "env=prod,broken"} {
+		if _, err := ParseSelector(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "service": "billing"}
+
+	cases := map[string]bool{
+		"":                         true,
+		"env=prod":                 true,
+		"env=prod,service=billing": true,
+		"env=staging":              false,
+		"team=core":                false,
+	}
+
+	for expr, want := range cases {
+		sel, _ := ParseSelector(expr)
+		if got := sel.Matches(labels); got != want {
+			t.Fatalf("%q: Matches = %v, want %v", expr, got, want)
+		}
+	}
+}
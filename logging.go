@@ -0,0 +1,76 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Log records carry key IDs and algorithms only, never key material.
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+func (km *KeyManager) logger() *slog.Logger {
+	if km.log == nil {
+		return discardLogger
+	}
+	return km.log
+}
+
+func (km *KeyManager) logReload(ctx context.Context, cause ReloadCause, err error, snap *cacheSnapshot) {
+	log := km.logger()
+
+	if err != nil {
+		if !errors.Is(err, ErrClosed) && !errors.Is(err, context.Canceled) {
+			log.WarnContext(ctx, "key cache reload failed", "cause", cause, "err", err)
+		}
+		return
+	}
+
+	for _, q := range snap.quarantine {
+		log.WarnContext(ctx, "key quarantined", "kid", q.KID, "alg", q.Alg, "err", q.Err)
+	}
+	for _, d := range snap.duplicates {
+		log.WarnContext(ctx, "several active keys in one slot", "alg", d.Alg, "purpose", d.Purpose, "kids", d.KIDs, "winner", d.Winner)
+	}
+
+	log.DebugContext(ctx, "key cache reloaded",
+		"cause", cause,
+		"keys", len(snap.cache),
+		"active", len(snap.active),
+		"quarantined", len(snap.quarantine),
+	)
+}
+
+// reportError passes err to the WithOnError handler. Shutdown and
+// cancellation are not reported.
+func (km *KeyManager) reportError(op Operation, kid string, err error) {
+	if err == nil || km.onError == nil {
+		return
+	}
+	if errors.Is(err, ErrClosed) || errors.Is(err, context.Canceled) {
+		return
+	}
+	km.onError(&OperationError{Op: op, KID: kid, Err: err})
+}
+
+func (km *KeyManager) logImplicitReload(ctx context.Context, reason string, err error, args ...any) {
+	log := km.logger()
+
+	args = append(args, "reason", reason)
+	if err != nil {
+		log.WarnContext(ctx, "implicit key cache reload failed", append(args, "err", err)...)
+		return
+	}
+	log.DebugContext(ctx, "implicit key cache reload", args...)
+}
+
+func (km *KeyManager) logRotate(ctx context.Context, s keySlot, newKID, oldKID string, err error) {
+	log := km.logger()
+
+	if err != nil {
+		log.ErrorContext(ctx, "key rotation failed", "slot", s.String(), "err", err)
+		return
+	}
+	log.InfoContext(ctx, "key rotated", "slot", s.String(), "kid", newKID, "retired_kid", oldKID)
+}
@@ -0,0 +1,72 @@
+package keys_manager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newLoggedManager(t *testing.T, store Store, enc Encryptor, opts ...Option) (*KeyManager, *bytes.Buffer) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	km, err := NewKeyManager(store, enc, mockPolicy, append(opts, WithLogger(log))...)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	return km, &buf
+}
+
+func TestLogger_RotationAndImplicitReload(t *testing.T) {
+	km, buf := newLoggedManager(t, NewMockStore(), MockEncryptor{})
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	kid := km.activeKey(AlgEdDSA).key.KID
+
+	_ = km.Verify("unknown", nil, nil)
+
+	out := buf.String()
+	for _, want := range []string{`"msg":"key rotated"`, `"kid":"` + kid + `"`, `"msg":"implicit key cache reload"`, `"reason":"unknown kid"`, `"msg":"key cache reloaded"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in log output:\n%s", want, out)
+		}
+	}
+
+	ck := km.activeKey(AlgEdDSA)
+	material := base64.StdEncoding.EncodeToString(ck.key.EncryptedKey.Ciphertext)
+	if strings.Contains(out, material) {
+		t.Fatalf("log output must not contain key material")
+	}
+}
+
+func TestLogger_RotationFailure(t *testing.T) {
+	store := NewMockStore()
+	store.RotateErr = errors.New("store down")
+
+	km, buf := newLoggedManager(t, store, MockEncryptor{})
+
+	if err := km.Rotate(AlgES256); err == nil {
+		t.Fatalf("expected Rotate error")
+	}
+	if !strings.Contains(buf.String(), `"msg":"key rotation failed"`) {
+		t.Fatalf("expected rotation failure to be logged:\n%s", buf)
+	}
+}
+
+func TestLogger_QuarantinedKeys(t *testing.T) {
+	store := NewMockStore()
+	store.Save(&Key{KID: "broken", Alg: AlgEdDSA, EncryptedKey: &EncryptedKey{Ciphertext: []byte("junk")}})
+
+	_, buf := newLoggedManager(t, store, MockEncryptor{}, WithLenientReload())
+
+	if !strings.Contains(buf.String(), `"msg":"key quarantined"`) || !strings.Contains(buf.String(), `"kid":"broken"`) {
+		t.Fatalf("expected quarantined key to be logged:\n%s", buf)
+	}
+}
@@ -1,11 +1,18 @@
 package keys_manager
 
 import (
+	"bytes"
+	"context"
 	"crypto"
-	"crypto/rand"
-	"encoding/json"
+	"crypto/x509/pkix"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,261 +21,1540 @@ type KeyManager struct {
 	encryptor Encryptor
 	policy    RotationPolicy
 
-	mu     sync.RWMutex
-	active map[Alg]*CachedKey
-	cache  map[string]*CachedKey
+	verifyOnly       bool
+	lazy             bool
+	lenient          bool
+	migratePlaintext bool
+	clock            Clock
+	skew             time.Duration
+	kidGuard         *unknownKIDGuard
+	reloads          reloadGroup
+
+	// listSeq numbers store listings; publishedSeq, guarded by mu, is the
+	// listing behind the current snapshot, so a slow reload never
+	// replaces the result of a later one.
+	listSeq      atomic.Uint64
+	publishedSeq uint64
+
+	// material is held for reading while a cached private key signs and
+	// for writing while retired keys are wiped.
+	material sync.RWMutex
+
+	minReloadInterval time.Duration
+	lastReload        atomic.Int64
+	reloadStats       reloadStats
+	initSlots         sync.Map
+	webhookTolerance  time.Duration
+	jwksAttestation   bool
+	jwksMaxAge        time.Duration
+	jwksRotationHint  bool
+	environment       string
+	rand              io.Reader
+	kidCheck          bool
+	domains           map[Purpose]string
+	replay            ReplayCache
+	purposePolicies   map[Purpose]RotationPolicy
+	purposeSchedules  map[Purpose]time.Duration
+	escrowKey         crypto.PublicKey
+	approver          Approver
+	revocationList    string
+	revocationSigner  Purpose
+	cacheMaxAge       time.Duration
+	acceptDER         bool
+	retention         time.Duration
+	verifyGrace       time.Duration
+	signRecorder      *signRecorder
+
+	refreshInterval time.Duration
+	rotateInterval  time.Duration
+	activePolicy    ActivePolicy
+	revocations     atomic.Pointer[revocationSet]
+	expiredPolicy   ExpiredKeyPolicy
+	rotateOnSign    bool
+	signRotate      sync.Mutex
+	usageInterval   time.Duration
+	bg              *background
+	watcher         Watcher
+	watching        atomic.Bool
+	keyPool         keyPool
+	bounded         *boundedCache
+	closed          atomic.Bool
+	warmStandby     bool
+	tracer          Tracer
+	limiter         SignLimiter
+	jwksExport      *jwksExport
+	events          EventPublisher
+	onError         func(*OperationError)
+	policyOverride  atomic.Pointer[RotationPolicy]
+	certSubject     *pkix.Name
+	attester        Attester
+	authorizer      Authorizer
+	log             *slog.Logger
+
+	// snap is replaced wholesale on every change so readers never lock.
+	// mu serializes writers.
+	snap atomic.Pointer[cacheSnapshot]
+	mu   sync.Mutex
+}
+
+// cacheSnapshot is immutable once published.
+type cacheSnapshot struct {
+	active     map[keySlot]*CachedKey
+	cache      map[string]*CachedKey
+	quarantine []QuarantinedKey
+	duplicates []DuplicateActive
+	splits     map[keySlot][]*CachedKey
+	jwks       *jwksDocument
+	purposes   map[Purpose]*jwksDocument // jwks split by key purpose
+	version    string
+	generation uint64 // bumped whenever the published jwks changes
+
+	// retired holds key material dropped by the reload that published
+	// this snapshot. It is wiped once the next snapshot replaces it,
+	// giving in-flight readers a generation to finish.
+	retired retiredMaterial
 }
 
+var (
+	_ TokenSigner   = (*KeyManager)(nil)
+	_ TokenVerifier = (*KeyManager)(nil)
+	_ KeyRotator    = (*KeyManager)(nil)
+	_ JWKSProvider  = (*KeyManager)(nil)
+)
+
 func NewKeyManager(
 	store Store,
 	enc Encryptor,
 	policy RotationPolicy,
+	opts ...Option,
 ) (*KeyManager, error) {
 	km := &KeyManager{
 		store:     store,
 		encryptor: enc,
 		policy:    policy,
-		active:    make(map[Alg]*CachedKey),
-		cache:     make(map[string]*CachedKey),
+		clock:     systemClock{},
+		kidGuard:  &unknownKIDGuard{},
+		bg:        newBackground(),
+	}
+	km.snap.Store(&cacheSnapshot{
+		active: make(map[keySlot]*CachedKey),
+		cache:  make(map[string]*CachedKey),
+	})
+
+	for _, opt := range opts {
+		opt(km)
+	}
+
+	if err := km.checkBoundedStore(); err != nil {
+		return nil, err
+	}
+	if err := km.checkUsageStore(); err != nil {
+		return nil, err
+	}
+	if err := checkEscrowKey(km.escrowKey); err != nil {
+		return nil, err
+	}
+
+	if !km.verifyOnly {
+		for alg := range km.keyPool.ready {
+			km.bg.goFunc(func(ctx context.Context) {
+				km.reportError(OpKeyPool, "", km.keyPool.fill(ctx, alg, km.entropy()))
+			})
+		}
+	}
+
+	if km.jwksExport != nil {
+		km.bg.goFunc(km.jwksExportLoop)
 	}
 
-	if err := km.ReloadCache(); err != nil {
+	events, err := km.watchStore()
+	if err != nil {
+		km.bg.stop()
+		return nil, err
+	}
+
+	if err := km.reload(withReloadCause(context.Background(), ReloadInit)); err != nil {
+		km.bg.stop()
 		return nil, err
 	}
 
+	if km.revocationList != "" {
+		if err := km.ApplyRevocationList(km.revocationList); err != nil {
+			km.bg.stop()
+			return nil, fmt.Errorf("apply revocation list: %w", err)
+		}
+	}
+
+	switch {
+	case events != nil:
+		km.bg.goFunc(func(ctx context.Context) {
+			km.watchLoop(ctx, events)
+		})
+	case km.refreshInterval > 0:
+		km.bg.goFunc(func(ctx context.Context) {
+			km.refreshLoop(ctx, km.refreshInterval)
+		})
+	}
+
+	if !km.verifyOnly {
+		km.resumeStaged()
+	}
+
+	if km.usageInterval > 0 {
+		km.bg.goFunc(func(ctx context.Context) {
+			km.usageLoop(ctx, km.usageInterval)
+		})
+	}
+
+	if km.rotateInterval > 0 && !km.verifyOnly {
+		km.bg.goFunc(func(ctx context.Context) {
+			km.rotateLoop(ctx, km.rotateInterval, func(p Purpose) bool {
+				_, own := km.purposeSchedules[p]
+				return !own
+			})
+		})
+	}
+
+	for purpose, interval := range km.purposeSchedules {
+		if interval <= 0 || km.verifyOnly {
+			continue
+		}
+		km.bg.goFunc(func(ctx context.Context) {
+			km.rotateLoop(ctx, interval, func(p Purpose) bool { return p == purpose })
+		})
+	}
+
 	return km, nil
 }
 
+func (km *KeyManager) snapshot() *cacheSnapshot {
+	return km.snap.Load()
+}
+
 func (km *KeyManager) activeKey(alg Alg) *CachedKey {
-	km.mu.RLock()
-	ck := km.active[alg]
-	km.mu.RUnlock()
+	return km.activeKeyFor(context.Background(), keySlot{alg: alg})
+}
 
-	if ck != nil {
-		return ck
+func (km *KeyManager) activeKeyFor(ctx context.Context, s keySlot) *CachedKey {
+	km.refreshIfStale(ctx)
+
+	ck := km.snapshot().active[s]
+
+	if ck == nil {
+		err := km.implicitReload(ctx, ReloadActiveMiss)
+		km.logImplicitReload(ctx, "no active key", err, "slot", s.String())
+		km.reportError(OpImplicitReload, "", err)
+		ck = km.snapshot().active[s]
 	}
 
-	_ = km.ReloadCache()
+	if ck == nil || km.notYetValid(ck.key, km.clock.Now()) {
+		return nil
+	}
 
-	km.mu.RLock()
-	defer km.mu.RUnlock()
-	return km.active[alg]
+	return ck
 }
 
 func (km *KeyManager) keyByKID(kid string) *CachedKey {
-	km.mu.RLock()
-	ck := km.cache[kid]
-	km.mu.RUnlock()
+	return km.keyByKIDCtx(context.Background(), kid)
+}
+
+func (km *KeyManager) keyByKIDCtx(ctx context.Context, kid string) *CachedKey {
+	km.refreshIfStale(ctx)
 
+	ck := km.snapshot().cache[kid]
 	if ck != nil {
 		return ck
 	}
 
-	_ = km.ReloadCache()
+	if km.bounded != nil {
+		if ck = km.bounded.get(kid); ck != nil {
+			return ck
+		}
+	}
+
+	now := km.clock.Now()
+	if !km.kidGuard.allowReload(kid, now) {
+		return nil
+	}
+
+	if km.bounded != nil {
+		ck = km.fetchKey(ctx, kid)
+	} else {
+		err := km.implicitReload(ctx, ReloadKIDMiss)
+		km.logImplicitReload(ctx, "unknown kid", err, "kid", kid)
+		km.reportError(OpImplicitReload, kid, err)
+		ck = km.snapshot().cache[kid]
+	}
+
+	if ck == nil {
+		km.kidGuard.recordMiss(kid, now)
+	}
 
-	km.mu.RLock()
-	defer km.mu.RUnlock()
-	return km.cache[kid]
+	return ck
 }
 
 func (km *KeyManager) Sign(
 	alg Alg,
 	build func(kid string) ([]byte, error),
 ) ([]byte, error) {
-	ck := km.activeKey(alg)
+	return km.signWithPurpose(context.Background(), alg, PurposeDefault, rawInput, build)
+}
+
+func (km *KeyManager) SignCtx(
+	ctx context.Context,
+	alg Alg,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	return km.signWithPurpose(ctx, alg, PurposeDefault, rawInput, build)
+}
+
+func (km *KeyManager) SignWithPurpose(
+	alg Alg,
+	purpose Purpose,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	return km.signWithPurpose(context.Background(), alg, purpose, rawInput, build)
+}
+
+func (km *KeyManager) SignWithPurposeCtx(
+	ctx context.Context,
+	alg Alg,
+	purpose Purpose,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	return km.signWithPurpose(ctx, alg, purpose, rawInput, build)
+}
+
+func (km *KeyManager) signWithPurpose(
+	ctx context.Context,
+	alg Alg,
+	purpose Purpose,
+	kind inputKind,
+	build func(kid string) ([]byte, error),
+) (sig []byte, err error) {
+	ctx, span := km.startSpan(ctx, SpanSign)
+	span.SetAttribute("alg", string(alg))
+	defer func() { span.End(err) }()
+
+	s := keySlot{alg: alg, purpose: purpose}
+
+	ck := km.activeKeyFor(ctx, s)
 	if ck == nil {
-		return nil, fmt.Errorf("no active key for alg %s", alg)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !km.rotateOnSign {
+			return nil, fmt.Errorf("%w for %s", ErrNoActiveKey, s)
+		}
+		if ck, err = km.rotateForSign(ctx, s); err != nil {
+			return nil, err
+		}
 	}
+	ck = km.pickWeighted(s, ck)
 
-	signingInput, err := build(ck.key.KID)
-	if err != nil {
-		return nil, err
+	if km.rotatesOnSign() && km.expired(ck.key, km.clock.Now()) {
+		if ck, err = km.rotateForSign(ctx, slotOf(ck.key)); err != nil {
+			return nil, err
+		}
 	}
+	span.SetAttribute("kid", ck.key.KID)
 
-	opts, err := signingOptions(alg)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	var digest []byte
-	if opts.HashFunc() != crypto.Hash(0) {
-		h := opts.HashFunc().New()
-		h.Write(signingInput)
-		digest = h.Sum(nil)
-	} else {
-		digest = signingInput
-	}
+	return km.signAs(ctx, ck, kind, build)
+}
 
-	sig, err := ck.priv.Sign(rand.Reader, digest, opts)
+// SignWithSelector signs with the newest active key of alg whose labels
+// satisfy the selector expression, e.g. "env=prod,service=billing".
+func (km *KeyManager) SignWithSelector(
+	alg Alg,
+	expr string,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	sel, err := ParseSelector(expr)
 	if err != nil {
 		return nil, err
 	}
 
-	if alg != AlgES256 {
-		return sig, nil
+	ck := km.activeKeyBySelector(alg, sel)
+	if ck == nil {
+		_ = km.implicitReload(context.Background(), ReloadActiveMiss)
+		ck = km.activeKeyBySelector(alg, sel)
 	}
 
-	rawSig, err := DERToRawECDSA(alg, sig)
-	if err != nil {
-		return nil, fmt.Errorf("ecdsa convert: %w", err)
+	if ck == nil || km.notYetValid(ck.key, km.clock.Now()) {
+		return nil, fmt.Errorf("%w for alg %s selector %q", ErrNoActiveKey, alg, sel)
 	}
+	ck = km.pickWeighted(slotOf(ck.key), ck)
 
-	return rawSig, nil
+	return km.sign(context.Background(), ck, build)
 }
 
-func (km *KeyManager) Verify(kid string, payload, sig []byte) error {
-	ck := km.keyByKID(kid)
-	if ck == nil {
-		return fmt.Errorf("key %s not found", kid)
+func (km *KeyManager) activeKeyBySelector(alg Alg, sel Selector) *CachedKey {
+	var best *CachedKey
+	for s, ck := range km.snapshot().active {
+		if s.alg != alg || !sel.Matches(ck.key.Labels) {
+			continue
+		}
+		if best == nil || ck.key.CreatedAt.After(best.key.CreatedAt) {
+			best = ck
+		}
 	}
 
-	return verifySignature(ck.key.Alg, ck.pub, payload, sig)
+	return best
 }
 
-func (km *KeyManager) JWKS() ([]byte, error) {
-	km.mu.RLock()
-	defer km.mu.RUnlock()
-
-	jwks := buildJWKS(km.cache)
-	return json.Marshal(jwks)
+func (km *KeyManager) SignWithKID(
+	kid string,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	return km.SignWithKIDCtx(context.Background(), kid, build)
 }
 
-func (km *KeyManager) Rotate(alg Alg) error {
-	policy, err := km.policy()
-	if err != nil {
-		return err
+func (km *KeyManager) SignWithKIDCtx(
+	ctx context.Context,
+	kid string,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	ck := km.keyByKIDCtx(ctx, kid)
+	if ck == nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
 	}
 
-	keys, err := km.store.List()
+	ck, err := km.materialize(ck)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var oldKey *Key
-	for _, k := range keys {
-		if k.Alg == alg && k.IsActive {
-			cloned := *k
-			cloned.IsActive = false
-			oldKey = &cloned
-			break
-		}
+	return km.sign(ctx, ck, build)
+}
+
+func (km *KeyManager) sign(
+	ctx context.Context,
+	ck *CachedKey,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	return km.signAs(ctx, ck, rawInput, build)
+}
+
+func (km *KeyManager) signAs(
+	ctx context.Context,
+	ck *CachedKey,
+	kind inputKind,
+	build func(kid string) ([]byte, error),
+) ([]byte, error) {
+	if ck.key.Alg.symmetric() {
+		return nil, fmt.Errorf("%w: %s keys cannot sign", ErrUnsupportedAlg, ck.key.Alg)
+	}
+	if ck.priv == nil {
+		return nil, ErrVerifyOnly
+	}
+	if ck.key.RevokedAt != nil || km.listRevoked(ck.key.KID) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRevoked, ck.key.KID)
+	}
+	if err := km.checkExpiry(ck.key); err != nil {
+		return nil, err
+	}
+	if err := km.authorize(ctx, ActionSign, ck.key); err != nil {
+		return nil, err
+	}
+	if err := km.allowSign(ctx, ck, 1); err != nil {
+		return nil, err
 	}
 
-	newPriv, err := generatePrivateKey(alg)
+	signingInput, err := build(ck.key.KID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	privBytes, err := marshalPKCS8(newPriv)
+	opts, err := signingOptions(ck.key.Alg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	encrypted, err := km.encryptor.Encrypt(privBytes)
+	d := acquireDigester(opts)
+	sig, err := km.signInput(km.entropy(), ck, opts, d, km.domainInput(kind, ck.key, signingInput))
+	d.release()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	now := time.Now()
-	expires := now.Add(policy.TTL)
+	now := km.clock.Now()
+	ck.usage.recordSign(now)
+	km.recordSign(ctx, ck.key, signingInput, now)
 
-	newKey := &Key{
-		Alg:          alg,
-		IsActive:     true,
-		CreatedAt:    now,
-		ExpiresAt:    &expires,
-		EncryptedKey: encrypted,
-		KID:          generateKID(alg),
+	return sig, nil
+}
+
+// signInput signs input with the private key of ck. A key wiped after it
+// left the cache fails with ErrKeyNotFound instead of signing with zeroes.
+func (km *KeyManager) signInput(
+	r io.Reader,
+	ck *CachedKey,
+	opts crypto.SignerOpts,
+	d *digester,
+	input []byte,
+) ([]byte, error) {
+	km.material.RLock()
+	if signerWiped(ck.priv) {
+		km.material.RUnlock()
+		return nil, fmt.Errorf("%w: %s was retired", ErrKeyNotFound, ck.key.KID)
+	}
+	sig, err := ck.priv.Sign(r, d.digest(input), opts)
+	km.material.RUnlock()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := km.store.Rotate(newKey, oldKey); err != nil {
-		return err
+	if ck.key.Alg == AlgES256 {
+		sig, err = DERToRawECDSA(ck.key.Alg, sig)
+		if err != nil {
+			return nil, fmt.Errorf("ecdsa convert: %w", err)
+		}
 	}
 
-	return km.ReloadCache()
+	return sig, nil
 }
 
-func (km *KeyManager) RotateExpired() error {
-	km.mu.RLock()
-	active := make(map[Alg]*CachedKey, len(km.active))
-	for alg, ck := range km.active {
-		active[alg] = ck
-	}
-	km.mu.RUnlock()
+func (km *KeyManager) Verify(kid string, payload, sig []byte) error {
+	return km.VerifyCtx(context.Background(), kid, payload, sig)
+}
 
-	now := time.Now()
-	var errs []error
+func (km *KeyManager) VerifyCtx(ctx context.Context, kid string, payload, sig []byte) error {
+	return km.verify(ctx, kid, "", false, rawInput, payload, sig)
+}
 
-	for alg, ck := range active {
-		if ck.key.ExpiresAt != nil && ck.key.ExpiresAt.Before(now) {
-			if err := km.Rotate(alg); err != nil {
-				errs = append(errs, fmt.Errorf("rotate %s: %w", alg, err))
-			}
+// verify checks sig by kid. A non-empty alg must be the key's alg; with
+// allowPSS an RS256 key also accepts the RSA-PSS algs.
+func (km *KeyManager) verify(ctx context.Context, kid string, alg Alg, allowPSS bool, kind inputKind, payload, sig []byte) (err error) {
+	ctx, span := km.startSpan(ctx, SpanVerify)
+	span.SetAttribute("kid", kid)
+	defer func() { span.End(err) }()
+
+	ck := km.keyByKIDCtx(ctx, kid)
+	if ck == nil {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("rotation errors: %v", errs)
+	return km.verifyCached(ck, alg, allowPSS, kind, payload, sig)
+}
+
+// verifyCached is verify for a key the caller already looked up.
+func (km *KeyManager) verifyCached(ck *CachedKey, alg Alg, allowPSS bool, kind inputKind, payload, sig []byte) error {
+	kid := ck.key.KID
+	if ck.key.RevokedAt != nil || km.listRevoked(kid) {
+		return fmt.Errorf("%w: %s", ErrKeyRevoked, kid)
+	}
+	if alg != "" && alg != ck.key.Alg && !(allowPSS && pssOnRSA(alg, ck.key)) {
+		return fmt.Errorf("%w: alg %s does not match key %s (%s)", ErrInvalidToken, alg, kid, ck.key.Alg)
+	}
+
+	now := km.clock.Now()
+	if km.notYetValid(ck.key, now) {
+		return fmt.Errorf("%w: %s", ErrKeyNotYetValid, kid)
+	}
+	if err := km.checkVerifyUntil(ck.key, now); err != nil {
+		return err
+	}
+
+	if alg == "" {
+		alg = ck.key.Alg
+	}
+	if err := km.verifyKey(alg, ck, km.domainInput(kind, ck.key, payload), sig); err != nil {
+		return err
 	}
 
+	ck.usage.recordVerify(now)
+
 	return nil
 }
 
-func (km *KeyManager) ReloadCache() error {
-	keys, err := km.store.List()
-	if err != nil {
-		return err
+// VerifyWithAlg checks a signature made with alg by the key kid. Besides
+// the key's own alg, RS256 keys accept PS256, PS384 and PS512 signatures
+// made with them outside the manager. This is the only opt-in: Verify,
+// VerifyJWT and ReSign require the key's own alg.
+func (km *KeyManager) VerifyWithAlg(ctx context.Context, kid string, alg Alg, payload, sig []byte) error {
+	return km.verify(ctx, kid, alg, true, rawInput, payload, sig)
+}
+
+// pssOnRSA reports whether alg is an RSA-PSS alg k can verify.
+func pssOnRSA(alg Alg, k *Key) bool {
+	_, pss := alg.pssHash()
+	return pss && k.Alg == AlgRS256
+}
+
+func (km *KeyManager) VerifyAny(alg Alg, payload, sig []byte) error {
+	candidates := km.keysForAlg(alg)
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w for alg %s", ErrKeyNotFound, alg)
 	}
 
-	newCache := make(map[string]*CachedKey)
-	newActive := make(map[Alg]*CachedKey)
+	now := km.clock.Now()
 
-	for _, k := range keys {
-		privBytes, err := km.encryptor.Decrypt(k.EncryptedKey)
-		if err != nil {
-			return fmt.Errorf("decrypt key %s: %w", k.KID, err)
+	for _, ck := range candidates {
+		if km.notYetValid(ck.key, now) || km.checkVerifyUntil(ck.key, now) != nil {
+			continue
 		}
 
-		priv, err := parsePrivateKey(privBytes)
-		if err != nil {
-			return fmt.Errorf("parse key %s: %w", k.KID, err)
+		if err := km.verifyKey(alg, ck, km.domainInput(rawInput, ck.key, payload), sig); err == nil {
+			ck.usage.recordVerify(now)
+			return nil
 		}
+	}
+
+	return fmt.Errorf("%w: no key for alg %s matches signature", ErrInvalidSignature, alg)
+}
+
+// keysForAlg returns cached keys of alg, the active key first and the
+// remaining ones newest first.
+func (km *KeyManager) keysForAlg(alg Alg) []*CachedKey {
+	cache := km.snapshot().cache
 
-		ck := &CachedKey{
-			key:  k,
-			priv: priv,
-			pub:  priv.Public(),
+	out := make([]*CachedKey, 0, len(cache))
+	for _, ck := range cache {
+		if ck.key.Alg == alg && ck.key.RevokedAt == nil && !km.listRevoked(ck.key.KID) {
+			out = append(out, ck)
 		}
+	}
 
-		newCache[k.KID] = ck
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].key, out[j].key
+		if a.IsActive != b.IsActive {
+			return a.IsActive
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
 
-		if k.IsActive {
-			newActive[k.Alg] = ck
+	return out
+}
+
+// JWKS returns a copy of the serialized key set built on the last cache
+// reload. JWKSTo writes it without copying.
+func (km *KeyManager) JWKS() ([]byte, error) {
+	body, _, err := km.JWKSWithETag()
+	return body, err
+}
+
+// JWKSWithETag returns a copy of the serialized key set together with a
+// strong ETag derived from its content.
+func (km *KeyManager) JWKSWithETag() ([]byte, string, error) {
+	doc := km.snapshot().jwks
+
+	if doc == nil {
+		return nil, "", errors.New("jwks not built")
+	}
+
+	return bytes.Clone(doc.body), doc.etag, nil
+}
+
+// Generation returns the keyset generation, which grows by one every time
+// a reload publishes a JWKS that differs from the previous one. It is 0
+// before the first load.
+func (km *KeyManager) Generation() uint64 {
+	return km.snapshot().generation
+}
+
+// JWKSForPurpose returns the key set of purpose alone, with its ETag, so
+// each key line can be published at its own URL. A purpose without keys
+// yields an empty set.
+func (km *KeyManager) JWKSForPurpose(purpose Purpose) ([]byte, string, error) {
+	snap := km.snapshot()
+	if snap.jwks == nil {
+		return nil, "", errors.New("jwks not built")
+	}
+
+	doc := snap.purposes[purpose]
+	if doc == nil {
+		empty, err := newJWKSDocument(nil, false)
+		if err != nil {
+			return nil, "", err
 		}
+		doc = empty
 	}
 
-	km.mu.Lock()
-	km.cache = newCache
-	km.active = newActive
-	km.mu.Unlock()
+	return bytes.Clone(doc.body), doc.etag, nil
+}
 
-	return nil
+// JWKSTo writes the serialized key set to w without copying it.
+func (km *KeyManager) JWKSTo(w io.Writer) (int64, error) {
+	doc := km.snapshot().jwks
+
+	if doc == nil {
+		return 0, errors.New("jwks not built")
+	}
+
+	n, err := w.Write(doc.body)
+	return int64(n), err
 }
 
-func (km *KeyManager) InitKeys(algs []Alg) error {
-	for _, alg := range algs {
-		km.mu.RLock()
-		_, exists := km.active[alg]
-		km.mu.RUnlock()
+func (km *KeyManager) Rotate(alg Alg) error {
+	return km.RotateWithPurpose(alg, PurposeDefault)
+}
 
-		if exists {
-			continue
+func (km *KeyManager) RotateWithPurpose(alg Alg, purpose Purpose) error {
+	return km.RotateWithPurposeCtx(context.Background(), alg, purpose)
+}
+
+func (km *KeyManager) RotateWithPurposeCtx(ctx context.Context, alg Alg, purpose Purpose) error {
+	return km.authorizedRotate(ctx, alg, purpose, nil)
+}
+
+func (km *KeyManager) RotateWithLabels(alg Alg, labels map[string]string) error {
+	return km.authorizedRotate(context.Background(), alg, PurposeDefault, labels)
+}
+
+func (km *KeyManager) authorizedRotate(ctx context.Context, alg Alg, purpose Purpose, labels map[string]string) error {
+	req := AccessRequest{Action: ActionRotate, Alg: alg, Purpose: purpose, Labels: labels}
+	if err := km.authorizeRequest(ctx, req); err != nil {
+		return err
+	}
+	return km.rotate(ctx, alg, purpose, labels)
+}
+
+func (km *KeyManager) rotate(ctx context.Context, alg Alg, purpose Purpose, labels map[string]string) (err error) {
+	s := keySlot{alg: alg, purpose: purpose, labels: formatLabels(labels)}
+
+	ctx, span := km.startSpan(ctx, SpanRotate)
+	span.SetAttribute("alg", string(alg))
+
+	var rotated bool
+	defer func() {
+		if err != nil && !rotated {
+			km.logRotate(ctx, s, "", "", err)
 		}
+		span.End(err)
+	}()
+
+	if km.verifyOnly {
+		return ErrVerifyOnly
+	}
+	if km.closed.Load() {
+		return ErrClosed
+	}
+
+	policy, err := km.rotationConfigFor(purpose)
+	if err != nil {
+		return err
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
 
-		if err := km.Rotate(alg); err != nil {
-			return fmt.Errorf("failed to initialize key for alg %s: %w", alg, err)
+	if km.approver != nil && pendingIn(keys, s) {
+		return nil
+	}
+	if staged := stagedIn(keys, s); staged != nil {
+		if km.clock.Now().Before(*staged.NotBefore) {
+			return nil
 		}
+		return km.activateStaged(ctx, staged.KID)
 	}
 
-	return nil
+	retired := km.retireActive(keys, s, "")
+	var oldKey *Key
+	if len(retired) > 0 {
+		oldKey = retired[0]
+	}
+
+	delay := policy.PropagationDelay
+	if oldKey == nil {
+		delay = 0
+	}
+
+	now := km.clock.Now()
+	newKey, err := km.generateKey(ctx, s, labels, now, now.Add(delay+policy.TTL))
+	if err != nil {
+		return err
+	}
+
+	if km.approver != nil {
+		return km.requestApproval(ctx, newKey, oldKey)
+	}
+	if delay > 0 {
+		return km.stage(ctx, newKey, oldKey, delay)
+	}
+
+	if err := km.storeRotateAll(ctx, newKey, retired); err != nil {
+		return err
+	}
+	rotated = true
+
+	var oldKID string
+	if oldKey != nil {
+		oldKID = oldKey.KID
+	}
+	km.logRotate(ctx, s, newKey.KID, oldKID, nil)
+	km.publish(ctx, LifecycleEvent{Type: EventKeyRotated, KID: newKey.KID, Alg: alg, Purpose: purpose, PreviousKID: oldKID})
+
+	return km.reload(ctx)
+}
+
+// generateKey creates and attests a new active key for s, valid from now
+// until expires. It is not stored.
+func (km *KeyManager) generateKey(ctx context.Context, s keySlot, labels map[string]string, now, expires time.Time) (*Key, error) {
+	kid, err := km.newKID(ctx, s.alg)
+	if err != nil {
+		return nil, err
+	}
+
+	privBytes, pubBytes, err := km.newKeyMaterial(s.alg)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := km.issueCertificate(s.alg, privBytes, kid, now, expires)
+	if err != nil {
+		wipeBytes(privBytes)
+		return nil, err
+	}
+
+	escrow, err := km.sealEscrow(kid, privBytes)
+	if err != nil {
+		wipeBytes(privBytes)
+		return nil, err
+	}
+
+	encrypted, err := km.encrypt(ctx, privBytes)
+	wipeBytes(privBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Key{
+		Alg:          s.alg,
+		Purpose:      s.purpose,
+		Labels:       labels,
+		Environment:  km.environment,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		ExpiresAt:    &expires,
+		EncryptedKey: encrypted,
+		PublicKey:    pubBytes,
+		Certificate:  cert,
+		Escrow:       escrow,
+		KID:          kid,
+	}
+
+	if k.Attestation, err = km.attest(ctx, k, OriginGenerated); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (km *KeyManager) RotateExpired() error {
+	return km.RotateExpiredCtx(context.Background())
+}
+
+func (km *KeyManager) RotateExpiredCtx(ctx context.Context) error {
+	return km.rotateExpired(ctx, nil, nil)
+}
+
+// rotateExpired rotates every expired active key whose purpose satisfies
+// match, or all of them when match is nil, passing each failure to onErr
+// as well when it is set.
+func (km *KeyManager) rotateExpired(ctx context.Context, match func(Purpose) bool, onErr func(kid string, err error)) error {
+	active := km.snapshot().active
+
+	now := km.clock.Now()
+	var errs []error
+
+	for s, ck := range active {
+		if match != nil && !match(s.purpose) {
+			continue
+		}
+		if km.expired(ck.key, now) {
+			if err := km.rotate(ctx, s.alg, s.purpose, ck.key.Labels); err != nil {
+				errs = append(errs, fmt.Errorf("rotate %s: %w", s, err))
+				if onErr != nil {
+					onErr(ck.key.KID, err)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("rotation errors: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (km *KeyManager) expired(k *Key, now time.Time) bool {
+	return k.ExpiresAt != nil && k.ExpiresAt.Before(now.Add(-km.skew))
+}
+
+func (km *KeyManager) notYetValid(k *Key, now time.Time) bool {
+	return k.NotBefore != nil && now.Add(km.skew).Before(*k.NotBefore)
+}
+
+func (km *KeyManager) ReloadCache() error {
+	return km.reload(withReloadCause(context.Background(), ReloadExplicit))
+}
+
+// implicitReload is used for reloads triggered by cache misses; concurrent
+// misses share a single store read.
+func (km *KeyManager) implicitReload(ctx context.Context, cause ReloadCause) error {
+	if km.watching.Load() {
+		return nil
+	}
+	return km.reloads.do(ctx, func(ctx context.Context) error {
+		if km.reloadedRecently() {
+			return nil
+		}
+		return km.reload(withReloadCause(ctx, cause))
+	})
+}
+
+// reloadedRecently reports whether any reload started within the minimum
+// implicit reload interval.
+func (km *KeyManager) reloadedRecently() bool {
+	if km.minReloadInterval <= 0 {
+		return false
+	}
+
+	last := km.lastReload.Load()
+	return last != 0 && km.clock.Now().UnixNano()-last < int64(km.minReloadInterval)
+}
+
+func (km *KeyManager) reload(ctx context.Context) error {
+	return km.reloadStore(ctx, false)
+}
+
+// reloadStore rebuilds the cache from the store. Unless force is set, the
+// store is not listed when its version matches the loaded snapshot.
+func (km *KeyManager) reloadStore(ctx context.Context, force bool) (err error) {
+	cause := reloadCauseOf(ctx)
+	km.reloadStats.causes.add(cause)
+
+	ctx, span := km.startSpan(ctx, SpanReload)
+	span.SetAttribute("cause", string(cause))
+	defer func() {
+		if err != nil {
+			km.logReload(ctx, cause, err, nil)
+		} else {
+			km.queueJWKSExport()
+		}
+		km.reloadStats.record(km.clock, err)
+		span.End(err)
+	}()
+
+	if km.closed.Load() {
+		return ErrClosed
+	}
+
+	if km.minReloadInterval > 0 {
+		km.lastReload.Store(km.clock.Now().UnixNano())
+	}
+
+	version := km.storeVersion()
+	if version != "" && !force {
+		if version == km.snapshot().version {
+			return nil
+		}
+	}
+
+	seq := km.listSeq.Add(1)
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldCache := km.snapshot().cache
+	memo := newDecryptMemo(oldCache)
+
+	var standby map[string]bool
+	if km.lazy && km.warmStandby {
+		standby = standbyKeys(keys)
+	}
+
+	newCache := make(map[string]*CachedKey)
+	newActive := make(map[keySlot]*CachedKey)
+	activeKeys := make(map[keySlot][]*Key)
+	published := make(map[string]*CachedKey)
+	listed := make(map[string]*Key, len(keys))
+	var quarantine []QuarantinedKey
+
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		listed[k.KID] = k
+
+		if err := km.checkEnvironment(k); err != nil {
+			return err
+		}
+		if km.pastRetention(k) {
+			continue
+		}
+
+		if km.bounded != nil && !k.IsActive {
+			ck, err := boundedJWK(k)
+			if err != nil {
+				if !km.lenient {
+					return err
+				}
+
+				quarantine = append(quarantine, QuarantinedKey{KID: k.KID, Alg: k.Alg, Err: err})
+				continue
+			}
+
+			if ck != nil {
+				published[k.KID] = ck
+			}
+			continue
+		}
+
+		prev, seen := oldCache[k.KID]
+
+		ck, err := km.cachedKey(ctx, k, prev, memo, k.IsActive || standby[k.KID])
+		if err != nil {
+			if !km.lenient {
+				return err
+			}
+
+			quarantine = append(quarantine, QuarantinedKey{KID: k.KID, Alg: k.Alg, Err: err})
+			continue
+		}
+
+		if seen {
+			ck.usage = prev.usage
+		} else {
+			ck.usage = &keyUsage{}
+		}
+
+		newCache[k.KID] = ck
+		published[k.KID] = ck
+
+		if k.IsActive {
+			s := slotOf(k)
+			if cur := newActive[s]; cur == nil || km.preferActive(k, cur.key) {
+				newActive[s] = ck
+			}
+			activeKeys[s] = append(activeKeys[s], k)
+		}
+	}
+
+	doc, err := newJWKSDocument(published, km.jwksAttestation)
+	if err != nil {
+		return err
+	}
+
+	purposeDocs, err := newPurposeJWKS(published, km.jwksAttestation)
+	if err != nil {
+		return err
+	}
+
+	km.mu.Lock()
+	if km.closed.Load() {
+		km.mu.Unlock()
+		return ErrClosed
+	}
+	if seq < km.publishedSeq {
+		// A reload that listed the store later has already published.
+		km.mu.Unlock()
+		return nil
+	}
+	km.publishedSeq = seq
+	prev := km.snapshot()
+	next := &cacheSnapshot{
+		active:     newActive,
+		cache:      newCache,
+		quarantine: quarantine,
+		duplicates: km.duplicateActive(activeKeys),
+		splits:     km.weightedSplits(activeKeys, newCache),
+		jwks:       doc,
+		purposes:   purposeDocs,
+		version:    version,
+		generation: prev.generation,
+		retired:    prunedMaterial(prev.cache, newCache),
+	}
+	if prev.jwks == nil || prev.jwks.etag != doc.etag {
+		next.generation++
+	}
+	km.snap.Store(next)
+	km.mu.Unlock()
+
+	km.logReload(ctx, cause, nil, next)
+
+	km.wipeRetired(prev.retired)
+
+	if km.bounded != nil {
+		km.wipeKeys(km.bounded.retain(func(ck *CachedKey) bool {
+			k, ok := listed[ck.key.KID]
+			return ok && !k.IsActive && unchangedKey(ck.key, k)
+		}))
+	}
+
+	return nil
+}
+
+// cachedKey builds the cache entry for k, reusing material from prev when
+// the key is unchanged and deferring decryption in lazy mode unless eager
+// is set.
+func (km *KeyManager) cachedKey(ctx context.Context, k *Key, prev *CachedKey, memo decryptMemo, eager bool) (*CachedKey, error) {
+	if prev != nil && unchangedKey(prev.key, k) && !(prev.deferred && eager) {
+		return &CachedKey{key: k, priv: prev.priv, pub: prev.pub, secret: prev.secret, deferred: prev.deferred, ctDigest: prev.ctDigest}, nil
+	}
+
+	if !km.verifyOnly {
+		if m, d := memo.lookup(k); m != nil {
+			return &CachedKey{key: k, priv: m.priv, pub: m.pub, secret: m.secret, ctDigest: d}, nil
+		}
+	}
+
+	if km.lazy && !km.verifyOnly && !eager && len(k.PublicKey) > 0 {
+		pub, err := parsePublicKey(k.PublicKey)
+		if err != nil {
+			return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+		}
+
+		return &CachedKey{key: k, pub: pub, deferred: true}, nil
+	}
+
+	return km.loadKey(ctx, k)
+}
+
+// standbyKeys returns the most recently retired key of every slot: the
+// inactive key retired last, by UpdatedAt and then CreatedAt.
+func standbyKeys(keys []*Key) map[string]bool {
+	newest := make(map[keySlot]*Key)
+	for _, k := range keys {
+		if k.IsActive {
+			continue
+		}
+
+		s := slotOf(k)
+		if cur := newest[s]; cur == nil || retiredAfter(k, cur) {
+			newest[s] = k
+		}
+	}
+
+	out := make(map[string]bool, len(newest))
+	for _, k := range newest {
+		out[k.KID] = true
+	}
+	return out
+}
+
+func retiredAfter(a, b *Key) bool {
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return a.UpdatedAt.After(b.UpdatedAt)
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+func (km *KeyManager) encrypt(ctx context.Context, plain []byte) (ek *EncryptedKey, err error) {
+	_, span := km.startSpan(ctx, SpanEncrypt)
+	defer func() { span.End(err) }()
+
+	return km.encryptor.Encrypt(plain)
+}
+
+func (km *KeyManager) decrypt(ctx context.Context, k *Key) (plain []byte, err error) {
+	_, span := km.startSpan(ctx, SpanDecrypt)
+	span.SetAttribute("kid", k.KID)
+	defer func() { span.End(err) }()
+
+	return km.encryptor.Decrypt(k.EncryptedKey)
+}
+
+func (km *KeyManager) storeRotate(ctx context.Context, newKey, oldKey *Key) (err error) {
+	_, span := km.startSpan(ctx, SpanStoreRotate)
+	span.SetAttribute("kid", newKey.KID)
+	defer func() { span.End(err) }()
+
+	return km.store.Rotate(newKey, oldKey)
+}
+
+// storeRotateAll stores newKey retiring every key of retired. Stores take
+// one old key per Rotate, so the rest are retired by repeating the call;
+// saving newKey again leaves it unchanged.
+func (km *KeyManager) storeRotateAll(ctx context.Context, newKey *Key, retired []*Key) error {
+	if len(retired) == 0 {
+		return km.storeRotate(ctx, newKey, nil)
+	}
+	for _, old := range retired {
+		if err := km.storeRotate(ctx, newKey, old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retireActive returns inactive copies of the active keys of s other than
+// except, the key Sign prefers first. A weighted split has several.
+func (km *KeyManager) retireActive(keys []*Key, s keySlot, except string) []*Key {
+	var out []*Key
+	for _, k := range keys {
+		if k.IsActive && k.KID != except && slotOf(k) == s {
+			out = append(out, k)
+		}
+	}
+	km.rankActive(out)
+
+	now := km.clock.Now()
+	for i, k := range out {
+		out[i] = cloneKey(k)
+		out[i].IsActive = false
+		out[i].UpdatedAt = now
+	}
+	return out
+}
+
+// materialize decrypts the private key of a lazily cached entry and
+// publishes the loaded entry in the cache.
+func (km *KeyManager) materialize(ck *CachedKey) (*CachedKey, error) {
+	if !ck.deferred {
+		return ck, nil
+	}
+
+	loaded, err := km.loadKey(context.Background(), ck.key)
+	if err != nil {
+		return nil, err
+	}
+	loaded.usage = ck.usage
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	old := km.snapshot()
+	if old.cache[ck.key.KID] != ck {
+		return loaded, nil
+	}
+
+	next := *old
+	next.cache = make(map[string]*CachedKey, len(old.cache))
+	for kid, c := range old.cache {
+		next.cache[kid] = c
+	}
+	next.cache[ck.key.KID] = loaded
+	km.snap.Store(&next)
+
+	return loaded, nil
+}
+
+// storeVersion returns the store's current version, or "" when the store
+// does not report one and every reload must read it in full.
+func (km *KeyManager) storeVersion() string {
+	vs, ok := km.store.(VersionedStore)
+	if !ok {
+		return ""
+	}
+
+	v, err := vs.Version()
+	if err != nil {
+		return ""
+	}
+
+	return v
+}
+
+// unchangedKey reports whether the key material of k can be taken from the
+// previously loaded prev without decrypting it again.
+func unchangedKey(prev, k *Key) bool {
+	return !k.UpdatedAt.IsZero() && k.UpdatedAt.Equal(prev.UpdatedAt)
+}
+
+func (km *KeyManager) listKeys(ctx context.Context) (keys []*Key, err error) {
+	ctx, span := km.startSpan(ctx, SpanStoreList)
+	defer func() { span.End(err) }()
+
+	if cs, ok := km.store.(ContextStore); ok {
+		return cs.ListContext(ctx)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return km.store.List()
+}
+
+func (km *KeyManager) loadKey(ctx context.Context, k *Key) (*CachedKey, error) {
+	if k.Alg.symmetric() {
+		return km.loadSecret(ctx, k)
+	}
+
+	if km.verifyOnly {
+		if len(k.PublicKey) == 0 {
+			return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: errors.New("no public key stored")}
+		}
+
+		pub, err := parsePublicKey(k.PublicKey)
+		if err != nil {
+			return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+		}
+
+		return &CachedKey{key: k, pub: pub}, nil
+	}
+
+	privBytes, err := km.decrypt(ctx, k)
+	if err != nil {
+		if plaintextMaterial(k) {
+			return km.loadPlaintext(ctx, k)
+		}
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+	}
+
+	priv, err := parsePrivateKey(privBytes)
+	wipeBytes(privBytes)
+	if err != nil {
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+	}
+
+	return &CachedKey{
+		key:      k,
+		priv:     priv,
+		pub:      priv.Public(),
+		ctDigest: digestCiphertext(k.EncryptedKey),
+	}, nil
+}
+
+func (km *KeyManager) InitKeys(algs []Alg) error {
+	return km.InitKeysWithPurposeCtx(context.Background(), PurposeDefault, algs)
+}
+
+func (km *KeyManager) InitKeysCtx(ctx context.Context, algs []Alg) error {
+	return km.InitKeysWithPurposeCtx(ctx, PurposeDefault, algs)
+}
+
+func (km *KeyManager) InitKeysWithPurpose(purpose Purpose, algs []Alg) error {
+	return km.InitKeysWithPurposeCtx(context.Background(), purpose, algs)
+}
+
+// InitKeysWithPurposeCtx creates an active key for every alg of purpose
+// that lacks one. The algs are rotated concurrently; the ones that fail are
+// reported together in an *InitKeysError, the others keep their new keys.
+func (km *KeyManager) InitKeysWithPurposeCtx(ctx context.Context, purpose Purpose, algs []Alg) error {
+	var missing []Alg
+	for _, alg := range algs {
+		s := keySlot{alg: alg, purpose: purpose}
+		km.initSlots.Store(s, struct{}{})
+
+		_, exists := km.snapshot().active[s]
+
+		if exists || slices.Contains(missing, alg) {
+			continue
+		}
+		missing = append(missing, alg)
+	}
+
+	errs := make([]error, len(missing))
+
+	var wg sync.WaitGroup
+	for i, alg := range missing {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = km.RotateWithPurposeCtx(ctx, alg, purpose)
+		}()
+	}
+	wg.Wait()
+
+	failed := make(map[Alg]error)
+	for i, err := range errs {
+		if err != nil {
+			failed[missing[i]] = err
+		}
+	}
+
+	if len(failed) > 0 {
+		return &InitKeysError{Purpose: purpose, Failed: failed}
+	}
+
+	return nil
+}
+
+func (km *KeyManager) Signer(kid string) (crypto.Signer, error) {
+	return km.SignerCtx(context.Background(), kid)
+}
+
+func (km *KeyManager) SignerCtx(ctx context.Context, kid string) (crypto.Signer, error) {
+	ck := km.keyByKIDCtx(ctx, kid)
+	if ck == nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	ck, err := km.materialize(ck)
+	if err != nil {
+		return nil, err
+	}
+
+	if ck.priv == nil {
+		return nil, ErrVerifyOnly
+	}
+	if ck.key.RevokedAt != nil || km.listRevoked(kid) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRevoked, kid)
+	}
+	if err := km.checkExpiry(ck.key); err != nil {
+		return nil, err
+	}
+	if err := km.authorize(ctx, ActionSign, ck.key); err != nil {
+		return nil, err
+	}
+
+	return km.handOut(ck)
+}
+
+// handOut returns a copy of the private key of ck for callers outside the
+// manager, so wiping the cached key never zeroes a signer in use.
+func (km *KeyManager) handOut(ck *CachedKey) (crypto.Signer, error) {
+	km.material.RLock()
+	defer km.material.RUnlock()
+
+	if signerWiped(ck.priv) {
+		return nil, fmt.Errorf("%w: %s was retired", ErrKeyNotFound, ck.key.KID)
+	}
+	return cloneSigner(ck.priv)
+}
+
+func (km *KeyManager) GetPublicKey(kid string) (crypto.PublicKey, error) {
+	ck := km.keyByKID(kid)
+	if ck == nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	return ck.pub, nil
+}
+
+func (km *KeyManager) ActiveSigner(alg Alg) (crypto.Signer, error) {
+	return km.ActiveSignerCtx(context.Background(), alg)
+}
+
+func (km *KeyManager) ActiveSignerCtx(ctx context.Context, alg Alg) (crypto.Signer, error) {
+	ck := km.signingKey(ctx, keySlot{alg: alg})
+	if ck == nil {
+		return nil, fmt.Errorf("%w for alg %s", ErrNoActiveKey, alg)
+	}
+
+	if ck.priv == nil {
+		return nil, ErrVerifyOnly
+	}
+	if ck.key.RevokedAt != nil || km.listRevoked(ck.key.KID) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRevoked, ck.key.KID)
+	}
+	if err := km.checkExpiry(ck.key); err != nil {
+		return nil, err
+	}
+	if err := km.authorize(ctx, ActionSign, ck.key); err != nil {
+		return nil, err
+	}
+
+	return km.handOut(ck)
+}
+
+func (km *KeyManager) Keys() []KeyInfo {
+	cache := km.snapshot().cache
+
+	out := make([]KeyInfo, 0, len(cache))
+	for _, ck := range cache {
+		out = append(out, km.keyInfo(ck))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].KID < out[j].KID
+	})
+
+	return out
+}
+
+// ActiveKIDs returns the KID of the active key of every alg with one, for
+// the default purpose and no labels, e.g. to embed in discovery metadata.
+func (km *KeyManager) ActiveKIDs() map[Alg]string {
+	out := make(map[Alg]string)
+	for s, ck := range km.snapshot().active {
+		if s.purpose == PurposeDefault && s.labels == "" {
+			out[s.alg] = ck.key.KID
+		}
+	}
+	return out
+}
+
+func (km *KeyManager) ActiveKeyInfo(alg Alg) (KeyInfo, error) {
+	return km.ActiveKeyInfoCtx(context.Background(), alg)
+}
+
+// ActiveKeyInfoCtx describes the key Sign would currently use for alg.
+func (km *KeyManager) ActiveKeyInfoCtx(ctx context.Context, alg Alg) (KeyInfo, error) {
+	ck := km.signingKey(ctx, keySlot{alg: alg})
+	if ck == nil {
+		return KeyInfo{}, fmt.Errorf("%w for alg %s", ErrNoActiveKey, alg)
+	}
+	return km.keyInfo(ck), nil
+}
+
+// KeyInfoByKIDCtx describes the key kid without listing the whole key set.
+func (km *KeyManager) KeyInfoByKIDCtx(ctx context.Context, kid string) (KeyInfo, error) {
+	ck := km.keyByKIDCtx(ctx, kid)
+	if ck == nil {
+		return KeyInfo{}, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+	return km.keyInfo(ck), nil
+}
+
+func (km *KeyManager) keyInfo(ck *CachedKey) KeyInfo {
+	info := ck.info()
+	info.VerifyUntil = km.verifyUntil(ck.key)
+	return info
+}
+
+func (ck *CachedKey) info() KeyInfo {
+	return KeyInfo{
+		KID:            ck.key.KID,
+		Alg:            ck.key.Alg,
+		Purpose:        ck.key.Purpose,
+		Labels:         ck.key.Labels,
+		State:          keyState(ck.key),
+		CreatedAt:      ck.key.CreatedAt,
+		ExpiresAt:      ck.key.ExpiresAt,
+		SignCount:      ck.usage.signs.Load(),
+		VerifyCount:    ck.usage.verifies.Load(),
+		LastSignedAt:   unixTime(ck.usage.lastSign.Load()),
+		LastVerifiedAt: unixTime(ck.usage.lastVerify.Load()),
+		Attestation:    ck.key.Attestation,
+	}
+}
+
+// QuarantinedKeys returns the keys skipped by the last reload in lenient
+// mode because they could not be decrypted or parsed.
+func (km *KeyManager) QuarantinedKeys() []QuarantinedKey {
+	q := km.snapshot().quarantine
+
+	out := make([]QuarantinedKey, len(q))
+	copy(out, q)
+
+	return out
+}
+
+func (km *KeyManager) RetryQuarantined() ([]QuarantinedKey, error) {
+	return km.RetryQuarantinedCtx(context.Background())
+}
+
+// RetryQuarantinedCtx reloads the store even if its version is unchanged so
+// that quarantined keys are decrypted and parsed again, for example after a
+// missing master key was added to the encryptor. It returns the keys that are
+// still quarantined.
+func (km *KeyManager) RetryQuarantinedCtx(ctx context.Context) ([]QuarantinedKey, error) {
+	if len(km.snapshot().quarantine) == 0 {
+		return nil, nil
+	}
+
+	if err := km.reloadStore(withReloadCause(ctx, ReloadExplicit), true); err != nil {
+		return nil, err
+	}
+
+	return km.QuarantinedKeys(), nil
 }
@@ -0,0 +1,71 @@
+package keys_manager
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestClock_RotateUsesInjectedTime(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	store := NewMockStore()
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	keys, _ := store.List()
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+
+	k := keys[0]
+	if !k.CreatedAt.Equal(clock.now) {
+		t.Fatalf("CreatedAt = %v, want %v", k.CreatedAt, clock.now)
+	}
+
+	if k.ExpiresAt == nil || !k.ExpiresAt.Equal(clock.now.Add(time.Hour)) {
+		t.Fatalf("ExpiresAt = %v, want %v", k.ExpiresAt, clock.now.Add(time.Hour))
+	}
+}
+
+func TestClock_RotateExpiredFollowsInjectedTime(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithClock(clock))
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	if err := km.RotateExpired(); err != nil {
+		t.Fatalf("RotateExpired error: %v", err)
+	}
+
+	if store.RotateCount != 1 {
+		t.Fatalf("key must not rotate before expiry, rotations: %d", store.RotateCount)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+
+	if err := km.RotateExpired(); err != nil {
+		t.Fatalf("RotateExpired error: %v", err)
+	}
+
+	if store.RotateCount != 2 {
+		t.Fatalf("expired key must rotate, rotations: %d", store.RotateCount)
+	}
+}
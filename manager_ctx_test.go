@@ -0,0 +1,108 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type slowStore struct {
+	*MockStore
+	delay time.Duration
+}
+
+func (s *slowStore) ListContext(ctx context.Context) ([]*Key, error) {
+	select {
+	case <-time.After(s.delay):
+		return s.MockStore.List()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestSignCtx_VerifyCtx(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgES256})
+
+	ctx := context.Background()
+	data := []byte("payload")
+
+	var kid string
+	sig, err := km.SignCtx(ctx, AlgES256, func(k string) ([]byte, error) {
+		kid = k
+		return data, nil
+	})
+	if err != nil {
+		t.Fatalf("SignCtx error: %v", err)
+	}
+
+	if err := km.VerifyCtx(ctx, kid, data, sig); err != nil {
+		t.Fatalf("VerifyCtx error: %v", err)
+	}
+}
+
+func TestSignCtx_CanceledContext(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := km.SignCtx(ctx, AlgEdDSA, func(_ string) ([]byte, error) {
+		t.Fatalf("build must not run with a canceled context")
+		return nil, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestVerifyCtx_ReloadRespectsDeadline(t *testing.T) {
+	store := &slowStore{MockStore: NewMockStore()}
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	store.delay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = km.VerifyCtx(ctx, "unknown", []byte("x"), []byte("sig"))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("VerifyCtx did not respect the deadline")
+	}
+}
+
+func TestSignWithKIDCtx_ReloadRespectsDeadline(t *testing.T) {
+	store := &slowStore{MockStore: NewMockStore()}
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	store.delay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = km.SignWithKIDCtx(ctx, "unknown", func(string) ([]byte, error) { return []byte("x"), nil })
+
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("SignWithKIDCtx did not respect the deadline")
+	}
+}
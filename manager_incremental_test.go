@@ -0,0 +1,131 @@
+package keys_manager
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type versionedStore struct {
+	*MockStore
+	version atomic.Int64
+	lists   atomic.Int64
+}
+
+func (s *versionedStore) Version() (string, error) {
+	return strconv.FormatInt(s.version.Load(), 10), nil
+}
+
+func (s *versionedStore) List() ([]*Key, error) {
+	s.lists.Add(1)
+	return s.MockStore.List()
+}
+
+func (s *versionedStore) Save(k *Key) error {
+	defer s.version.Add(1)
+	return s.MockStore.Save(k)
+}
+
+func (s *versionedStore) Rotate(newKey, oldKey *Key) error {
+	defer s.version.Add(1)
+	return s.MockStore.Rotate(newKey, oldKey)
+}
+
+type countingEncryptor struct {
+	MockEncryptor
+	decrypts *atomic.Int64
+}
+
+func (e countingEncryptor) Decrypt(k *EncryptedKey) ([]byte, error) {
+	e.decrypts.Add(1)
+	return e.MockEncryptor.Decrypt(k)
+}
+
+func TestIncrementalReload_SkipsUnchangedStore(t *testing.T) {
+	store := &versionedStore{MockStore: NewMockStore()}
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	lists := store.lists.Load()
+
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+	km.keyByKID("unknown")
+
+	if got := store.lists.Load(); got != lists {
+		t.Fatalf("unchanged store must not be listed again, got %d extra lists", got-lists)
+	}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	_ = store.Save(makeTestKey("added", AlgEdDSA, false, nil, MockEncryptor{}, priv))
+
+	if km.keyByKID("added") == nil {
+		t.Fatalf("key added after version change must be loaded")
+	}
+}
+
+func TestIncrementalReload_ReusesUnchangedKeys(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+
+	now := time.Now()
+	for _, kid := range []string{"a", "b", "c"} {
+		priv, _ := generatePrivateKey(AlgEdDSA)
+		k := makeTestKey(kid, AlgEdDSA, false, nil, enc, priv)
+		k.UpdatedAt = now
+		store.Save(k)
+	}
+
+	km, _ := NewKeyManager(store, enc, mockPolicy)
+	if got := decrypts.Load(); got != 3 {
+		t.Fatalf("expected 3 decrypts on first load, got %d", got)
+	}
+
+	_ = km.ReloadCache()
+	if got := decrypts.Load(); got != 3 {
+		t.Fatalf("unchanged keys must not be decrypted again, got %d decrypts", got)
+	}
+
+	keys, _ := store.List()
+	for _, k := range keys {
+		if k.KID == "b" {
+			priv, _ := generatePrivateKey(AlgEdDSA)
+			changed := makeTestKey("b", AlgEdDSA, true, nil, enc, priv)
+			changed.UpdatedAt = now.Add(time.Second)
+			store.Save(changed)
+		}
+	}
+	decrypts.Store(0)
+
+	_ = km.ReloadCache()
+	if got := decrypts.Load(); got != 1 {
+		t.Fatalf("only the changed key must be decrypted, got %d decrypts", got)
+	}
+
+	if ck := km.activeKey(AlgEdDSA); ck == nil || ck.key.KID != "b" {
+		t.Fatalf("changed metadata must be picked up, got %v", ck)
+	}
+}
+
+func TestIncrementalReload_KeysWithoutUpdatedAtUseCiphertextMemo(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("legacy", AlgEdDSA, true, nil, enc, priv))
+
+	km, _ := NewKeyManager(store, enc, mockPolicy)
+	_ = km.ReloadCache()
+
+	if got := decrypts.Load(); got != 1 {
+		t.Fatalf("keys without UpdatedAt must be reused by ciphertext, got %d decrypts", got)
+	}
+}
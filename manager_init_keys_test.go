@@ -1,6 +1,7 @@
 package keys_manager
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -93,3 +94,64 @@ func TestInitKeys_RotateFails(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+type barrierStore struct {
+	*MockStore
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func (s *barrierStore) Rotate(newKey, oldKey *Key) error {
+	s.arrived <- struct{}{}
+	<-s.release
+	return s.MockStore.Rotate(newKey, oldKey)
+}
+
+func TestInitKeys_RotatesAlgsConcurrently(t *testing.T) {
+	store := &barrierStore{MockStore: NewMockStore(), arrived: make(chan struct{}), release: make(chan struct{})}
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	done := make(chan error)
+	go func() { done <- km.InitKeysCtx(context.Background(), []Alg{AlgES256, AlgEdDSA, AlgES256}) }()
+
+	for range 2 {
+		select {
+		case <-store.arrived:
+		case <-time.After(5 * time.Second):
+			t.Fatal("rotations of distinct algs must run concurrently")
+		}
+	}
+	close(store.release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("InitKeysCtx error: %v", err)
+	}
+	if store.RotateCount != 2 {
+		t.Fatalf("expected one rotation per distinct alg, got %d", store.RotateCount)
+	}
+	for _, alg := range []Alg{AlgES256, AlgEdDSA} {
+		if km.activeKey(alg) == nil {
+			t.Fatalf("missing active %s key after InitKeysCtx", alg)
+		}
+	}
+}
+
+func TestInitKeys_ReportsFailedAlgs(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	err := km.InitKeys([]Alg{AlgEdDSA, "HS1", "none"})
+
+	var initErr *InitKeysError
+	if !errors.As(err, &initErr) {
+		t.Fatalf("expected *InitKeysError, got %v", err)
+	}
+	if len(initErr.Failed) != 2 || initErr.Failed["HS1"] == nil || initErr.Failed["none"] == nil {
+		t.Fatalf("expected HS1 and none to fail, got %v", initErr.Failed)
+	}
+	if !errors.Is(err, ErrUnsupportedAlg) {
+		t.Fatalf("expected wrapped ErrUnsupportedAlg, got %v", err)
+	}
+	if km.activeKey(AlgEdDSA) == nil {
+		t.Fatalf("algs that succeeded must keep their keys")
+	}
+}
@@ -0,0 +1,82 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeys_ListsMetadataAndUsage(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(time.Hour)
+	old := makeTestKey("old", AlgEdDSA, true, &exp, enc, priv)
+	old.CreatedAt = time.Now().Add(-time.Hour)
+	store.Save(old)
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	data := []byte("payload")
+	sig, err := km.Sign(AlgEdDSA, func(_ string) ([]byte, error) { return data, nil })
+	if err != nil {
+		t.Fatalf("sign error: %v", err)
+	}
+
+	if err := km.Verify("old", data, sig); err != nil {
+		t.Fatalf("verify error: %v", err)
+	}
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	keys := km.Keys()
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+
+	if keys[0].State != KeyStateActive || keys[0].KID == "old" {
+		t.Fatalf("expected the new active key first, got %+v", keys[0])
+	}
+
+	info := keys[1]
+	if info.KID != "old" || info.State != KeyStateInactive {
+		t.Fatalf("unexpected info for old key: %+v", info)
+	}
+
+	if info.SignCount != 1 || info.VerifyCount != 1 {
+		t.Fatalf("usage counters must survive reloads, got sign=%d verify=%d", info.SignCount, info.VerifyCount)
+	}
+
+	if info.ExpiresAt == nil || !info.ExpiresAt.Equal(exp) {
+		t.Fatalf("ExpiresAt not propagated: %v", info.ExpiresAt)
+	}
+}
+
+func TestActiveKIDs(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgES256})
+	_ = km.InitKeysWithPurpose(PurposeWebhook, []Alg{AlgEdDSA})
+
+	kids := km.ActiveKIDs()
+	if len(kids) != 2 || kids[AlgEdDSA] == "" || kids[AlgES256] == "" {
+		t.Fatalf("expected active KIDs for the default purpose only, got %v", kids)
+	}
+
+	info, err := km.ActiveKeyInfo(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("ActiveKeyInfo error: %v", err)
+	}
+	if info.KID != kids[AlgEdDSA] || info.State != KeyStateActive {
+		t.Fatalf("unexpected active key info: %+v", info)
+	}
+
+	if _, err := km.ActiveKeyInfo(AlgRS256); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey, got %v", err)
+	}
+}
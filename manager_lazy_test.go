@@ -0,0 +1,144 @@
+package keys_manager
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLazyDecrypt_DefersInactiveKeys(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+
+	signer, _ := NewKeyManager(store, enc, mockPolicy)
+	_ = signer.InitKeys([]Alg{AlgEdDSA})
+
+	data := []byte("payload")
+	var oldKID string
+	oldSig, _ := signer.Sign(AlgEdDSA, func(k string) ([]byte, error) {
+		oldKID = k
+		return data, nil
+	})
+
+	_ = signer.Rotate(AlgEdDSA)
+	_ = signer.Rotate(AlgEdDSA)
+
+	decrypts.Store(0)
+
+	km, err := NewKeyManager(store, enc, mockPolicy, WithLazyDecrypt())
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if got := decrypts.Load(); got != 1 {
+		t.Fatalf("only the active key must be decrypted, got %d decrypts", got)
+	}
+
+	if err := km.Verify(oldKID, data, oldSig); err != nil {
+		t.Fatalf("Verify with lazily cached key failed: %v", err)
+	}
+
+	raw, _ := km.JWKS()
+	var jwks JWKS
+	_ = json.Unmarshal(raw, &jwks)
+	if len(jwks.Keys) != 3 {
+		t.Fatalf("expected all 3 keys in JWKS, got %d", len(jwks.Keys))
+	}
+
+	if got := decrypts.Load(); got != 1 {
+		t.Fatalf("Verify and JWKS must not decrypt, got %d decrypts", got)
+	}
+
+	build := func(_ string) ([]byte, error) { return data, nil }
+
+	sig, err := km.SignWithKID(oldKID, build)
+	if err != nil {
+		t.Fatalf("SignWithKID on deferred key failed: %v", err)
+	}
+
+	if !ed25519.Verify(mustPublic(t, km, oldKID), data, sig) {
+		t.Fatalf("signature from materialized key does not verify")
+	}
+
+	if _, err := km.SignWithKID(oldKID, build); err != nil {
+		t.Fatalf("second SignWithKID failed: %v", err)
+	}
+
+	if got := decrypts.Load(); got != 2 {
+		t.Fatalf("deferred key must be decrypted exactly once, got %d decrypts", got)
+	}
+
+	for _, info := range km.Keys() {
+		if info.KID == oldKID && info.SignCount != 2 {
+			t.Fatalf("usage must survive materialization, got %d signs", info.SignCount)
+		}
+	}
+}
+
+func mustPublic(t *testing.T, km *KeyManager, kid string) ed25519.PublicKey {
+	t.Helper()
+
+	pub, err := km.GetPublicKey(kid)
+	if err != nil {
+		t.Fatalf("GetPublicKey error: %v", err)
+	}
+
+	return pub.(ed25519.PublicKey)
+}
+
+func TestLazyDecrypt_KeysWithoutPublicKeyLoadEagerly(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("legacy", AlgEdDSA, false, nil, enc, priv))
+
+	km, _ := NewKeyManager(store, enc, mockPolicy, WithLazyDecrypt())
+
+	if got := decrypts.Load(); got != 1 {
+		t.Fatalf("legacy key without public key must be decrypted, got %d", got)
+	}
+
+	if _, err := km.Signer("legacy"); err != nil {
+		t.Fatalf("Signer error: %v", err)
+	}
+}
+
+func TestLazyDecrypt_WarmStandbyPreloadsLastRetiredKey(t *testing.T) {
+	var decrypts atomic.Int64
+	enc := countingEncryptor{decrypts: &decrypts}
+	store := NewMockStore()
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	signer, _ := NewKeyManager(store, enc, mockPolicy, WithClock(clock))
+
+	var kids []string
+	for i := 0; i < 3; i++ {
+		_ = signer.Rotate(AlgEdDSA)
+		kids = append(kids, signer.activeKey(AlgEdDSA).key.KID)
+		clock.now = clock.now.Add(time.Minute)
+	}
+
+	decrypts.Store(0)
+
+	km, err := NewKeyManager(store, enc, mockPolicy, WithClock(clock), WithLazyDecrypt(), WithWarmStandby())
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if got := decrypts.Load(); got != 2 {
+		t.Fatalf("expected the active and the standby key to be decrypted, got %d", got)
+	}
+
+	cache := km.snapshot().cache
+	if cache[kids[1]].deferred {
+		t.Fatalf("expected most recently retired key %s to be preloaded", kids[1])
+	}
+	if !cache[kids[0]].deferred {
+		t.Fatalf("expected older key %s to stay deferred", kids[0])
+	}
+}
@@ -0,0 +1,136 @@
+package keys_manager
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLenientReload_QuarantinesBrokenKeys(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(time.Hour)
+	store.Save(makeTestKey("good", AlgEdDSA, true, &exp, enc, priv))
+	store.Save(&Key{
+		KID:          "broken",
+		Alg:          AlgRS256,
+		IsActive:     true,
+		EncryptedKey: &EncryptedKey{Ciphertext: []byte("not a pkcs8 key")},
+	})
+
+	if _, err := NewKeyManager(store, enc, mockPolicy); err == nil {
+		t.Fatalf("strict reload must fail on a broken key")
+	}
+
+	km, err := NewKeyManager(store, enc, mockPolicy, WithLenientReload())
+	if err != nil {
+		t.Fatalf("lenient reload must not fail: %v", err)
+	}
+
+	if _, err := km.Sign(AlgEdDSA, func(_ string) ([]byte, error) {
+		return []byte("data"), nil
+	}); err != nil {
+		t.Fatalf("healthy alg must keep signing: %v", err)
+	}
+
+	if _, err := km.Sign(AlgRS256, func(_ string) ([]byte, error) {
+		return []byte("data"), nil
+	}); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey for quarantined alg, got %v", err)
+	}
+
+	q := km.QuarantinedKeys()
+	if len(q) != 1 || q[0].KID != "broken" || q[0].Alg != AlgRS256 {
+		t.Fatalf("unexpected quarantine: %+v", q)
+	}
+
+	var keyErr *KeyError
+	if !errors.As(q[0].Err, &keyErr) {
+		t.Fatalf("expected KeyError cause, got %v", q[0].Err)
+	}
+}
+
+func TestLenientReload_ClearsQuarantineAfterRepair(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	store.Save(&Key{
+		KID:          "broken",
+		Alg:          AlgEdDSA,
+		EncryptedKey: &EncryptedKey{Ciphertext: []byte("garbage")},
+	})
+
+	km, _ := NewKeyManager(store, enc, mockPolicy, WithLenientReload())
+	if len(km.QuarantinedKeys()) != 1 {
+		t.Fatalf("expected 1 quarantined key")
+	}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("broken", AlgEdDSA, false, nil, enc, priv))
+
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+
+	if q := km.QuarantinedKeys(); len(q) != 0 {
+		t.Fatalf("quarantine must be cleared after repair, got %+v", q)
+	}
+}
+
+type toggleEncryptor struct {
+	MockEncryptor
+	fail *atomic.Bool
+}
+
+func (e toggleEncryptor) Decrypt(k *EncryptedKey) ([]byte, error) {
+	if e.fail.Load() {
+		return nil, errors.New("master key unavailable")
+	}
+	return e.MockEncryptor.Decrypt(k)
+}
+
+func TestLenientReload_RetryQuarantined(t *testing.T) {
+	store := &versionedStore{MockStore: NewMockStore()}
+	fail := &atomic.Bool{}
+	enc := toggleEncryptor{fail: fail}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("k1", AlgEdDSA, true, nil, enc, priv))
+
+	fail.Store(true)
+	km, err := NewKeyManager(store, enc, mockPolicy, WithLenientReload())
+	if err != nil {
+		t.Fatalf("lenient reload must not fail: %v", err)
+	}
+
+	q := km.QuarantinedKeys()
+	if len(q) != 1 || q[0].KID != "k1" || q[0].Err == nil {
+		t.Fatalf("expected k1 quarantined with a cause, got %+v", q)
+	}
+
+	fail.Store(false)
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+	if len(km.QuarantinedKeys()) != 1 {
+		t.Fatalf("unchanged store version must not trigger a reload")
+	}
+
+	left, err := km.RetryQuarantined()
+	if err != nil {
+		t.Fatalf("RetryQuarantined error: %v", err)
+	}
+	if len(left) != 0 {
+		t.Fatalf("expected quarantine to be cleared, got %+v", left)
+	}
+	if km.activeKey(AlgEdDSA) == nil {
+		t.Fatalf("recovered key must become active")
+	}
+
+	if left, err := km.RetryQuarantined(); err != nil || left != nil {
+		t.Fatalf("retry with empty quarantine must be a no-op, got %v, %v", left, err)
+	}
+}
@@ -0,0 +1,49 @@
+package keys_manager
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetPublicKey(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(time.Hour)
+	store.Save(makeTestKey("k1", AlgEdDSA, true, &exp, enc, priv))
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	pub, err := km.GetPublicKey("k1")
+	if err != nil {
+		t.Fatalf("GetPublicKey error: %v", err)
+	}
+
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		t.Fatalf("expected ed25519.PublicKey, got %T", pub)
+	}
+
+	if !edPub.Equal(priv.Public()) {
+		t.Fatalf("returned public key does not match stored key")
+	}
+
+	msg := []byte("custom protocol")
+	if !ed25519.Verify(edPub, msg, ed25519.Sign(priv.(ed25519.PrivateKey), msg)) {
+		t.Fatalf("returned key cannot verify signatures")
+	}
+}
+
+func TestGetPublicKey_UnknownKID(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if _, err := km.GetPublicKey("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPurpose_IndependentKeyLines(t *testing.T) {
+	store := NewMockStore()
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.InitKeysWithPurpose(PurposeAccess, []Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeysWithPurpose(access) error: %v", err)
+	}
+	if err := km.InitKeysWithPurpose(PurposeWebhook, []Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeysWithPurpose(webhook) error: %v", err)
+	}
+
+	build := func(kid string) ([]byte, error) { return []byte(kid), nil }
+
+	var accessKID, webhookKID string
+	if _, err := km.SignWithPurpose(AlgEdDSA, PurposeAccess, func(kid string) ([]byte, error) {
+		accessKID = kid
+		return build(kid)
+	}); err != nil {
+		t.Fatalf("sign access error: %v", err)
+	}
+	if _, err := km.SignWithPurpose(AlgEdDSA, PurposeWebhook, func(kid string) ([]byte, error) {
+		webhookKID = kid
+		return build(kid)
+	}); err != nil {
+		t.Fatalf("sign webhook error: %v", err)
+	}
+
+	if accessKID == webhookKID {
+		t.Fatalf("purposes must use distinct keys, both used %s", accessKID)
+	}
+
+	if _, err := km.Sign(AlgEdDSA, build); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("default purpose has no key, expected ErrNoActiveKey, got %v", err)
+	}
+
+	if err := km.RotateWithPurpose(AlgEdDSA, PurposeAccess); err != nil {
+		t.Fatalf("RotateWithPurpose error: %v", err)
+	}
+
+	var rotatedAccessKID, sameWebhookKID string
+	_, _ = km.SignWithPurpose(AlgEdDSA, PurposeAccess, func(kid string) ([]byte, error) {
+		rotatedAccessKID = kid
+		return build(kid)
+	})
+	_, _ = km.SignWithPurpose(AlgEdDSA, PurposeWebhook, func(kid string) ([]byte, error) {
+		sameWebhookKID = kid
+		return build(kid)
+	})
+
+	if rotatedAccessKID == accessKID {
+		t.Fatalf("access key must change after rotation")
+	}
+	if sameWebhookKID != webhookKID {
+		t.Fatalf("webhook key must not change when access key rotates")
+	}
+
+	active := 0
+	for _, info := range km.Keys() {
+		if info.State == KeyStateActive {
+			active++
+		}
+	}
+	if active != 2 {
+		t.Fatalf("expected 2 active keys (one per purpose), got %d", active)
+	}
+}
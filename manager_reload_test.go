@@ -1,6 +1,10 @@
 package keys_manager
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
 
 func TestReloadCache(t *testing.T) {
 	store := NewMockStore()
@@ -46,11 +50,9 @@ func TestReloadCache(t *testing.T) {
 		t.Fatalf("expected active key k1, got %s", ck.key.KID)
 	}
 
-	km.mu.RLock()
-	if len(km.cache) != 2 {
-		t.Fatalf("expected 2 keys in cache, got %d", len(km.cache))
+	if n := len(km.snapshot().cache); n != 2 {
+		t.Fatalf("expected 2 keys in cache, got %d", n)
 	}
-	km.mu.RUnlock()
 
 	err = km.ReloadCache()
 	if err != nil {
@@ -84,9 +86,11 @@ func TestReloadCache_InvalidKey(t *testing.T) {
 	km := &KeyManager{
 		store:     store,
 		encryptor: enc,
-		active:    make(map[Alg]*CachedKey),
-		cache:     make(map[string]*CachedKey),
 	}
+	km.snap.Store(&cacheSnapshot{
+		active: make(map[keySlot]*CachedKey),
+		cache:  make(map[string]*CachedKey),
+	})
 
 	err := km.ReloadCache()
 	if err == nil {
@@ -138,3 +142,50 @@ func TestReloadCache_UpdatesChangedStore(t *testing.T) {
 		t.Fatalf("expected active k2 after reload, got %s", ck.key.KID)
 	}
 }
+
+// stallingStore returns what List read, but only once release is closed,
+// when armed.
+type stallingStore struct {
+	*MockStore
+	armed   chan struct{}
+	listed  chan struct{}
+	release chan struct{}
+}
+
+func (s *stallingStore) List() ([]*Key, error) {
+	keys, err := s.MockStore.List()
+	select {
+	case <-s.armed:
+		s.armed = nil
+		close(s.listed)
+		<-s.release
+	default:
+	}
+	return keys, err
+}
+
+func TestReloadCache_StaleListingIsDropped(t *testing.T) {
+	store := &stallingStore{MockStore: NewMockStore(), armed: make(chan struct{}), listed: make(chan struct{}), release: make(chan struct{})}
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	close(store.armed)
+	done := make(chan error)
+	go func() { done <- km.reload(context.Background()) }()
+	<-store.listed
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(time.Hour)
+	_ = store.Save(makeTestKey("new", AlgEdDSA, true, &exp, MockEncryptor{}, priv))
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+
+	close(store.release)
+	if err := <-done; err != nil {
+		t.Fatalf("stale reload error: %v", err)
+	}
+
+	if km.snapshot().cache["new"] == nil {
+		t.Fatalf("a slower reload must not replace a newer snapshot")
+	}
+}
@@ -0,0 +1,71 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignWithSelector_SharedStore(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	billing := map[string]string{"env": "prod", "service": "billing"}
+	search := map[string]string{"env": "prod", "service": "search"}
+
+	if err := km.RotateWithLabels(AlgEdDSA, billing); err != nil {
+		t.Fatalf("RotateWithLabels(billing) error: %v", err)
+	}
+	if err := km.RotateWithLabels(AlgEdDSA, search); err != nil {
+		t.Fatalf("RotateWithLabels(search) error: %v", err)
+	}
+
+	kidFor := func(expr string) string {
+		var kid string
+		_, err := km.SignWithSelector(AlgEdDSA, expr, func(k string) ([]byte, error) {
+			kid = k
+			return []byte("data"), nil
+		})
+		if err != nil {
+			t.Fatalf("SignWithSelector(%q) error: %v", expr, err)
+		}
+		return kid
+	}
+
+	billingKID := kidFor("env=prod,service=billing")
+	searchKID := kidFor("service=search")
+
+	if billingKID == searchKID {
+		t.Fatalf("services must sign with distinct keys")
+	}
+
+	if err := km.RotateWithLabels(AlgEdDSA, billing); err != nil {
+		t.Fatalf("second RotateWithLabels(billing) error: %v", err)
+	}
+
+	if kidFor("service=billing") == billingKID {
+		t.Fatalf("billing key must change after rotation")
+	}
+	if kidFor("service=search") != searchKID {
+		t.Fatalf("search key must not change when billing rotates")
+	}
+
+	active := 0
+	for _, info := range km.Keys() {
+		if info.State == KeyStateActive {
+			active++
+		}
+	}
+	if active != 2 {
+		t.Fatalf("expected 2 active keys, got %d", active)
+	}
+
+	build := func(_ string) ([]byte, error) { return []byte("data"), nil }
+
+	if _, err := km.SignWithSelector(AlgEdDSA, "service=payments", build); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey for unmatched selector, got %v", err)
+	}
+
+	if _, err := km.Sign(AlgEdDSA, build); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("unlabelled Sign must not pick labelled keys, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package keys_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignWithKID_RetiredKey(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgES256)
+	exp := time.Now().Add(time.Hour)
+	store.Save(makeTestKey("old", AlgES256, true, &exp, enc, priv))
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.Rotate(AlgES256); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	data := []byte("partner payload")
+
+	var usedKID string
+	sig, err := km.SignWithKID("old", func(kid string) ([]byte, error) {
+		usedKID = kid
+		return data, nil
+	})
+	if err != nil {
+		t.Fatalf("SignWithKID error: %v", err)
+	}
+
+	if usedKID != "old" {
+		t.Fatalf("build received kid %q, want %q", usedKID, "old")
+	}
+
+	if len(sig) != 64 {
+		t.Fatalf("expected RAW ES256 signature length 64, got %d", len(sig))
+	}
+
+	if err := km.Verify("old", data, sig); err != nil {
+		t.Fatalf("verify with retired key failed: %v", err)
+	}
+}
+
+func TestSignWithKID_UnknownKID(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	_, err := km.SignWithKID("missing", func(kid string) ([]byte, error) {
+		t.Fatalf("build must not be called for unknown kid")
+		return nil, nil
+	})
+	if err == nil {
+		t.Fatalf("expected error for unknown kid")
+	}
+}
@@ -0,0 +1,99 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSigner_ByKID(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgES256)
+	exp := time.Now().Add(time.Hour)
+	store.Save(makeTestKey("k1", AlgES256, false, &exp, enc, priv))
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	signer, err := km.Signer("k1")
+	if err != nil {
+		t.Fatalf("Signer error: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("payload"))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("sign error: %v", err)
+	}
+
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		t.Fatalf("expected ECDSA public key, got %T", signer.Public())
+	}
+
+	if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+		t.Fatalf("signature produced by Signer does not verify")
+	}
+}
+
+func TestSigner_UnknownKID(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if _, err := km.Signer("missing"); err == nil {
+		t.Fatalf("expected error for unknown kid")
+	}
+}
+
+func TestActiveSigner_IssuesCertificate(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	km, _ := NewKeyManager(store, enc, mockPolicy)
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	signer, err := km.ActiveSigner(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("ActiveSigner error: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, signer.Public(), signer)
+	if err != nil {
+		t.Fatalf("CreateCertificate error: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate error: %v", err)
+	}
+
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Fatalf("self-signed certificate does not verify: %v", err)
+	}
+}
+
+func TestActiveSigner_NoActiveKey(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if _, err := km.ActiveSigner(AlgRS256); err == nil {
+		t.Fatalf("expected error when no active key exists")
+	}
+}
@@ -0,0 +1,77 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSkew_RotateExpiredToleratesDrift(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := clock.now.Add(-30 * time.Second)
+	store.Save(makeTestKey("k1", AlgEdDSA, true, &exp, enc, priv))
+
+	km, err := NewKeyManager(store, enc, mockPolicy, WithClock(clock), WithSkew(time.Minute))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := km.RotateExpired(); err != nil {
+		t.Fatalf("RotateExpired error: %v", err)
+	}
+
+	if store.RotateCount != 0 {
+		t.Fatalf("key within skew must not be rotated, rotations: %d", store.RotateCount)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+
+	if err := km.RotateExpired(); err != nil {
+		t.Fatalf("RotateExpired error: %v", err)
+	}
+
+	if store.RotateCount != 1 {
+		t.Fatalf("key past skew must be rotated, rotations: %d", store.RotateCount)
+	}
+}
+
+func TestSkew_NotBefore(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	k := makeTestKey("k1", AlgEdDSA, true, nil, enc, priv)
+	nbf := clock.now.Add(30 * time.Second)
+	k.NotBefore = &nbf
+	store.Save(k)
+
+	build := func(_ string) ([]byte, error) { return []byte("data"), nil }
+
+	strict, _ := NewKeyManager(store, enc, mockPolicy, WithClock(clock))
+
+	if _, err := strict.Sign(AlgEdDSA, build); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("Sign before NotBefore: expected ErrNoActiveKey, got %v", err)
+	}
+
+	if err := strict.Verify("k1", []byte("data"), []byte("sig")); !errors.Is(err, ErrKeyNotYetValid) {
+		t.Fatalf("Verify before NotBefore: expected ErrKeyNotYetValid, got %v", err)
+	}
+
+	lenient, _ := NewKeyManager(store, enc, mockPolicy, WithClock(clock), WithSkew(time.Minute))
+
+	sig, err := lenient.Sign(AlgEdDSA, build)
+	if err != nil {
+		t.Fatalf("Sign within skew failed: %v", err)
+	}
+
+	if err := lenient.Verify("k1", []byte("data"), sig); err != nil {
+		t.Fatalf("Verify within skew failed: %v", err)
+	}
+}
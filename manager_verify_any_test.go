@@ -0,0 +1,112 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyAny_ActiveAndRetiredKeys(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgRS256)
+	exp := time.Now().Add(time.Hour)
+	store.Save(makeTestKey("old", AlgRS256, true, &exp, enc, priv))
+
+	km, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	data := []byte("legacy token")
+	build := func(_ string) ([]byte, error) { return data, nil }
+
+	oldSig, err := km.Sign(AlgRS256, build)
+	if err != nil {
+		t.Fatalf("sign with old key failed: %v", err)
+	}
+
+	if err := km.Rotate(AlgRS256); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	newSig, err := km.Sign(AlgRS256, build)
+	if err != nil {
+		t.Fatalf("sign with new key failed: %v", err)
+	}
+
+	if err := km.VerifyAny(AlgRS256, data, newSig); err != nil {
+		t.Fatalf("VerifyAny failed for active key signature: %v", err)
+	}
+
+	if err := km.VerifyAny(AlgRS256, data, oldSig); err != nil {
+		t.Fatalf("VerifyAny failed for retired key signature: %v", err)
+	}
+
+	if err := km.VerifyAny(AlgRS256, []byte("tampered"), newSig); err == nil {
+		t.Fatalf("VerifyAny passed for WRONG data")
+	}
+}
+
+func TestVerifyAny_NoKeysForAlg(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if err := km.VerifyAny(AlgEdDSA, []byte("x"), []byte("sig")); err == nil {
+		t.Fatalf("expected error when no keys exist for alg")
+	}
+}
+
+func TestVerifyAny_MismatchIsInvalidSignature(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	sig, _ := km.Sign(AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil })
+
+	if err := km.VerifyAny(AlgEdDSA, []byte("y"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature from VerifyAny, got %v", err)
+	}
+	kid := km.activeKey(AlgEdDSA).key.KID
+	if err := km.Verify(kid, []byte("y"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature from Verify, got %v", err)
+	}
+}
+
+func TestKeysForAlg_Order(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	now := time.Now()
+
+	for _, tc := range []struct {
+		kid     string
+		alg     Alg
+		active  bool
+		created time.Time
+	}{
+		{"older", AlgEdDSA, false, now.Add(-2 * time.Hour)},
+		{"newer", AlgEdDSA, false, now.Add(-time.Hour)},
+		{"active", AlgEdDSA, true, now.Add(-3 * time.Hour)},
+		{"other", AlgES256, true, now},
+	} {
+		priv, _ := generatePrivateKey(tc.alg)
+		k := makeTestKey(tc.kid, tc.alg, tc.active, nil, enc, priv)
+		k.CreatedAt = tc.created
+		store.Save(k)
+	}
+
+	km, _ := NewKeyManager(store, enc, mockPolicy)
+
+	got := km.keysForAlg(AlgEdDSA)
+	want := []string{"active", "newer", "older"}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d keys, got %d", len(want), len(got))
+	}
+
+	for i, ck := range got {
+		if ck.key.KID != want[i] {
+			t.Fatalf("position %d: got %s, want %s", i, ck.key.KID, want[i])
+		}
+	}
+}
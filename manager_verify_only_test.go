@@ -0,0 +1,99 @@
+package keys_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestVerifyOnly_VerifiesWithoutEncryptor(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	signer, err := NewKeyManager(store, enc, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	if err := signer.InitKeys([]Alg{AlgRS256, AlgES256, AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	verifier, err := NewKeyManager(store, nil, mockPolicy, WithVerifyOnly())
+	if err != nil {
+		t.Fatalf("verify-only NewKeyManager error: %v", err)
+	}
+
+	data := []byte("hello world")
+
+	for _, alg := range []Alg{AlgRS256, AlgES256, AlgEdDSA} {
+		var kid string
+		sig, err := signer.Sign(alg, func(k string) ([]byte, error) {
+			kid = k
+			return data, nil
+		})
+		if err != nil {
+			t.Fatalf("%s: sign error: %v", alg, err)
+		}
+
+		if err := verifier.Verify(kid, data, sig); err != nil {
+			t.Fatalf("%s: verify-only Verify failed: %v", alg, err)
+		}
+	}
+
+	raw, err := verifier.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS error: %v", err)
+	}
+
+	var jwks JWKS
+	if err := json.Unmarshal(raw, &jwks); err != nil {
+		t.Fatalf("JWKS unmarshal error: %v", err)
+	}
+
+	if len(jwks.Keys) != 3 {
+		t.Fatalf("expected 3 keys in JWKS, got %d", len(jwks.Keys))
+	}
+}
+
+func TestVerifyOnly_RejectsSigningAndRotation(t *testing.T) {
+	store := NewMockStore()
+
+	signer, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = signer.InitKeys([]Alg{AlgEdDSA})
+
+	verifier, err := NewKeyManager(store, nil, mockPolicy, WithVerifyOnly())
+	if err != nil {
+		t.Fatalf("verify-only NewKeyManager error: %v", err)
+	}
+
+	_, err = verifier.Sign(AlgEdDSA, func(_ string) ([]byte, error) {
+		return []byte("data"), nil
+	})
+	if !errors.Is(err, ErrVerifyOnly) {
+		t.Fatalf("Sign: expected ErrVerifyOnly, got %v", err)
+	}
+
+	if _, err := verifier.ActiveSigner(AlgEdDSA); !errors.Is(err, ErrVerifyOnly) {
+		t.Fatalf("ActiveSigner: expected ErrVerifyOnly, got %v", err)
+	}
+
+	if err := verifier.Rotate(AlgEdDSA); !errors.Is(err, ErrVerifyOnly) {
+		t.Fatalf("Rotate: expected ErrVerifyOnly, got %v", err)
+	}
+}
+
+func TestVerifyOnly_MissingPublicKey(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgRS256)
+	store.Save(makeTestKey("legacy", AlgRS256, true, nil, enc, priv))
+
+	_, err := NewKeyManager(store, nil, mockPolicy, WithVerifyOnly())
+
+	var keyErr *KeyError
+	if !errors.As(err, &keyErr) || keyErr.KID != "legacy" {
+		t.Fatalf("expected KeyError for legacy key, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package keys_manager
+
+import "context"
+
+// refreshIfStale reloads the cache before use once it is older than the
+// age set with WithCacheMaxAge. A failed reload is reported and the stale
+// cache keeps serving.
+func (km *KeyManager) refreshIfStale(ctx context.Context) {
+	if km.cacheMaxAge <= 0 || km.watching.Load() || !km.stale() {
+		return
+	}
+
+	err := km.reloads.do(ctx, func(ctx context.Context) error {
+		if !km.stale() || km.reloadedRecently() {
+			return nil
+		}
+		return km.reload(withReloadCause(ctx, ReloadStale))
+	})
+	km.logImplicitReload(ctx, "cache stale", err)
+	km.reportError(OpImplicitReload, "", err)
+}
+
+func (km *KeyManager) stale() bool {
+	last := km.reloadStats.lastOK.Load()
+	return last == 0 || km.clock.Now().UnixNano()-last >= int64(km.cacheMaxAge)
+}
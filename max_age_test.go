@@ -0,0 +1,35 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCacheMaxAge_ReloadsStaleCache(t *testing.T) {
+	store := NewMockStore()
+	clock := &fakeClock{now: time.Now()}
+
+	issuer, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithClock(clock))
+	_ = issuer.InitKeys([]Alg{AlgEdDSA})
+	kid := issuer.activeKey(AlgEdDSA).key.KID
+
+	payload := []byte("payload")
+	sig, _ := issuer.Sign(AlgEdDSA, func(string) ([]byte, error) { return payload, nil })
+
+	replica, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithClock(clock), WithCacheMaxAge(time.Minute))
+
+	if err := issuer.Revoke(kid); err != nil {
+		t.Fatalf("Revoke error: %v", err)
+	}
+
+	if err := replica.Verify(kid, payload, sig); err != nil {
+		t.Fatalf("fresh cache must still serve the old state, got %v", err)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+
+	if err := replica.Verify(kid, payload, sig); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("stale cache must be reloaded, expected ErrKeyRevoked, got %v", err)
+	}
+}
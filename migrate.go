@@ -0,0 +1,251 @@
+package keys_manager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+)
+
+var ErrMigrateConflict = errors.New("destination holds a different key with the same KID")
+
+type MigrateOptions struct {
+	// DryRun reports what would be copied without writing to dst.
+	DryRun bool
+
+	// Encryptor, when set, decrypts and parses every key before it is
+	// copied, so unreadable material is caught before the cutover.
+	Encryptor Encryptor
+
+	// Progress is called for every key with the action taken.
+	Progress func(kid string, action MigrateAction)
+}
+
+type MigrateAction string
+
+const (
+	MigrateCopied   MigrateAction = "copied"
+	MigrateSkipped  MigrateAction = "skipped"
+	MigrateConflict MigrateAction = "conflict"
+)
+
+// MigrateReport lists KIDs by the action MigrateStore took. With DryRun,
+// Copied lists the keys that would be copied.
+type MigrateReport struct {
+	Copied    []string
+	Skipped   []string
+	Conflicts []string
+}
+
+// MigrateStore copies every key of src into dst as is, ciphertext
+// included, then lists dst again and checks each copy. Keys already in dst
+// with identical content are skipped, so an interrupted migration can be
+// resumed by running it again. A KID stored differently in dst fails the
+// migration with ErrMigrateConflict before anything is written. dst must
+// implement KeySaver.
+func MigrateStore(src, dst Store, opts MigrateOptions) (*MigrateReport, error) {
+	saver, ok := dst.(KeySaver)
+	if !ok {
+		return nil, fmt.Errorf("migrate: destination %w: requires KeySaver", ErrStoreUnsupported)
+	}
+
+	keys, err := src.List()
+	if err != nil {
+		return nil, fmt.Errorf("migrate: list source: %w", err)
+	}
+
+	existing, err := dst.List()
+	if err != nil {
+		return nil, fmt.Errorf("migrate: list destination: %w", err)
+	}
+
+	stored := make(map[string]*Key, len(existing))
+	for _, k := range existing {
+		stored[k.KID] = k
+	}
+
+	// Active keys go last, so saving them retires nothing copied later.
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.IsActive != b.IsActive {
+			return b.IsActive
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.KID < b.KID
+	})
+
+	report := &MigrateReport{}
+	progress := func(kid string, action MigrateAction) {
+		if opts.Progress != nil {
+			opts.Progress(kid, action)
+		}
+	}
+
+	var toCopy []*Key
+	for _, k := range keys {
+		if have, ok := stored[k.KID]; ok {
+			if field := keyDiff(k, have); field != "" {
+				report.Conflicts = append(report.Conflicts, k.KID)
+				progress(k.KID, MigrateConflict)
+				continue
+			}
+			report.Skipped = append(report.Skipped, k.KID)
+			progress(k.KID, MigrateSkipped)
+			continue
+		}
+
+		if opts.Encryptor != nil {
+			if err := checkMaterial(opts.Encryptor, k); err != nil {
+				return report, fmt.Errorf("migrate: %w", err)
+			}
+		}
+		toCopy = append(toCopy, k)
+	}
+
+	if len(report.Conflicts) > 0 {
+		return report, fmt.Errorf("migrate: %w: %v", ErrMigrateConflict, report.Conflicts)
+	}
+
+	for _, k := range toCopy {
+		if !opts.DryRun {
+			if err := saver.Save(cloneKey(k)); err != nil {
+				return report, fmt.Errorf("migrate: save key %s: %w", k.KID, err)
+			}
+		}
+		report.Copied = append(report.Copied, k.KID)
+		progress(k.KID, MigrateCopied)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	return report, verifyMigration(keys, dst)
+}
+
+func verifyMigration(want []*Key, dst Store) error {
+	copied, err := dst.List()
+	if err != nil {
+		return fmt.Errorf("migrate: verify: %w", err)
+	}
+
+	got := make(map[string]*Key, len(copied))
+	for _, k := range copied {
+		got[k.KID] = k
+	}
+
+	var errs []error
+	for _, k := range want {
+		have, ok := got[k.KID]
+		if !ok {
+			errs = append(errs, &KeyError{KID: k.KID, Alg: k.Alg, Err: errors.New("missing from destination")})
+			continue
+		}
+		if field := keyDiff(k, have); field != "" {
+			errs = append(errs, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%s differs in destination", field)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("migrate: verify: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// checkMaterial decrypts and parses the key material of k.
+func checkMaterial(enc Encryptor, k *Key) error {
+	if k.EncryptedKey == nil {
+		return &KeyError{KID: k.KID, Alg: k.Alg, Err: errors.New("no encrypted material")}
+	}
+
+	plain, err := enc.Decrypt(k.EncryptedKey)
+	if err != nil {
+		if plaintextMaterial(k) {
+			return &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, ErrPlaintextKey)}
+		}
+		return &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, err)}
+	}
+	defer wipeBytes(plain)
+
+	if k.Alg.symmetric() {
+		return nil
+	}
+
+	priv, err := parsePrivateKey(plain)
+	if err != nil {
+		return &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+	}
+	wipeSigner(priv)
+
+	return nil
+}
+
+// keyDiff names the first persisted field that differs between a and b,
+// or returns "". UpdatedAt is ignored as stores may set it on save.
+func keyDiff(a, b *Key) string {
+	switch {
+	case a.Alg != b.Alg:
+		return "alg"
+	case a.Purpose != b.Purpose:
+		return "purpose"
+	case !maps.Equal(a.Labels, b.Labels):
+		return "labels"
+	case a.Environment != b.Environment:
+		return "environment"
+	case a.IsActive != b.IsActive:
+		return "is_active"
+	case a.Pending != b.Pending:
+		return "pending"
+	case a.Staged != b.Staged:
+		return "staged"
+	case a.Priority != b.Priority:
+		return "priority"
+	case a.Weight != b.Weight:
+		return "weight"
+	case !a.CreatedAt.Equal(b.CreatedAt):
+		return "created_at"
+	case !equalTime(a.NotBefore, b.NotBefore):
+		return "not_before"
+	case !equalTime(a.ExpiresAt, b.ExpiresAt):
+		return "expires_at"
+	case !equalTime(a.RevokedAt, b.RevokedAt):
+		return "revoked_at"
+	case !equalEncrypted(a.EncryptedKey, b.EncryptedKey):
+		return "encrypted_key"
+	case !bytes.Equal(a.PublicKey, b.PublicKey):
+		return "public_key"
+	case !bytes.Equal(a.Certificate, b.Certificate):
+		return "certificate"
+	case !bytes.Equal(a.Escrow, b.Escrow):
+		return "escrow"
+	}
+	return ""
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalEncrypted(a, b *EncryptedKey) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return bytes.Equal(a.Nonce, b.Nonce) && bytes.Equal(a.Ciphertext, b.Ciphertext)
+}
+
+func cloneKey(k *Key) *Key {
+	c := *k
+	c.Labels = maps.Clone(k.Labels)
+	if k.EncryptedKey != nil {
+		ek := *k.EncryptedKey
+		c.EncryptedKey = &ek
+	}
+	return &c
+}
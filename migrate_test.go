@@ -0,0 +1,71 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigrateStore_CopiesAndResumes(t *testing.T) {
+	src := NewMockStore()
+	km, _ := NewKeyManager(src, MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgES256})
+	_ = km.Rotate(AlgEdDSA)
+
+	dst := NewMockStore()
+
+	report, err := MigrateStore(src, dst, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run error: %v", err)
+	}
+	if len(report.Copied) != 3 {
+		t.Fatalf("dry run must report 3 keys to copy, got %+v", report)
+	}
+	if keys, _ := dst.List(); len(keys) != 0 {
+		t.Fatalf("dry run must not write")
+	}
+
+	srcKeys, _ := src.List()
+	_ = dst.Save(cloneKey(srcKeys[0]))
+
+	var actions []MigrateAction
+	report, err = MigrateStore(src, dst, MigrateOptions{
+		Encryptor: MockEncryptor{},
+		Progress:  func(kid string, a MigrateAction) { actions = append(actions, a) },
+	})
+	if err != nil {
+		t.Fatalf("MigrateStore error: %v", err)
+	}
+	if len(report.Skipped) != 1 || len(report.Copied) != 2 || len(actions) != 3 {
+		t.Fatalf("already copied key must be skipped, got %+v", report)
+	}
+
+	migrated, err := NewKeyManager(dst, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager on destination error: %v", err)
+	}
+	if got, want := migrated.activeKey(AlgEdDSA).key.KID, km.activeKey(AlgEdDSA).key.KID; got != want {
+		t.Fatalf("active key must survive migration, got %s want %s", got, want)
+	}
+
+	_ = km.Rotate(AlgEdDSA)
+	if _, err := MigrateStore(src, dst, MigrateOptions{}); !errors.Is(err, ErrMigrateConflict) {
+		t.Fatalf("key retired in source after copy must conflict, got %v", err)
+	}
+}
+
+func TestMigrateStore_ChecksMaterial(t *testing.T) {
+	src := NewMockStore()
+	_ = src.Save(&Key{KID: "broken", Alg: AlgEdDSA, IsActive: true, EncryptedKey: &EncryptedKey{Ciphertext: []byte("junk")}})
+
+	dst := NewMockStore()
+	if _, err := MigrateStore(src, dst, MigrateOptions{Encryptor: MockEncryptor{}}); err == nil {
+		t.Fatalf("unreadable key material must fail the migration")
+	}
+	if keys, _ := dst.List(); len(keys) != 0 {
+		t.Fatalf("nothing must be copied when a key fails the check")
+	}
+
+	if _, err := MigrateStore(src, struct{ Store }{dst}, MigrateOptions{}); !errors.Is(err, ErrStoreUnsupported) {
+		t.Fatalf("destination without KeySaver must be rejected, got %v", err)
+	}
+}
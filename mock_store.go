@@ -19,7 +19,7 @@ func (s *MockStore) Save(key *Key) error {
 
 	if key.IsActive {
 		for _, k := range s.data {
-			if k.Alg == key.Alg {
+			if slotOf(k) == slotOf(key) {
 				k.IsActive = false
 			}
 		}
@@ -29,6 +29,16 @@ func (s *MockStore) Save(key *Key) error {
 	return nil
 }
 
+func (s *MockStore) SaveAll(keys []*Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys {
+		s.data[k.KID] = k
+	}
+	return nil
+}
+
 func (s *MockStore) List() ([]*Key, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -40,6 +50,14 @@ func (s *MockStore) List() ([]*Key, error) {
 	return out, nil
 }
 
+func (s *MockStore) Delete(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, kid)
+	return nil
+}
+
 func (s *MockStore) Rotate(newKey *Key, old *Key) error {
 	if s.RotateErr != nil {
 		return s.RotateErr
@@ -0,0 +1,99 @@
+// Package natskm publishes keys_manager lifecycle events over NATS and
+// feeds events published by peers back into a manager as reload triggers.
+package natskm
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Conn is the part of *nats.Conn used by this package.
+type Conn interface {
+	Publish(subj string, data []byte) error
+	Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error)
+}
+
+var _ Conn = (*nats.Conn)(nil)
+
+// Publisher is a km.EventPublisher sending JSON encoded events to subject.
+type Publisher struct {
+	nc      Conn
+	subject string
+}
+
+func NewPublisher(nc Conn, subject string) *Publisher {
+	return &Publisher{nc: nc, subject: subject}
+}
+
+func (p *Publisher) Publish(ctx context.Context, ev km.LifecycleEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	return p.nc.Publish(p.subject, data)
+}
+
+// Watcher is a km.Watcher for use with km.WithWatcher. Every message on
+// subject triggers a reload; messages that do not decode as events still
+// do, with an empty KID.
+//
+// Core NATS delivers at most once, so a replica that misses a message
+// only catches up on the next event. Combine with a store that changes
+// rarely, or publish through JetStream when that matters.
+type Watcher struct {
+	nc      Conn
+	subject string
+}
+
+func NewWatcher(nc Conn, subject string) *Watcher {
+	return &Watcher{nc: nc, subject: subject}
+}
+
+func (w *Watcher) Watch(ctx context.Context) (<-chan km.StoreEvent, error) {
+	var (
+		mu     sync.Mutex
+		closed bool
+		out    = make(chan km.StoreEvent, 16)
+	)
+
+	sub, err := w.nc.Subscribe(w.subject, func(m *nats.Msg) {
+		var ev km.LifecycleEvent
+		_ = json.Unmarshal(m.Data, &ev)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		// A full buffer already has a reload pending.
+		select {
+		case out <- km.StoreEvent{KID: ev.KID}:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = sub.Unsubscribe()
+
+		mu.Lock()
+		closed = true
+		close(out)
+		mu.Unlock()
+	}()
+
+	return out, nil
+}
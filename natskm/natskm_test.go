@@ -0,0 +1,77 @@
+package natskm
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+// bus delivers published messages to subscribers of the same subject.
+type bus struct {
+	mu   sync.Mutex
+	subs map[string][]nats.MsgHandler
+}
+
+func (b *bus) Publish(subj string, data []byte) error {
+	b.mu.Lock()
+	subs := b.subs[subj]
+	b.mu.Unlock()
+
+	for _, cb := range subs {
+		cb(&nats.Msg{Subject: subj, Data: data})
+	}
+	return nil
+}
+
+func (b *bus) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[string][]nats.MsgHandler)
+	}
+	b.subs[subj] = append(b.subs[subj], cb)
+	return nil, nil
+}
+
+func TestPublisherAndWatcher_PeerReloadsOnRotation(t *testing.T) {
+	b := keytest.New(t, 1)
+	store := b.Store()
+	nc := &bus{}
+
+	writer := b.Manager(store, km.WithEventPublisher(NewPublisher(nc, "keys.events")))
+	if err := writer.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	peer := b.Manager(store, km.WithVerifyOnly(), km.WithWatcher(NewWatcher(nc, "keys.events")))
+
+	if err := writer.Rotate(km.AlgEdDSA); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	want := activeKID(writer)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if activeKID(peer) == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("peer did not pick up rotation, keys %+v", peer.Keys())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func activeKID(m *km.KeyManager) string {
+	for _, k := range m.Keys() {
+		if k.State == km.KeyStateActive {
+			return k.KID
+		}
+	}
+	return ""
+}
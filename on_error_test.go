@@ -0,0 +1,100 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func collectErrors() (func(*OperationError), <-chan *OperationError) {
+	ch := make(chan *OperationError, 16)
+	return func(e *OperationError) {
+		select {
+		case ch <- e:
+		default:
+		}
+	}, ch
+}
+
+func nextError(t *testing.T, ch <-chan *OperationError) *OperationError {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no error reported")
+		return nil
+	}
+}
+
+func TestOnError_BackgroundRefresh(t *testing.T) {
+	store := &flakyStore{MockStore: NewMockStore()}
+	onError, errs := collectErrors()
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy,
+		WithBackgroundRefresh(5*time.Millisecond), WithOnError(onError))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	store.fail.Store(true)
+
+	e := nextError(t, errs)
+	if e.Op != OpBackgroundRefresh || e.Err == nil {
+		t.Fatalf("unexpected operation error: %+v", e)
+	}
+}
+
+func TestOnError_ImplicitReload(t *testing.T) {
+	store := &FailingStore{MockStore: *NewMockStore()}
+	onError, errs := collectErrors()
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithOnError(onError))
+	store.FailList = true
+
+	if km.keyByKID("missing") != nil {
+		t.Fatalf("unexpected key")
+	}
+
+	e := nextError(t, errs)
+	if e.Op != OpImplicitReload || e.KID != "missing" {
+		t.Fatalf("unexpected operation error: %+v", e)
+	}
+
+	var opErr *OperationError
+	if !errors.As(error(e), &opErr) || errors.Unwrap(e) == nil {
+		t.Fatalf("OperationError must unwrap to the cause")
+	}
+}
+
+func TestOnError_EventPublish(t *testing.T) {
+	onError, errs := collectErrors()
+	pub := &recordingPublisher{err: errors.New("bus down")}
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithEventPublisher(pub), WithOnError(onError))
+	_ = km.Rotate(AlgEdDSA)
+
+	e := nextError(t, errs)
+	if e.Op != OpEventPublish || e.KID != km.activeKey(AlgEdDSA).key.KID {
+		t.Fatalf("unexpected operation error: %+v", e)
+	}
+}
+
+func TestOnError_IgnoresShutdown(t *testing.T) {
+	onError, errs := collectErrors()
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy,
+		WithBackgroundRefresh(time.Millisecond), WithOnError(onError))
+	_ = km.Close(context.Background())
+
+	km.reportError(OpBackgroundRefresh, "", km.ReloadCache())
+
+	select {
+	case e := <-errs:
+		t.Fatalf("shutdown must not be reported, got %+v", e)
+	default:
+	}
+}
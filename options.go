@@ -0,0 +1,450 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509/pkix"
+	"io"
+	"log/slog"
+	"time"
+)
+
+type Option func(*KeyManager)
+
+// WithVerifyOnly builds the cache from the public keys persisted alongside
+// each stored key, so the Encryptor is never used and may be nil. Signing
+// and rotation fail with ErrVerifyOnly.
+func WithVerifyOnly() Option {
+	return func(km *KeyManager) {
+		km.verifyOnly = true
+	}
+}
+
+// WithLazyDecrypt caches inactive keys from their stored public key only.
+// The private key is decrypted on first use by SignWithKID or Signer;
+// Verify and JWKS never need it. Keys without a stored public key are
+// decrypted eagerly as before.
+func WithLazyDecrypt() Option {
+	return func(km *KeyManager) {
+		km.lazy = true
+	}
+}
+
+// WithWarmStandby makes WithLazyDecrypt still decrypt the most recently
+// retired key of every slot on load, as it is the inactive key most likely
+// to be needed right after a rotation.
+func WithWarmStandby() Option {
+	return func(km *KeyManager) {
+		km.warmStandby = true
+	}
+}
+
+// WithLenientReload makes reloads skip keys that fail to decrypt or parse
+// instead of failing as a whole. Skipped keys are reported by
+// QuarantinedKeys and can be retried with RetryQuarantined.
+func WithLenientReload() Option {
+	return func(km *KeyManager) {
+		km.lenient = true
+	}
+}
+
+// WithPlaintextMigration makes reloads encrypt keys found stored
+// unencrypted, as raw PKCS#8 the Encryptor cannot decrypt, and save them
+// back before loading them. Without it such keys fail to load with
+// ErrPlaintextKey. The store must implement KeySaver.
+func WithPlaintextMigration() Option {
+	return func(km *KeyManager) {
+		km.migratePlaintext = true
+	}
+}
+
+// WithMinReloadInterval stops cache misses from reloading the store more
+// than once per interval. ReloadCache and Rotate always reload.
+func WithMinReloadInterval(d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.minReloadInterval = d
+	}
+}
+
+func WithClock(c Clock) Option {
+	return func(km *KeyManager) {
+		km.clock = c
+	}
+}
+
+// WithSkew tolerates clock drift between hosts: keys are treated as expired
+// only once ExpiresAt is more than d in the past, and as valid once
+// NotBefore is less than d in the future.
+func WithSkew(d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.skew = d
+	}
+}
+
+// WithWebhookTolerance sets how far the timestamp of a webhook signature
+// may be from the current time for VerifyWebhook to accept it. The default
+// is DefaultWebhookTolerance.
+func WithWebhookTolerance(d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.webhookTolerance = d
+	}
+}
+
+// WithSelfSignedCertificates makes Rotate and ImportKey issue a self-signed
+// X.509 certificate for every new signing key, valid until the key
+// expires. The certificate is stored with the key, so every replica serves
+// the same one. An empty CommonName defaults to the KID.
+func WithSelfSignedCertificates(subject pkix.Name) Option {
+	return func(km *KeyManager) {
+		km.certSubject = &subject
+	}
+}
+
+// WithAttester attests new keys with a instead of the Encryptor.
+func WithAttester(a Attester) Option {
+	return func(km *KeyManager) {
+		km.attester = a
+	}
+}
+
+// WithExpiredKeyPolicy sets what signing does with an active key that has
+// expired before rotation replaced it. The default is ExpiredKeyReject.
+func WithExpiredKeyPolicy(p ExpiredKeyPolicy) Option {
+	return func(km *KeyManager) {
+		km.expiredPolicy = p
+	}
+}
+
+// WithVerifyGrace limits how long a key verifies after it expires:
+// verification fails with ErrKeyExpired once ExpiresAt plus grace has
+// passed, and KeyInfo reports that time as VerifyUntil. Unlike
+// WithKeyRetention the key stays cached, so the rejection is immediate and
+// explicit. Zero, the default, lets expired keys verify until they are
+// removed.
+func WithVerifyGrace(grace time.Duration) Option {
+	return func(km *KeyManager) {
+		km.verifyGrace = grace
+	}
+}
+
+// WithRotateOnSign makes Sign rotate a slot inline when it has no active
+// key or only an expired one, instead of failing, so keys need not be
+// created with InitKeys before the first signature. The rotation follows
+// the slot's rotation policy and runs once however many signers wait on it.
+func WithRotateOnSign() Option {
+	return func(km *KeyManager) {
+		km.rotateOnSign = true
+	}
+}
+
+// WithCacheMaxAge forces a reload on the next lookup once the cache is
+// older than d, bounding how long a change made by another replica, such
+// as a revocation, goes unnoticed without WithBackgroundRefresh or a
+// watching store. If the reload fails the old cache keeps serving and the
+// error goes to WithOnError.
+func WithCacheMaxAge(d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.cacheMaxAge = d
+	}
+}
+
+// WithKeyRetention drops inactive keys from the cache and the JWKS once
+// they have been expired for longer than d, after which they no longer
+// verify. Zero, the default, keeps every stored key. A Verifier usually
+// retains keys much longer than the signer.
+func WithKeyRetention(d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.retention = d
+	}
+}
+
+// WithRevocationList applies a list made by ExportRevocationList once the
+// keys are first loaded; NewKeyManager and NewVerifier fail if it does not
+// verify. Newer lists can be applied later with ApplyRevocationList.
+func WithRevocationList(list string) Option {
+	return func(km *KeyManager) {
+		km.revocationList = list
+	}
+}
+
+// WithRevocationSigner sets the purpose whose keys sign revocation lists,
+// PurposeDefault unless set. ExportRevocationList signs with the active
+// key of purpose, and lists signed by a key of any other purpose are
+// rejected, so a webhook or cookie key cannot revoke anything.
+func WithRevocationSigner(purpose Purpose) Option {
+	return func(km *KeyManager) {
+		km.revocationSigner = purpose
+	}
+}
+
+// WithJWKSAttestation adds each key's attestation to the published JWKS as
+// the non-standard "attestation" member.
+func WithJWKSAttestation() Option {
+	return func(km *KeyManager) {
+		km.jwksAttestation = true
+	}
+}
+
+// WithJWKSCacheControl makes the JWKS handlers send Cache-Control with a
+// max-age of maxAge, shortened to end at the next planned rotation of the
+// served keys so caches refetch once a new key is published.
+func WithJWKSCacheControl(maxAge time.Duration) Option {
+	return func(km *KeyManager) {
+		km.jwksMaxAge = maxAge
+	}
+}
+
+// WithJWKSRotationHint makes the JWKS handlers send the next planned
+// rotation of the served keys in the Keyset-Next-Rotation header.
+func WithJWKSRotationHint() Option {
+	return func(km *KeyManager) {
+		km.jwksRotationHint = true
+	}
+}
+
+// WithAuthorizer consults a before signing with, rotating, revoking or
+// exporting keys.
+func WithAuthorizer(a Authorizer) Option {
+	return func(km *KeyManager) {
+		km.authorizer = a
+	}
+}
+
+// WithEnvironment asserts that every key belongs to env. Keys generated or
+// imported by the manager are tagged with it, and loading a key tagged with
+// another environment fails with ErrWrongEnvironment, even with
+// WithLenientReload. Untagged keys are accepted.
+func WithEnvironment(env string) Option {
+	return func(km *KeyManager) {
+		km.environment = env
+	}
+}
+
+// WithRand draws key generation, signing and nonce randomness from r
+// instead of crypto/rand, for HSM-backed DRBGs or deterministic tests. Read
+// failures surface as ErrEntropy. RSA key generation ignores r unless
+// GODEBUG=cryptocustomrand=1 is set.
+func WithRand(r io.Reader) Option {
+	return func(km *KeyManager) {
+		km.rand = r
+	}
+}
+
+// WithKIDCollisionCheck looks every generated KID up in the store before
+// using it and draws a new one if it is taken. KIDs already in the cache
+// are always rejected.
+func WithKIDCollisionCheck() Option {
+	return func(km *KeyManager) {
+		km.kidCheck = true
+	}
+}
+
+// WithSigningDomain mixes domain into the input of signatures made or
+// checked with keys of purpose by Sign, SignBatch, Verify, VerifyAny and
+// the webhook methods, so signatures cannot be replayed across purposes.
+// Verifiers outside the manager must apply the same prefix, see
+// DomainInput. JWS the manager makes or checks itself, such as VerifyJWT,
+// ReSign and revocation lists, are signed over the plain JWS input so any
+// JOSE library accepts them; do not assemble JWS from Sign output for a
+// purpose with a domain. crypto.Signer values returned by the manager
+// sign raw input.
+func WithSigningDomain(purpose Purpose, domain string) Option {
+	return func(km *KeyManager) {
+		if km.domains == nil {
+			km.domains = make(map[Purpose]string)
+		}
+		km.domains[purpose] = domain
+	}
+}
+
+// WithReplayCache makes VerifyJWT reject a token whose jti claim was seen
+// before. Tokens without a jti are not tracked.
+func WithReplayCache(c ReplayCache) Option {
+	return func(km *KeyManager) {
+		km.replay = c
+	}
+}
+
+// WithActivePolicy selects which key signs when a slot holds several active
+// keys. The default is PreferNewest.
+func WithActivePolicy(p ActivePolicy) Option {
+	return func(km *KeyManager) {
+		km.activePolicy = p
+	}
+}
+
+// WithUnknownKIDPolicy selects how lookups of unknown KIDs are handled. d is
+// the negative-cache TTL or the minimum reload interval, depending on p.
+func WithUnknownKIDPolicy(p UnknownKIDPolicy, d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.kidGuard = &unknownKIDGuard{policy: p, ttl: d}
+	}
+}
+
+// WithBackgroundRefresh reloads the cache every interval in a background
+// goroutine, so changes made by other replicas are picked up without a
+// request paying for the reload.
+func WithBackgroundRefresh(interval time.Duration) Option {
+	return func(km *KeyManager) {
+		km.refreshInterval = interval
+	}
+}
+
+// WithRotationSchedule calls RotateExpired every interval in a background
+// goroutine. It has no effect in verify-only mode.
+func WithRotationSchedule(interval time.Duration) Option {
+	return func(km *KeyManager) {
+		km.rotateInterval = interval
+	}
+}
+
+// WithPurposePolicy rotates keys of purpose under p instead of the
+// manager's policy, e.g. to give refresh-token keys a longer TTL than
+// access-token keys of the same alg. SetRotationPolicy does not replace it.
+func WithPurposePolicy(purpose Purpose, p RotationPolicy) Option {
+	return func(km *KeyManager) {
+		if km.purposePolicies == nil {
+			km.purposePolicies = make(map[Purpose]RotationPolicy)
+		}
+		km.purposePolicies[purpose] = p
+	}
+}
+
+// WithPurposeRotationSchedule rotates expired keys of purpose every
+// interval, independently of WithRotationSchedule, which then skips them.
+// It has no effect in verify-only mode.
+func WithPurposeRotationSchedule(purpose Purpose, interval time.Duration) Option {
+	return func(km *KeyManager) {
+		if km.purposeSchedules == nil {
+			km.purposeSchedules = make(map[Purpose]time.Duration)
+		}
+		km.purposeSchedules[purpose] = interval
+	}
+}
+
+// WithEscrow additionally wraps every generated or imported private key for
+// recipient, an *rsa.PublicKey or X25519 *ecdh.PublicKey whose private half
+// is kept offline, and stores the result in Key.Escrow. OpenEscrow recovers
+// the key.
+func WithEscrow(recipient crypto.PublicKey) Option {
+	return func(km *KeyManager) {
+		km.escrowKey = recipient
+	}
+}
+
+// WithApprover makes Rotate store new keys as pending and ask a for
+// approval instead of activating them. ApproveKey activates a pending key
+// and RejectKey revokes it.
+func WithApprover(a Approver) Option {
+	return func(km *KeyManager) {
+		km.approver = a
+	}
+}
+
+// WithKeyPool keeps up to depth keys for alg generated in the background,
+// so Rotate can use a ready key instead of generating one. It is mostly
+// useful for RS256, where generation is slow.
+func WithKeyPool(alg Alg, depth int) Option {
+	return func(km *KeyManager) {
+		km.keyPool.setDepth(alg, depth)
+	}
+}
+
+// WithBoundedCache keeps only active keys resident. Inactive keys are
+// fetched by KID through the store's KeyGetter when a token needs them
+// and kept in an LRU of up to size entries. The JWKS is still built from
+// every stored key that has a persisted public key.
+func WithBoundedCache(size int) Option {
+	return func(km *KeyManager) {
+		km.bounded = newBoundedCache(size)
+	}
+}
+
+func WithTracer(t Tracer) Option {
+	return func(km *KeyManager) {
+		km.tracer = t
+	}
+}
+
+// WithWatcher takes change notifications from w instead of the store, for
+// example lifecycle events published by peers over a message bus.
+func WithWatcher(w Watcher) Option {
+	return func(km *KeyManager) {
+		km.watcher = w
+	}
+}
+
+// WithEventPublisher announces rotations, revocations and imports made
+// through this manager, so peers can reload without waiting for a refresh.
+func WithEventPublisher(p EventPublisher) Option {
+	return func(km *KeyManager) {
+		km.events = p
+	}
+}
+
+// WithUsagePersistence adds the sign and verify counts recorded since the
+// previous flush to the store's UsageStore every interval, and once more
+// on Close.
+func WithUsagePersistence(interval time.Duration) Option {
+	return func(km *KeyManager) {
+		km.usageInterval = interval
+	}
+}
+
+// WithSignLimiter consults l before every signature, e.g. a
+// TokenBucketLimiter, so a misbehaving client cannot exhaust signing
+// quotas. Callers are identified with WithCaller.
+func WithSignLimiter(l SignLimiter) Option {
+	return func(km *KeyManager) {
+		km.limiter = l
+	}
+}
+
+// WithSignRecorder calls fn with a SignRecord for a sample of signatures
+// made through Sign, SignWithKID and SignBatch. rate is the fraction
+// recorded, from 0 to 1; values of 1 or more record every signature. fn
+// runs on the signing goroutine after the signature is made and should
+// hand the record off rather than block.
+func WithSignRecorder(fn func(context.Context, SignRecord), rate float64) Option {
+	return func(km *KeyManager) {
+		km.signRecorder = &signRecorder{fn: fn, rate: rate}
+	}
+}
+
+// WithDERSignatures makes Verify and VerifyAny also accept ES256
+// signatures in the ASN.1 DER form produced by most crypto libraries,
+// for interop with signers outside JWS. Raw R||S signatures keep working.
+func WithDERSignatures() Option {
+	return func(km *KeyManager) {
+		km.acceptDER = true
+	}
+}
+
+// WithJWKSExporter hands the JWKS to e after every reload that changes
+// it, including the initial load and rotations made by other replicas.
+// Exports run in the background, so reloads never wait on e; a failed
+// export is retried with backoff until it succeeds or a newer keyset
+// replaces it.
+func WithJWKSExporter(e JWKSExporter) Option {
+	return func(km *KeyManager) {
+		km.jwksExport = newJWKSExport(e)
+	}
+}
+
+// WithOnError calls fn with every error from background refreshes, watch
+// and signal reloads, implicit reloads, scheduled rotations, key pool
+// generation and event publishing. These errors have no caller to return
+// to. fn runs on the goroutine that hit the error and should not block.
+func WithOnError(fn func(*OperationError)) Option {
+	return func(km *KeyManager) {
+		km.onError = fn
+	}
+}
+
+// WithLogger reports reloads, rotations and quarantined keys to l. Records
+// include key IDs but never key material.
+func WithLogger(l *slog.Logger) Option {
+	return func(km *KeyManager) {
+		km.log = l
+	}
+}
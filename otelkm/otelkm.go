@@ -0,0 +1,45 @@
+// Package otelkm reports keys_manager spans to OpenTelemetry.
+package otelkm
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+const instrumentationName = "github.com/keylet-auth/keys-manager"
+
+// Tracer returns a km.Tracer creating spans with tp, for use with
+// km.WithTracer.
+func Tracer(tp trace.TracerProvider) km.Tracer {
+	return tracer{t: tp.Tracer(instrumentationName)}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, km.Span) {
+	ctx, s := t.t.Start(ctx, name)
+	return ctx, span{s: s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttribute(key, value string) {
+	s.s.SetAttributes(attribute.String(key, value))
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+	s.s.End()
+}
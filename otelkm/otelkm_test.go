@@ -0,0 +1,72 @@
+package otelkm
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+func policy() (km.RotationConfig, error) {
+	return km.RotationConfig{TTL: time.Hour}, nil
+}
+
+func TestTracer_RecordsManagerSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	m, err := km.NewKeyManager(km.NewMockStore(), km.MockEncryptor{}, policy, km.WithTracer(Tracer(tp)))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	_ = m.InitKeys([]km.Alg{km.AlgEdDSA})
+
+	_, _ = m.Sign(km.AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil })
+	_ = m.Verify("missing", nil, nil)
+
+	seen := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		seen[s.Name()] = s
+	}
+
+	for _, name := range []string{km.SpanRotate, km.SpanStoreList, km.SpanEncrypt, km.SpanStoreRotate, km.SpanReload, km.SpanSign, km.SpanVerify} {
+		if _, ok := seen[name]; !ok {
+			t.Fatalf("expected span %s", name)
+		}
+	}
+
+	verify := seen[km.SpanVerify]
+	if verify.Status().Code != codes.Error {
+		t.Fatalf("expected failed Verify span to carry an error status, got %v", verify.Status())
+	}
+
+	var found bool
+	for _, ev := range verify.Events() {
+		found = found || ev.Name == "exception"
+	}
+	if !found {
+		t.Fatalf("expected the error to be recorded on the span")
+	}
+}
+
+func TestSpan_EndWithoutError(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	_, s := Tracer(tp).Start(t.Context(), "op")
+	s.SetAttribute("kid", "k1")
+	s.End(nil)
+
+	ended := rec.Ended()
+	if len(ended) != 1 || ended[0].Status().Code == codes.Error {
+		t.Fatalf("expected one successful span, got %v", ended)
+	}
+	if attrs := ended[0].Attributes(); len(attrs) != 1 || attrs[0].Value.AsString() != "k1" {
+		t.Fatalf("expected kid attribute, got %v", attrs)
+	}
+
+}
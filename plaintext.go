@@ -0,0 +1,121 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// plaintextMaterial reports whether the ciphertext of k is an unencrypted
+// PKCS#8 private key, as written by legacy stores. It is only consulted
+// once the Encryptor failed to decrypt k. Symmetric secrets have no
+// structure to recognize and are never detected.
+func plaintextMaterial(k *Key) bool {
+	if k.EncryptedKey == nil || k.Alg.symmetric() {
+		return false
+	}
+
+	priv, err := parsePrivateKey(k.EncryptedKey.Ciphertext)
+	if err != nil {
+		return false
+	}
+	wipeSigner(priv)
+
+	return true
+}
+
+// loadPlaintext loads a key whose material is stored unencrypted. Without
+// WithPlaintextMigration it is refused with ErrPlaintextKey, which also
+// matches ErrDecryptFailed; with it the material is encrypted and saved
+// back first.
+func (km *KeyManager) loadPlaintext(ctx context.Context, k *Key) (*CachedKey, error) {
+	if !km.migratePlaintext {
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w", ErrDecryptFailed, ErrPlaintextKey)}
+	}
+
+	encrypted, err := km.encryptPlaintext(k)
+	if err != nil {
+		return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: fmt.Errorf("%w: %w: %w", ErrDecryptFailed, ErrPlaintextKey, err)}
+	}
+
+	km.logger().Warn("plaintext key encrypted", "kid", k.KID, "alg", k.Alg)
+
+	return km.loadKey(ctx, encrypted)
+}
+
+// encryptPlaintext encrypts the plaintext material of k with the
+// manager's Encryptor and saves the result.
+func (km *KeyManager) encryptPlaintext(k *Key) (*Key, error) {
+	saver, ok := km.store.(KeySaver)
+	if !ok {
+		return nil, fmt.Errorf("%w: requires KeySaver", ErrStoreUnsupported)
+	}
+
+	encrypted, err := km.encryptor.Encrypt(k.EncryptedKey.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := cloneKey(k)
+	updated.EncryptedKey = encrypted
+	updated.UpdatedAt = km.clock.Now()
+
+	if err := saver.Save(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (km *KeyManager) EncryptPlaintextKeys() ([]string, error) {
+	return km.EncryptPlaintextKeysCtx(context.Background())
+}
+
+// EncryptPlaintextKeysCtx finds stored keys whose material is unencrypted
+// PKCS#8 and saves them encrypted with the manager's Encryptor, returning
+// their KIDs. Keys the Encryptor decrypts are left alone, so it can be run
+// repeatedly. Open the manager WithLenientReload to run it over a store
+// that still holds plaintext keys. The store must implement KeySaver.
+func (km *KeyManager) EncryptPlaintextKeysCtx(ctx context.Context) ([]string, error) {
+	if _, err := km.saver(); err != nil {
+		return nil, err
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted []string
+	for _, k := range keys {
+		if !km.storedPlaintext(ctx, k) {
+			continue
+		}
+
+		if _, err := km.encryptPlaintext(k); err != nil {
+			return encrypted, &KeyError{KID: k.KID, Alg: k.Alg, Err: err}
+		}
+		encrypted = append(encrypted, k.KID)
+	}
+
+	if len(encrypted) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(encrypted)
+	return encrypted, km.reload(ctx)
+}
+
+// storedPlaintext reports whether k fails to decrypt because its material
+// is stored unencrypted.
+func (km *KeyManager) storedPlaintext(ctx context.Context, k *Key) bool {
+	if k.EncryptedKey == nil || k.Alg.symmetric() {
+		return false
+	}
+
+	plain, err := km.decrypt(ctx, k)
+	if err == nil {
+		wipeBytes(plain)
+		return false
+	}
+	return plaintextMaterial(k)
+}
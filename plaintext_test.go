@@ -0,0 +1,69 @@
+package keys_manager
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func plaintextStore(t *testing.T) (*MockStore, *AESGCMEncryptor) {
+	t.Helper()
+
+	enc, _ := NewAESGCMEncryptor(bytes.Repeat([]byte{7}, 32))
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(time.Hour)
+
+	store := NewMockStore()
+	store.Save(makeTestKey("legacy", AlgEdDSA, true, &exp, MockEncryptor{}, priv))
+	return store, enc
+}
+
+func TestPlaintextKey_Refused(t *testing.T) {
+	store, enc := plaintextStore(t)
+
+	if _, err := NewKeyManager(store, enc, mockPolicy); !errors.Is(err, ErrPlaintextKey) {
+		t.Fatalf("expected ErrPlaintextKey, got %v", err)
+	}
+
+	km, err := NewKeyManager(store, enc, mockPolicy, WithLenientReload())
+	if err != nil {
+		t.Fatalf("lenient reload must not fail: %v", err)
+	}
+
+	report, err := km.VerifyStoreIntegrity()
+	if err != nil || len(report.Findings) != 1 || report.Findings[0].Kind != FindingPlaintext {
+		t.Fatalf("expected a plaintext finding, got %+v, %v", report, err)
+	}
+
+	kids, err := km.EncryptPlaintextKeys()
+	if err != nil || len(kids) != 1 || kids[0] != "legacy" {
+		t.Fatalf("EncryptPlaintextKeys = %v, %v", kids, err)
+	}
+	if len(km.QuarantinedKeys()) != 0 || km.activeKey(AlgEdDSA) == nil {
+		t.Fatalf("encrypted key must load after the migration")
+	}
+	if plaintextMaterial(store.data["legacy"]) {
+		t.Fatalf("stored material must be encrypted")
+	}
+
+	if kids, err := km.EncryptPlaintextKeys(); err != nil || kids != nil {
+		t.Fatalf("second run must find nothing, got %v, %v", kids, err)
+	}
+}
+
+func TestPlaintextKey_MigratedOnLoad(t *testing.T) {
+	store, enc := plaintextStore(t)
+
+	km, err := NewKeyManager(store, enc, mockPolicy, WithPlaintextMigration())
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if km.activeKey(AlgEdDSA) == nil {
+		t.Fatalf("migrated key must be active")
+	}
+
+	if _, err := NewKeyManager(store, enc, mockPolicy); err != nil {
+		t.Fatalf("migrated store must load without the option: %v", err)
+	}
+}
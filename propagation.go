@@ -0,0 +1,111 @@
+package keys_manager
+
+import (
+	"context"
+	"time"
+)
+
+// stagedIn returns the newest key of s that waits for activation after a
+// PropagationDelay.
+func stagedIn(keys []*Key, s keySlot) *Key {
+	var staged *Key
+	for _, k := range keys {
+		if !k.Staged || k.NotBefore == nil || k.IsActive || k.RevokedAt != nil || slotOf(k) != s {
+			continue
+		}
+		if staged == nil || k.CreatedAt.After(staged.CreatedAt) {
+			staged = k
+		}
+	}
+	return staged
+}
+
+// stage saves k inactive and marked Staged, with NotBefore at the end of
+// the delay, publishes it and schedules its activation.
+func (km *KeyManager) stage(ctx context.Context, k, oldKey *Key, delay time.Duration) error {
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	activateAt := km.clock.Now().Add(delay)
+	k.IsActive = false
+	k.Staged = true
+	k.NotBefore = &activateAt
+	if err := saver.Save(k); err != nil {
+		return err
+	}
+
+	km.logger().InfoContext(ctx, "key staged", "kid", k.KID, "alg", k.Alg, "activate_at", activateAt)
+	km.publish(ctx, LifecycleEvent{Type: EventKeyStaged, KID: k.KID, Alg: k.Alg, Purpose: k.Purpose, PreviousKID: oldKey.KID})
+
+	if err := km.reload(ctx); err != nil {
+		return err
+	}
+
+	km.scheduleActivation(k.KID, activateAt)
+	return nil
+}
+
+// scheduleActivation activates kid once the manager's clock reaches at.
+func (km *KeyManager) scheduleActivation(kid string, at time.Time) {
+	km.bg.goFunc(func(ctx context.Context) {
+		for {
+			wait := at.Sub(km.clock.Now())
+			if wait <= 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-km.after(wait):
+			}
+		}
+		km.reportError(OpActivation, kid, km.activateStaged(ctx, kid))
+	})
+}
+
+// resumeStaged schedules the activation of keys staged before the manager
+// started, so a restart during the delay does not leave them inactive.
+func (km *KeyManager) resumeStaged() {
+	snap := km.snapshot()
+
+	keys := make([]*Key, 0, len(snap.cache))
+	for _, ck := range snap.cache {
+		keys = append(keys, ck.key)
+	}
+
+	slots := make(map[keySlot]bool)
+	for _, k := range keys {
+		s := slotOf(k)
+		if slots[s] {
+			continue
+		}
+		slots[s] = true
+
+		if staged := stagedIn(keys, s); staged != nil {
+			km.scheduleActivation(staged.KID, *staged.NotBefore)
+		}
+	}
+}
+
+// activateStaged activates the staged key kid, retiring the active key of
+// its slot. It does nothing when kid is no longer staged.
+func (km *KeyManager) activateStaged(ctx context.Context, kid string) error {
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.KID != kid {
+			continue
+		}
+		if staged := stagedIn(keys, slotOf(k)); staged == nil || staged.KID != kid {
+			return nil
+		}
+		return km.promote(ctx, keys, cloneKey(k))
+	}
+
+	return nil
+}
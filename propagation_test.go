@@ -0,0 +1,220 @@
+package keys_manager
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timerClock is a TimerClock whose timers fire only when advanced.
+type timerClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []clockWaiter
+}
+
+type clockWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *timerClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *timerClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, clockWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *timerClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+func propagationPolicy(delay time.Duration) RotationPolicy {
+	return func() (RotationConfig, error) {
+		return RotationConfig{TTL: time.Hour, PropagationDelay: delay}, nil
+	}
+}
+
+func waitForActive(t *testing.T, km *KeyManager, kid string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if ck := km.activeKey(AlgEdDSA); ck != nil && ck.key.KID == kid {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("key %s was not activated", kid)
+}
+
+func TestPropagationDelay_PublishesBeforeActivation(t *testing.T) {
+	store := NewMockStore()
+	pub := &recordingPublisher{}
+	km, _ := NewKeyManager(store, MockEncryptor{}, propagationPolicy(50*time.Millisecond), WithEventPublisher(pub))
+	defer km.Close(context.Background())
+
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+	oldKID := km.activeKey(AlgEdDSA).key.KID
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("second Rotate error: %v", err)
+	}
+
+	keys, _ := store.List()
+	if len(keys) != 2 {
+		t.Fatalf("rotating during the delay must not stage another key, got %d keys", len(keys))
+	}
+
+	var newKID string
+	for _, k := range keys {
+		if k.KID != oldKID {
+			newKID = k.KID
+		}
+	}
+
+	if ck := km.activeKey(AlgEdDSA); ck == nil || ck.key.KID != oldKID {
+		t.Fatalf("old key must stay active during the delay")
+	}
+	jwks, _ := km.JWKS()
+	if !strings.Contains(string(jwks), newKID) {
+		t.Fatalf("staged key must be published before activation")
+	}
+
+	waitForActive(t, km, newKID)
+
+	keys, _ = store.List()
+	for _, k := range keys {
+		if k.KID == oldKID && k.IsActive {
+			t.Fatalf("old key must be retired on activation")
+		}
+	}
+
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+
+	var types []EventType
+	for _, ev := range pub.events[1:] {
+		types = append(types, ev.Type)
+	}
+	if len(types) != 2 || types[0] != EventKeyStaged || types[1] != EventKeyRotated {
+		t.Fatalf("expected staged then rotated events, got %v", types)
+	}
+}
+
+func TestPropagationDelay_ResumesAfterRestart(t *testing.T) {
+	store := NewMockStore()
+	policy := propagationPolicy(100 * time.Millisecond)
+
+	first, _ := NewKeyManager(store, MockEncryptor{}, policy)
+	_ = first.InitKeys([]Alg{AlgEdDSA})
+	oldKID := first.activeKey(AlgEdDSA).key.KID
+	_ = first.Rotate(AlgEdDSA)
+	_ = first.Close(context.Background())
+
+	var newKID string
+	keys, _ := store.List()
+	for _, k := range keys {
+		if k.KID != oldKID {
+			newKID = k.KID
+		}
+	}
+
+	second, _ := NewKeyManager(store, MockEncryptor{}, policy)
+	defer second.Close(context.Background())
+
+	waitForActive(t, second, newKID)
+}
+
+func TestPropagationDelay_WaitsOnInjectedClock(t *testing.T) {
+	clock := &timerClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, propagationPolicy(time.Hour), WithClock(clock))
+	defer km.Close(context.Background())
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	oldKID := km.activeKey(AlgEdDSA).key.KID
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	var newKID string
+	for kid, k := range store.data {
+		if kid != oldKID {
+			newKID = kid
+			if !k.Staged || keyState(k) != KeyStateStaged {
+				t.Fatalf("new key must be stored as staged, got %+v", k)
+			}
+		}
+	}
+
+	clock.advance(30 * time.Minute)
+	time.Sleep(20 * time.Millisecond)
+	if ck := km.activeKey(AlgEdDSA); ck == nil || ck.key.KID != oldKID {
+		t.Fatalf("staged key must wait for the injected clock")
+	}
+
+	clock.advance(30 * time.Minute)
+	waitForActive(t, km, newKID)
+	if store.data[newKID].Staged {
+		t.Fatalf("activated key must no longer be staged")
+	}
+}
+
+func TestPropagationDelay_AppliesToApprovedKeys(t *testing.T) {
+	clock := &timerClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	approver := &recordingApprover{}
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, propagationPolicy(time.Hour), WithClock(clock))
+	defer km.Close(context.Background())
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	oldKID := km.activeKey(AlgEdDSA).key.KID
+
+	km.approver = approver
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	kid := approver.requests[0].KID
+
+	clock.advance(2 * time.Hour)
+	if err := km.ApproveKey(t.Context(), kid); err != nil {
+		t.Fatalf("ApproveKey error: %v", err)
+	}
+	if ck := km.activeKey(AlgEdDSA); ck == nil || ck.key.KID != oldKID {
+		t.Fatalf("approved key must be staged, not activated at once")
+	}
+	if k := store.data[kid]; !k.Staged || k.Pending {
+		t.Fatalf("approved key must be stored as staged, got %+v", k)
+	}
+
+	clock.advance(time.Hour)
+	waitForActive(t, km, kid)
+}
@@ -0,0 +1,142 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type callerKey struct{}
+
+// WithCaller tags ctx with the identity of the internal client signing, so
+// a SignLimiter can apply per-caller limits.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller set with WithCaller, or "".
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// SignRequest describes signatures about to be made. N is the number of
+// signatures, more than one for SignBatch.
+type SignRequest struct {
+	KID     string
+	Alg     Alg
+	Purpose Purpose
+	Caller  string
+	N       int
+}
+
+// SignLimiter is consulted before every signature. A non-nil error rejects
+// the request; it is returned to the caller wrapped in ErrRateLimited.
+type SignLimiter interface {
+	Allow(ctx context.Context, req SignRequest) error
+}
+
+func (km *KeyManager) allowSign(ctx context.Context, ck *CachedKey, n int) error {
+	if km.limiter == nil {
+		return nil
+	}
+
+	req := SignRequest{
+		KID:     ck.key.KID,
+		Alg:     ck.key.Alg,
+		Purpose: ck.key.Purpose,
+		Caller:  CallerFromContext(ctx),
+		N:       n,
+	}
+	if err := km.limiter.Allow(ctx, req); err != nil {
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	return nil
+}
+
+// TokenBucketLimiter is an in-process SignLimiter allowing rate signatures
+// per second with bursts of up to burst, tracked separately per alg,
+// purpose and caller. Buckets that refilled completely are dropped, so
+// callers that stop signing do not pin memory.
+type TokenBucketLimiter struct {
+	rate  float64
+	burst float64
+	clock Clock
+
+	mu      sync.Mutex
+	buckets map[limitKey]*bucket
+	swept   time.Time
+}
+
+// limiterSweepInterval is how often Allow looks for idle buckets.
+const limiterSweepInterval = time.Minute
+
+type limitKey struct {
+	alg     Alg
+	purpose Purpose
+	caller  string
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		clock:   systemClock{},
+		buckets: make(map[limitKey]*bucket),
+	}
+}
+
+// refill returns the tokens b holds at now.
+func (l *TokenBucketLimiter) refill(b *bucket, now time.Time) float64 {
+	return min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+}
+
+// sweep drops the buckets that are full again: a new bucket starts full,
+// so forgetting them changes no decision.
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < limiterSweepInterval {
+		return
+	}
+	l.swept = now
+
+	for k, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func (l *TokenBucketLimiter) Allow(_ context.Context, req SignRequest) error {
+	k := limitKey{alg: req.Alg, purpose: req.Purpose, caller: req.Caller}
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b := l.buckets[k]
+	if b == nil {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[k] = b
+	}
+
+	b.tokens = l.refill(b, now)
+	b.last = now
+
+	if n := float64(req.N); b.tokens >= n {
+		b.tokens -= n
+		return nil
+	}
+
+	if req.Caller != "" {
+		return fmt.Errorf("caller %s exceeded %g signatures/s for alg %s", req.Caller, l.rate, req.Alg)
+	}
+	return fmt.Errorf("exceeded %g signatures/s for alg %s", l.rate, req.Alg)
+}
@@ -0,0 +1,120 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSignLimiter_PerCaller(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewTokenBucketLimiter(1, 2)
+	limiter.clock = clock
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithSignLimiter(limiter))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	build := func(string) ([]byte, error) { return []byte("x"), nil }
+	billing := WithCaller(context.Background(), "billing")
+
+	for i := 0; i < 2; i++ {
+		if _, err := km.SignCtx(billing, AlgEdDSA, build); err != nil {
+			t.Fatalf("sign %d within burst failed: %v", i, err)
+		}
+	}
+
+	_, err := km.SignCtx(billing, AlgEdDSA, build)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	if _, err := km.SignCtx(WithCaller(context.Background(), "search"), AlgEdDSA, build); err != nil {
+		t.Fatalf("other callers must have their own bucket, got %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if _, err := km.SignCtx(billing, AlgEdDSA, build); err != nil {
+		t.Fatalf("bucket must refill over time, got %v", err)
+	}
+}
+
+func TestSignLimiter_BatchCountsEverySignature(t *testing.T) {
+	var got SignRequest
+	limiter := signLimiterFunc(func(_ context.Context, req SignRequest) error {
+		got = req
+		return errors.New("quota exhausted")
+	})
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithSignLimiter(limiter))
+	_ = km.InitKeysWithPurpose(PurposeAccess, []Alg{AlgEdDSA})
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	_, _, err := km.SignBatch(AlgEdDSA, [][]byte{{1}, {2}, {3}}, 1)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if got.N != 3 || got.Alg != AlgEdDSA || got.KID == "" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+
+	_, _ = km.SignWithPurpose(AlgEdDSA, PurposeAccess, func(string) ([]byte, error) { return nil, nil })
+	if got.Purpose != PurposeAccess || got.N != 1 {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+}
+
+func TestSignLimiter_CtxVariantsPassCaller(t *testing.T) {
+	var callers []string
+	limiter := signLimiterFunc(func(_ context.Context, req SignRequest) error {
+		callers = append(callers, req.Caller)
+		return nil
+	})
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithSignLimiter(limiter))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	ctx := WithCaller(context.Background(), "billing")
+	if _, _, err := km.SignBatchCtx(ctx, AlgEdDSA, [][]byte{{1}}, 1); err != nil {
+		t.Fatalf("SignBatchCtx error: %v", err)
+	}
+	if len(callers) != 1 || callers[0] != "billing" {
+		t.Fatalf("expected the caller from ctx, got %v", callers)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := km.SignBatchCtx(cancelled, AlgEdDSA, [][]byte{{1}}, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTokenBucketLimiter_DropsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewTokenBucketLimiter(1, 2)
+	limiter.clock = clock
+
+	for i := range 100 {
+		req := SignRequest{Alg: AlgEdDSA, Caller: fmt.Sprint("caller-", i), N: 1}
+		if err := limiter.Allow(context.Background(), req); err != nil {
+			t.Fatalf("Allow error: %v", err)
+		}
+	}
+
+	clock.now = clock.now.Add(limiterSweepInterval)
+	_ = limiter.Allow(context.Background(), SignRequest{Alg: AlgEdDSA, Caller: "active", N: 2})
+
+	if n := len(limiter.buckets); n != 1 {
+		t.Fatalf("expected refilled buckets to be dropped, %d left", n)
+	}
+	if err := limiter.Allow(context.Background(), SignRequest{Alg: AlgEdDSA, Caller: "active", N: 1}); err == nil {
+		t.Fatalf("a drained bucket must be kept")
+	}
+}
+
+type signLimiterFunc func(ctx context.Context, req SignRequest) error
+
+func (f signLimiterFunc) Allow(ctx context.Context, req SignRequest) error {
+	return f(ctx, req)
+}
@@ -0,0 +1,45 @@
+// Package rediskm provides a keys_manager.ReplayCache shared between
+// replicas through Redis.
+//
+// It does not depend on a particular Redis client. Client is small enough
+// to wrap any of them; with redis/go-redis:
+//
+//	type client struct{ rdb *redis.Client }
+//
+//	func (c client) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+//		return c.rdb.SetNX(ctx, key, value, ttl).Result()
+//	}
+package rediskm
+
+import (
+	"context"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// Client sets key to value with an expiry unless key already exists,
+// reporting whether it was set.
+type Client interface {
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+}
+
+// ReplayCache stores token IDs under prefix.
+type ReplayCache struct {
+	c      Client
+	prefix string
+}
+
+var _ km.ReplayCache = (*ReplayCache)(nil)
+
+func NewReplayCache(c Client, prefix string) *ReplayCache {
+	return &ReplayCache{c: c, prefix: prefix}
+}
+
+func (r *ReplayCache) Seen(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	set, err := r.c.SetNX(ctx, r.prefix+id, "1", ttl)
+	if err != nil {
+		return false, err
+	}
+	return !set, nil
+}
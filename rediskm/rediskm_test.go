@@ -0,0 +1,69 @@
+package rediskm
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+type fakeClient struct {
+	mu   sync.Mutex
+	keys map[string]time.Duration
+}
+
+func (c *fakeClient) SetNX(_ context.Context, key, _ string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.keys[key]; ok {
+		return false, nil
+	}
+	c.keys[key] = ttl
+	return true, nil
+}
+
+func TestReplayCache_SharedBetweenManagers(t *testing.T) {
+	b := keytest.New(t, 1)
+	client := &fakeClient{keys: make(map[string]time.Duration)}
+	store := b.Store()
+
+	signer := b.Manager(store, km.WithReplayCache(NewReplayCache(client, "jti:")))
+	if err := signer.InitKeys([]km.Alg{km.AlgEdDSA}); err != nil {
+		t.Fatalf("init keys: %v", err)
+	}
+	peer := b.Manager(store, km.WithReplayCache(NewReplayCache(client, "jti:")))
+
+	jti, _ := signer.NewTokenID()
+	exp := keytest.Epoch.Add(time.Minute).Unix()
+
+	var input string
+	sig, err := signer.Sign(km.AlgEdDSA, func(kid string) ([]byte, error) {
+		header := fmt.Sprintf(`{"alg":"EdDSA","kid":%q}`, kid)
+		claims := fmt.Sprintf(`{"jti":%q,"exp":%d}`, jti, exp)
+		input = base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(claims))
+		return []byte(input), nil
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	token := input + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	if _, err := signer.VerifyJWT(token); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if _, err := peer.VerifyJWT(token); !errors.Is(err, km.ErrTokenReplayed) {
+		t.Fatalf("replay on a peer must be rejected, got %v", err)
+	}
+
+	if ttl := client.keys["jti:"+jti]; ttl != time.Minute {
+		t.Fatalf("id must be kept until the token expires, got %v", ttl)
+	}
+}
@@ -0,0 +1,61 @@
+package keys_manager
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// ReloadCause tells why the key cache was reloaded. It is logged with each
+// reload, set as the "cause" attribute of SpanReload and counted in
+// DebugInfo.ReloadCauses, so propagation can be told apart from traffic
+// spraying unknown KIDs.
+type ReloadCause string
+
+const (
+	ReloadInit       ReloadCause = "init"        // first load in NewKeyManager
+	ReloadExplicit   ReloadCause = "explicit"    // ReloadCache and RetryQuarantined
+	ReloadWrite      ReloadCause = "write"       // after the manager changed the store
+	ReloadKIDMiss    ReloadCause = "kid_miss"    // lookup of a KID missing from the cache
+	ReloadActiveMiss ReloadCause = "active_miss" // signing without an active key
+	ReloadStale      ReloadCause = "stale"       // cache older than WithCacheMaxAge
+	ReloadScheduled  ReloadCause = "scheduled"   // WithBackgroundRefresh
+	ReloadWatch      ReloadCause = "watch"       // Watcher event
+	ReloadSignal     ReloadCause = "signal"      // ReloadOnSignal
+)
+
+type reloadCauseKey struct{}
+
+func withReloadCause(ctx context.Context, cause ReloadCause) context.Context {
+	return context.WithValue(ctx, reloadCauseKey{}, cause)
+}
+
+// reloadCauseOf returns the cause set on ctx. Reloads without one follow a
+// write by the manager itself.
+func reloadCauseOf(ctx context.Context) ReloadCause {
+	if c, ok := ctx.Value(reloadCauseKey{}).(ReloadCause); ok {
+		return c
+	}
+	return ReloadWrite
+}
+
+type reloadCauses struct {
+	mu     sync.Mutex
+	counts map[ReloadCause]uint64
+}
+
+func (c *reloadCauses) add(cause ReloadCause) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[ReloadCause]uint64)
+	}
+	c.counts[cause]++
+}
+
+func (c *reloadCauses) snapshot() map[ReloadCause]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.counts)
+}
@@ -0,0 +1,40 @@
+package keys_manager
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestReloadCauses(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithLogger(logger))
+
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	_ = km.ReloadCache()
+	_ = km.Verify("sprayed-kid", []byte("data"), []byte("sig"))
+	_, _ = km.Sign(AlgES256, func(string) ([]byte, error) { return []byte("data"), nil })
+
+	causes := km.Debug().ReloadCauses
+	for cause, want := range map[ReloadCause]uint64{
+		ReloadInit:       1,
+		ReloadWrite:      1,
+		ReloadExplicit:   1,
+		ReloadKIDMiss:    1,
+		ReloadActiveMiss: 1,
+	} {
+		if causes[cause] < want {
+			t.Fatalf("expected at least %d %s reloads, got %v", want, cause, causes)
+		}
+	}
+	if causes[ReloadWatch] != 0 || causes[ReloadScheduled] != 0 {
+		t.Fatalf("unexpected causes: %v", causes)
+	}
+
+	if !strings.Contains(logs.String(), "cause=kid_miss") {
+		t.Fatalf("reload logs must carry the cause:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,55 @@
+package keys_manager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sharedReloadTimeout bounds a reload shared through a reloadGroup, which
+// no longer follows the cancellation of the caller that started it.
+const sharedReloadTimeout = 30 * time.Second
+
+// reloadGroup collapses concurrent reloads into one: callers arriving while
+// a reload is in flight wait for it and share its result. The reload runs
+// detached from the caller that started it, so that caller giving up does
+// not fail the others; every caller stops waiting when its own ctx is done.
+type reloadGroup struct {
+	mu   sync.Mutex
+	call *reloadCall
+}
+
+type reloadCall struct {
+	done chan struct{}
+	err  error
+}
+
+func (g *reloadGroup) do(ctx context.Context, fn func(context.Context) error) error {
+	g.mu.Lock()
+	c := g.call
+	if c == nil {
+		c = &reloadCall{done: make(chan struct{})}
+		g.call = c
+		go g.run(ctx, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *reloadGroup) run(ctx context.Context, c *reloadCall, fn func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedReloadTimeout)
+	defer cancel()
+
+	c.err = fn(ctx)
+
+	g.mu.Lock()
+	g.call = nil
+	g.mu.Unlock()
+	close(c.done)
+}
@@ -0,0 +1,117 @@
+package keys_manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type gatedStore struct {
+	*MockStore
+	gate  chan struct{}
+	lists atomic.Int64
+}
+
+func (s *gatedStore) List() ([]*Key, error) {
+	s.lists.Add(1)
+	<-s.gate
+	return s.MockStore.List()
+}
+
+func TestImplicitReload_ConcurrentMissesShareOneReload(t *testing.T) {
+	store := &gatedStore{MockStore: NewMockStore(), gate: make(chan struct{})}
+	close(store.gate)
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	store.gate = make(chan struct{})
+	store.lists.Store(0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			km.keyByKID("unknown")
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(store.gate)
+	wg.Wait()
+
+	if got := store.lists.Load(); got != 1 {
+		t.Fatalf("expected concurrent misses to share 1 reload, got %d", got)
+	}
+}
+
+func TestReloadGroup_WaiterRespectsContext(t *testing.T) {
+	var g reloadGroup
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_ = g.do(context.Background(), func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := g.do(ctx, func(context.Context) error {
+		t.Fatalf("waiter must not start its own reload")
+		return nil
+	})
+	close(release)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestReloadGroup_LeaderCancelDoesNotFailWaiters(t *testing.T) {
+	var g reloadGroup
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		leader <- g.do(leaderCtx, func(ctx context.Context) error {
+			close(started)
+			<-release
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		waiter <- g.do(context.Background(), func(context.Context) error {
+			t.Errorf("waiter must not start its own reload")
+			return nil
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancelLeader()
+	if err := <-leader; err != context.Canceled {
+		t.Fatalf("expected the leader to stop waiting with Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := <-waiter; err != nil {
+		t.Fatalf("the shared reload must not see the leader's cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package keys_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMinReloadInterval_ThrottlesMisses(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	km, store := newCountingManager(t, clock, WithMinReloadInterval(time.Second))
+
+	km.keyByKID("a")
+	km.keyByKID("b")
+	km.keyByKID("c")
+
+	if got := store.lists.Load(); got != 0 {
+		t.Fatalf("expected misses right after startup to be throttled, got %d reloads", got)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	km.keyByKID("a")
+	km.keyByKID("b")
+
+	if got := store.lists.Load(); got != 1 {
+		t.Fatalf("expected 1 reload after the interval, got %d", got)
+	}
+}
+
+func TestMinReloadInterval_ExplicitReloadIsForced(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	km, store := newCountingManager(t, clock, WithMinReloadInterval(time.Hour))
+
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+	if err := km.ReloadCache(); err != nil {
+		t.Fatalf("ReloadCache error: %v", err)
+	}
+
+	if got := store.lists.Load(); got != 2 {
+		t.Fatalf("expected explicit reloads to bypass the interval, got %d", got)
+	}
+}
@@ -0,0 +1,107 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultReplayRetention is how long the jti of a token without an exp
+// claim is remembered.
+const DefaultReplayRetention = 24 * time.Hour
+
+// ReplayCache remembers token IDs. Seen reports whether id was recorded
+// before and otherwise records it for ttl; it must do both atomically when
+// shared between replicas.
+type ReplayCache interface {
+	Seen(ctx context.Context, id string, ttl time.Duration) (bool, error)
+}
+
+// NewTokenID returns a random token ID for the jti claim of one-time
+// tokens, drawn from the manager's randomness source.
+func (km *KeyManager) NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(km.entropy(), buf); err != nil {
+		return "", fmt.Errorf("generate token id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// checkReplay records the jti claim, keeping it until the token expires
+// plus the clock skew.
+func (km *KeyManager) checkReplay(ctx context.Context, claims map[string]any) error {
+	if km.replay == nil {
+		return nil
+	}
+
+	v, ok := claims["jti"]
+	if !ok {
+		return nil
+	}
+	jti, ok := v.(string)
+	if !ok || jti == "" {
+		return fmt.Errorf("%w: jti is not a string", ErrInvalidToken)
+	}
+
+	ttl := DefaultReplayRetention
+	exp, ok, err := numericDate(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if ok {
+		ttl = max(exp.Add(km.skew).Sub(km.clock.Now()), time.Second)
+	}
+
+	seen, err := km.replay.Seen(ctx, jti, ttl)
+	if err != nil {
+		return fmt.Errorf("replay cache: %w", err)
+	}
+	if seen {
+		return fmt.Errorf("%w: jti %s", ErrTokenReplayed, jti)
+	}
+
+	return nil
+}
+
+// MemoryReplayCache is a ReplayCache for a single process. Expired IDs are
+// dropped at most once per minute.
+type MemoryReplayCache struct {
+	clock Clock
+
+	mu        sync.Mutex
+	ids       map[string]time.Time
+	nextPrune time.Time
+}
+
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{
+		clock: systemClock{},
+		ids:   make(map[string]time.Time),
+	}
+}
+
+func (c *MemoryReplayCache) Seen(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.After(c.nextPrune) {
+		for k, exp := range c.ids {
+			if !now.Before(exp) {
+				delete(c.ids, k)
+			}
+		}
+		c.nextPrune = now.Add(time.Minute)
+	}
+
+	if exp, ok := c.ids[id]; ok && now.Before(exp) {
+		return true, nil
+	}
+
+	c.ids[id] = now.Add(ttl)
+	return false, nil
+}
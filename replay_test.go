@@ -0,0 +1,68 @@
+package keys_manager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReplayCache_RejectsReusedJTI(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(2_000_000_000, 0)}
+	cache := NewMemoryReplayCache()
+	cache.clock = clock
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock), WithReplayCache(cache))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	jti, err := km.NewTokenID()
+	if err != nil {
+		t.Fatalf("NewTokenID error: %v", err)
+	}
+	other, _ := km.NewTokenID()
+	if jti == other {
+		t.Fatalf("token IDs must be unique")
+	}
+
+	token := signTestJWT(t, km, AlgEdDSA, fmt.Sprintf(`{"jti":%q,"exp":2000000060}`, jti))
+
+	if _, err := km.VerifyJWT(token); err != nil {
+		t.Fatalf("first use must verify, got %v", err)
+	}
+	if _, err := km.VerifyJWT(token); !errors.Is(err, ErrTokenReplayed) {
+		t.Fatalf("expected ErrTokenReplayed, got %v", err)
+	}
+
+	plain := signTestJWT(t, km, AlgEdDSA, `{"exp":2000000060}`)
+	for range 2 {
+		if _, err := km.VerifyJWT(plain); err != nil {
+			t.Fatalf("tokens without jti are not tracked, got %v", err)
+		}
+	}
+
+	bad := signTestJWT(t, km, AlgEdDSA, `{"jti":42}`)
+	if _, err := km.VerifyJWT(bad); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("non-string jti must be invalid, got %v", err)
+	}
+}
+
+func TestMemoryReplayCache_Expiry(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(2_000_000_000, 0)}
+	cache := NewMemoryReplayCache()
+	cache.clock = clock
+
+	if seen, _ := cache.Seen(t.Context(), "a", time.Minute); seen {
+		t.Fatalf("new id must not be seen")
+	}
+	if seen, _ := cache.Seen(t.Context(), "a", time.Minute); !seen {
+		t.Fatalf("recorded id must be seen")
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if seen, _ := cache.Seen(t.Context(), "b", time.Minute); seen {
+		t.Fatalf("new id must not be seen")
+	}
+	if _, ok := cache.ids["a"]; ok {
+		t.Fatalf("expired ids must be pruned")
+	}
+}
@@ -0,0 +1,143 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+type ReSignOptions struct {
+	// Alg selects the active key the token is re-signed with. It defaults
+	// to the alg of the key that signed the token.
+	Alg Alg
+
+	// Purpose must be empty or match the purpose of the key that signed
+	// the token, which is always used, unless ChangePurpose is set.
+	Purpose Purpose
+
+	// ChangePurpose re-signs with the active key of Purpose instead, which
+	// may be PurposeDefault.
+	ChangePurpose bool
+
+	// AllowRevoked accepts tokens signed by a revoked key. Without it
+	// ReSign fails with ErrKeyRevoked like VerifyJWT.
+	AllowRevoked bool
+}
+
+// ReSign verifies a compact JWS token and signs its claims again with the
+// current active key, for re-issuing tokens after an emergency rotation.
+// The payload is kept byte for byte; the header keeps its other fields
+// but gets the new alg and kid, and loses fields that embed or point at
+// the old key (jwk, jku, x5c, x5u, x5t). Expired or not yet valid tokens are
+// refused, and the replay cache is not consulted.
+func (km *KeyManager) ReSign(token string, opts ReSignOptions) (string, error) {
+	return km.ReSignCtx(context.Background(), token, opts)
+}
+
+func (km *KeyManager) ReSignCtx(ctx context.Context, token string, opts ReSignOptions) (string, error) {
+	header, payload, sig, ok := splitJWT(token)
+	if !ok {
+		return "", fmt.Errorf("%w: malformed compact serialization", ErrInvalidToken)
+	}
+
+	var h jwtHeader
+	if err := decodeJWTPart(header, &h); err != nil {
+		return "", fmt.Errorf("%w: header: %w", ErrInvalidToken, err)
+	}
+	if err := h.check(); err != nil {
+		return "", err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := decodeJWTPart(header, &fields); err != nil {
+		return "", fmt.Errorf("%w: header: %w", ErrInvalidToken, err)
+	}
+
+	rawSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return "", fmt.Errorf("%w: signature: %w", ErrInvalidToken, err)
+	}
+
+	signingInput := []byte(token[:len(header)+1+len(payload)])
+	old, err := km.verifyForReSign(ctx, h, signingInput, rawSig, opts.AllowRevoked)
+	if err != nil {
+		return "", err
+	}
+
+	var claims map[string]any
+	if err := decodeJWTPart(payload, &claims); err != nil {
+		return "", fmt.Errorf("%w: claims: %w", ErrInvalidToken, err)
+	}
+	if err := km.checkTimeClaims(claims); err != nil {
+		return "", err
+	}
+
+	alg := opts.Alg
+	if alg == "" {
+		alg = old.Alg
+	}
+	purpose := old.Purpose
+	switch {
+	case opts.ChangePurpose:
+		purpose = opts.Purpose
+	case opts.Purpose != "" && opts.Purpose != old.Purpose:
+		return "", fmt.Errorf("%w: token was signed for purpose %q, not %q", ErrInvalidToken, old.Purpose, opts.Purpose)
+	}
+	for _, name := range keyBoundHeaders {
+		delete(fields, name)
+	}
+
+	var newInput string
+	newSig, err := km.signWithPurpose(ctx, alg, purpose, jwsInput, func(kid string) ([]byte, error) {
+		fields["alg"], _ = json.Marshal(alg)
+		fields["kid"], _ = json.Marshal(kid)
+
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		newInput = b64(raw) + "." + payload
+		return []byte(newInput), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return newInput + "." + b64(newSig), nil
+}
+
+// keyBoundHeaders name header fields that describe the signing key itself
+// and would point at the old key after re-signing.
+var keyBoundHeaders = []string{"jwk", "jku", "x5c", "x5u", "x5t", "x5t#S256"}
+
+// verifyForReSign checks the token signature like verify, except that
+// revoked keys are accepted when allowRevoked is set.
+func (km *KeyManager) verifyForReSign(ctx context.Context, h jwtHeader, input, sig []byte, allowRevoked bool) (*Key, error) {
+	ck := km.keyByKIDCtx(ctx, h.Kid)
+	if ck == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, h.Kid)
+	}
+
+	if !allowRevoked && (ck.key.RevokedAt != nil || km.listRevoked(h.Kid)) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRevoked, h.Kid)
+	}
+	if h.Alg != ck.key.Alg {
+		return nil, fmt.Errorf("%w: alg %s does not match key %s (%s)", ErrInvalidToken, h.Alg, h.Kid, ck.key.Alg)
+	}
+	now := km.clock.Now()
+	if km.notYetValid(ck.key, now) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotYetValid, h.Kid)
+	}
+	if err := km.checkVerifyUntil(ck.key, now); err != nil {
+		return nil, err
+	}
+	if err := km.verifyKey(h.Alg, ck, input, sig); err != nil {
+		return nil, err
+	}
+
+	return ck.key, nil
+}
@@ -0,0 +1,114 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReSign(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(2_000_000_000, 0)}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock))
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgES256})
+
+	claims := `{"sub":"alice","exp":2000000600}`
+	token := signTestJWT(t, km, AlgEdDSA, claims)
+	oldKID := km.activeKey(AlgEdDSA).key.KID
+
+	if err := km.Revoke(oldKID); err != nil {
+		t.Fatalf("Revoke error: %v", err)
+	}
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	if _, err := km.ReSign(token, ReSignOptions{}); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("expected ErrKeyRevoked without AllowRevoked, got %v", err)
+	}
+
+	fresh, err := km.ReSign(token, ReSignOptions{AllowRevoked: true})
+	if err != nil {
+		t.Fatalf("ReSign error: %v", err)
+	}
+
+	var h map[string]any
+	header, payload, _, _ := splitJWT(fresh)
+	_ = decodeJWTPart(header, &h)
+	if h["kid"] == oldKID || h["kid"] != km.activeKey(AlgEdDSA).key.KID {
+		t.Fatalf("expected token re-signed with the new active key, got header %v", h)
+	}
+	if h["typ"] != "JWT" {
+		t.Fatalf("other header fields must be kept, got %v", h)
+	}
+	if _, oldPayload, _, _ := splitJWT(token); payload != oldPayload {
+		t.Fatalf("payload must be kept byte for byte")
+	}
+
+	got, err := km.VerifyJWT(fresh)
+	if err != nil || got["sub"] != "alice" {
+		t.Fatalf("re-signed token must verify, got %v, %v", got, err)
+	}
+
+	fresh, err = km.ReSign(token, ReSignOptions{Alg: AlgES256, AllowRevoked: true})
+	if err != nil {
+		t.Fatalf("ReSign with alg error: %v", err)
+	}
+	header, _, _, _ = splitJWT(fresh)
+	_ = decodeJWTPart(header, &h)
+	if h["alg"] != string(AlgES256) {
+		t.Fatalf("expected ES256 header, got %v", h)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	if _, err := km.ReSign(token, ReSignOptions{AllowRevoked: true}); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestReSign_KeepsPurposeAndDropsKeyHeaders(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeysWithPurpose("id-token", []Alg{AlgEdDSA})
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	var input string
+	sig, err := km.SignWithPurpose(AlgEdDSA, "id-token", func(kid string) ([]byte, error) {
+		header := fmt.Sprintf(`{"alg":"EdDSA","kid":%q,"jku":"https://old.example/jwks","x5t":"abc"}`, kid)
+		input = b64([]byte(header)) + "." + b64([]byte(`{"sub":"alice"}`))
+		return []byte(input), nil
+	})
+	if err != nil {
+		t.Fatalf("SignWithPurpose error: %v", err)
+	}
+	token := input + "." + b64(sig)
+
+	fresh, err := km.ReSign(token, ReSignOptions{})
+	if err != nil {
+		t.Fatalf("ReSign error: %v", err)
+	}
+	var h map[string]any
+	header, _, _, _ := splitJWT(fresh)
+	_ = decodeJWTPart(header, &h)
+	if h["kid"] != km.activeKeyFor(context.Background(), keySlot{alg: AlgEdDSA, purpose: "id-token"}).key.KID {
+		t.Fatalf("expected the id-token key to re-sign, got %v", h)
+	}
+	if _, ok := h["jku"]; ok {
+		t.Fatalf("jku must be dropped, got %v", h)
+	}
+	if _, ok := h["x5t"]; ok {
+		t.Fatalf("x5t must be dropped, got %v", h)
+	}
+
+	if _, err := km.ReSign(token, ReSignOptions{Purpose: "other"}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for a purpose change, got %v", err)
+	}
+
+	fresh, err = km.ReSign(token, ReSignOptions{Purpose: PurposeDefault, ChangePurpose: true})
+	if err != nil {
+		t.Fatalf("ReSign with ChangePurpose error: %v", err)
+	}
+	header, _, _, _ = splitJWT(fresh)
+	_ = decodeJWTPart(header, &h)
+	if h["kid"] != km.activeKey(AlgEdDSA).key.KID {
+		t.Fatalf("expected the default key to re-sign, got %v", h)
+	}
+}
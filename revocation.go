@@ -0,0 +1,162 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RevocationListType is the typ header of signed revocation lists.
+const RevocationListType = "krl+jwt"
+
+// RevocationList is the payload of a signed revocation list: every revoked
+// KID in the store with the time it was revoked, in Unix seconds.
+type RevocationList struct {
+	IssuedAt int64        `json:"iat"`
+	Revoked  []RevokedKey `json:"revoked"`
+}
+
+type RevokedKey struct {
+	KID       string `json:"kid"`
+	RevokedAt int64  `json:"revoked_at"`
+}
+
+type revocationHeader struct {
+	Alg Alg    `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+type revocationSet struct {
+	issuedAt int64
+	kids     map[string]time.Time
+}
+
+func (km *KeyManager) ExportRevocationList(alg Alg) (string, error) {
+	return km.ExportRevocationListCtx(context.Background(), alg)
+}
+
+// ExportRevocationListCtx lists the revoked keys in the store and returns
+// them as a compact JWS signed with the active key of alg for the purpose
+// set with WithRevocationSigner. Validators that cache the JWKS for long
+// can load it with WithRevocationList or ApplyRevocationList to reject
+// revoked keys before their cache expires.
+func (km *KeyManager) ExportRevocationListCtx(ctx context.Context, alg Alg) (string, error) {
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	list := RevocationList{IssuedAt: km.clock.Now().Unix(), Revoked: []RevokedKey{}}
+	for _, k := range keys {
+		if k.RevokedAt != nil {
+			list.Revoked = append(list.Revoked, RevokedKey{KID: k.KID, RevokedAt: k.RevokedAt.Unix()})
+		}
+	}
+	sort.Slice(list.Revoked, func(i, j int) bool {
+		return list.Revoked[i].KID < list.Revoked[j].KID
+	})
+
+	payload, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+
+	s := keySlot{alg: alg, purpose: km.revocationSigner}
+	ck := km.activeKeyFor(ctx, s)
+	if ck == nil {
+		return "", fmt.Errorf("%w for %s", ErrNoActiveKey, s)
+	}
+	ck, err = km.materialize(ck)
+	if err != nil {
+		return "", err
+	}
+
+	var signingInput string
+	sig, err := km.signAs(ctx, ck, jwsInput, func(kid string) ([]byte, error) {
+		header, err := json.Marshal(revocationHeader{Alg: alg, Kid: kid, Typ: RevocationListType})
+		if err != nil {
+			return nil, err
+		}
+		signingInput = b64(header) + "." + b64(payload)
+		return []byte(signingInput), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + b64(sig), nil
+}
+
+// ApplyRevocationList verifies a list made by ExportRevocationList against
+// the manager's keys and from then on rejects verification with the keys
+// it names, even if the store or JWKS the manager was loaded from still
+// shows them as valid. Only lists signed by a key of the purpose set with
+// WithRevocationSigner are accepted. A list issued before the one already
+// applied is ignored, so an old list cannot undo newer revocations.
+func (km *KeyManager) ApplyRevocationList(list string) error {
+	return km.ApplyRevocationListCtx(context.Background(), list)
+}
+
+func (km *KeyManager) ApplyRevocationListCtx(ctx context.Context, list string) error {
+	header, payload, sig, ok := splitJWT(list)
+	if !ok {
+		return fmt.Errorf("%w: malformed revocation list", ErrInvalidToken)
+	}
+
+	var h revocationHeader
+	if err := decodeJWTPart(header, &h); err != nil {
+		return fmt.Errorf("%w: revocation list header: %w", ErrInvalidToken, err)
+	}
+	if h.Typ != RevocationListType {
+		return fmt.Errorf("%w: typ %q is not a revocation list", ErrInvalidToken, h.Typ)
+	}
+
+	rawSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("%w: revocation list signature: %w", ErrInvalidToken, err)
+	}
+	if ck := km.keyByKIDCtx(ctx, h.Kid); ck != nil && ck.key.Purpose != km.revocationSigner {
+		return fmt.Errorf("%w: %s is not a revocation list signer", ErrInvalidToken, h.Kid)
+	}
+	if err := km.verify(ctx, h.Kid, h.Alg, false, jwsInput, []byte(list[:len(header)+1+len(payload)]), rawSig); err != nil {
+		return err
+	}
+
+	var rl RevocationList
+	if err := decodeJWTPart(payload, &rl); err != nil {
+		return fmt.Errorf("%w: revocation list: %w", ErrInvalidToken, err)
+	}
+
+	set := &revocationSet{issuedAt: rl.IssuedAt, kids: make(map[string]time.Time, len(rl.Revoked))}
+	for _, r := range rl.Revoked {
+		set.kids[r.KID] = time.Unix(r.RevokedAt, 0)
+	}
+
+	for {
+		cur := km.revocations.Load()
+		if cur != nil && cur.issuedAt > set.issuedAt {
+			return nil
+		}
+		if km.revocations.CompareAndSwap(cur, set) {
+			break
+		}
+	}
+
+	km.logger().Info("revocation list applied", "kid", h.Kid, "revoked", len(set.kids))
+
+	return nil
+}
+
+// listRevoked reports whether an applied revocation list names kid.
+func (km *KeyManager) listRevoked(kid string) bool {
+	set := km.revocations.Load()
+	if set == nil {
+		return false
+	}
+	_, ok := set.kids[kid]
+	return ok
+}
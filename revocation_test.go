@@ -0,0 +1,159 @@
+package keys_manager
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRevocationList_OfflineValidator(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	store := NewMockStore()
+	issuer, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithClock(clock))
+	_ = issuer.InitKeys([]Alg{AlgEdDSA})
+	old := issuer.activeKey(AlgEdDSA).key.KID
+
+	payload := []byte("payload")
+	sig, err := issuer.SignWithKID(old, func(string) ([]byte, error) { return payload, nil })
+	if err != nil {
+		t.Fatalf("SignWithKID error: %v", err)
+	}
+
+	_ = issuer.Rotate(AlgEdDSA)
+
+	// The validator works from a copy of the store taken before the
+	// revocation, like a long cached JWKS.
+	cached := NewMockStore()
+	keys, _ := store.List()
+	for _, k := range keys {
+		_ = cached.Save(cloneKey(k))
+	}
+	validator, _ := NewKeyManager(cached, MockEncryptor{}, mockPolicy, WithVerifyOnly(), WithClock(clock))
+
+	if err := issuer.Revoke(old); err != nil {
+		t.Fatalf("Revoke error: %v", err)
+	}
+
+	stale, _ := issuer.ExportRevocationList(AlgEdDSA)
+
+	clock.now = clock.now.Add(time.Minute)
+	list, err := issuer.ExportRevocationList(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("ExportRevocationList error: %v", err)
+	}
+
+	if err := validator.Verify(old, payload, sig); err != nil {
+		t.Fatalf("cached key must verify before the list is applied: %v", err)
+	}
+
+	if err := validator.ApplyRevocationList(list); err != nil {
+		t.Fatalf("ApplyRevocationList error: %v", err)
+	}
+	if err := validator.Verify(old, payload, sig); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("expected ErrKeyRevoked after applying the list, got %v", err)
+	}
+	if err := validator.VerifyAny(AlgEdDSA, payload, sig); err == nil {
+		t.Fatalf("VerifyAny must skip keys on the revocation list")
+	}
+
+	if err := validator.ApplyRevocationList(stale); err != nil {
+		t.Fatalf("stale list must be ignored, got %v", err)
+	}
+	if err := validator.Verify(old, payload, sig); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("stale list must not replace a newer one, got %v", err)
+	}
+
+	fresh, err := NewKeyManager(cached, MockEncryptor{}, mockPolicy, WithVerifyOnly(), WithRevocationList(list))
+	if err != nil {
+		t.Fatalf("NewKeyManager with revocation list error: %v", err)
+	}
+	if err := fresh.Verify(old, payload, sig); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("expected ErrKeyRevoked with WithRevocationList, got %v", err)
+	}
+}
+
+func TestRevocationList_RejectsTampering(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgES256})
+
+	list, _ := km.ExportRevocationList(AlgES256)
+	parts := strings.Split(list, ".")
+
+	forged := parts[0] + "." + b64([]byte(`{"iat":1,"revoked":[]}`)) + "." + parts[2]
+	if err := km.ApplyRevocationList(forged); err == nil {
+		t.Fatalf("forged revocation list must be rejected")
+	}
+
+	if _, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithRevocationList("garbage")); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for a malformed list, got %v", err)
+	}
+}
+
+func TestRevocationList_OnlyFromRevocationSigner(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithRevocationSigner("revocation"))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	_ = km.InitKeysWithPurpose("revocation", []Alg{AlgEdDSA})
+	victim := km.activeKey(AlgEdDSA).key.KID
+
+	var input string
+	sig, err := km.SignWithPurpose(AlgEdDSA, PurposeDefault, func(kid string) ([]byte, error) {
+		header := `{"alg":"EdDSA","kid":"` + kid + `","typ":"` + RevocationListType + `"}`
+		input = b64([]byte(header)) + "." + b64([]byte(`{"iat":1,"revoked":[{"kid":"`+victim+`","revoked_at":1}]}`))
+		return []byte(input), nil
+	})
+	if err != nil {
+		t.Fatalf("SignWithPurpose error: %v", err)
+	}
+	if err := km.ApplyRevocationList(input + "." + b64(sig)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("a list signed by another purpose must be rejected, got %v", err)
+	}
+
+	list, err := km.ExportRevocationList(AlgEdDSA)
+	if err != nil {
+		t.Fatalf("ExportRevocationList error: %v", err)
+	}
+	if _, err := NewVerifier(store, WithRevocationSigner("revocation"), WithRevocationList(list)); err != nil {
+		t.Fatalf("NewVerifier with revocation list error: %v", err)
+	}
+	if _, err := NewVerifier(store, WithRevocationList(list)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("a verifier must check the signer purpose too, got %v", err)
+	}
+}
+
+func TestRevokedActiveKey_RejectedAtSign(t *testing.T) {
+	store := NewMockStore()
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	key := makeTestKey("revoked", AlgEdDSA, true, nil, MockEncryptor{}, priv)
+	revokedAt := time.Now()
+	key.RevokedAt = &revokedAt
+	_ = store.Save(key)
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if _, err := km.ActiveSigner(AlgEdDSA); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("ActiveSigner: expected ErrKeyRevoked, got %v", err)
+	}
+	if _, _, err := km.SignBatch(AlgEdDSA, [][]byte{[]byte("x")}, 1); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("SignBatch: expected ErrKeyRevoked, got %v", err)
+	}
+
+	listed, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = listed.InitKeys([]Alg{AlgEdDSA})
+	kid := listed.activeKey(AlgEdDSA).key.KID
+	listed.revocations.Store(&revocationSet{kids: map[string]time.Time{kid: revokedAt}})
+
+	build := func(string) ([]byte, error) { return []byte("x"), nil }
+	if _, err := listed.Sign(AlgEdDSA, build); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("Sign: expected ErrKeyRevoked for a listed key, got %v", err)
+	}
+	if _, err := listed.Signer(kid); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("Signer: expected ErrKeyRevoked for a listed key, got %v", err)
+	}
+	if _, err := listed.ActiveSigner(AlgEdDSA); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("ActiveSigner: expected ErrKeyRevoked for a listed key, got %v", err)
+	}
+	if _, _, err := listed.SignBatch(AlgEdDSA, [][]byte{[]byte("x")}, 1); !errors.Is(err, ErrKeyRevoked) {
+		t.Fatalf("SignBatch: expected ErrKeyRevoked for a listed key, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var selfTestCanary = []byte("keys-manager self-test canary")
+
+type SelfTestResult struct {
+	KID     string
+	Alg     Alg
+	Purpose Purpose
+	Err     error
+}
+
+type SelfTestReport struct {
+	Keys    []SelfTestResult
+	JWKSErr error
+}
+
+func (r *SelfTestReport) OK() bool {
+	return r.Err() == nil
+}
+
+func (r *SelfTestReport) Err() error {
+	var errs []error
+	if r.JWKSErr != nil {
+		errs = append(errs, fmt.Errorf("jwks: %w", r.JWKSErr))
+	}
+	for _, res := range r.Keys {
+		if res.Err != nil {
+			errs = append(errs, &KeyError{KID: res.KID, Alg: res.Alg, Err: res.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SelfTest signs and verifies a canary payload with every active key and
+// checks that each key survives a JWKS round trip. It is meant to run at
+// startup so corrupted key material fails a deploy early. Canary signatures
+// are not counted in key usage statistics.
+func (km *KeyManager) SelfTest() *SelfTestReport {
+	slots := km.snapshot().active
+
+	active := make([]*CachedKey, 0, len(slots))
+	for _, ck := range slots {
+		active = append(active, ck)
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].key.KID < active[j].key.KID
+	})
+
+	report := &SelfTestReport{}
+
+	published, err := km.publishedKeys()
+	if err != nil {
+		report.JWKSErr = err
+	}
+
+	for _, ck := range active {
+		res := SelfTestResult{KID: ck.key.KID, Alg: ck.key.Alg, Purpose: ck.key.Purpose}
+
+		if ck.key.Alg.symmetric() {
+			res.Err = selfTestSecret(ck)
+		} else if err := km.selfTestSign(ck); err != nil {
+			res.Err = err
+		} else if published != nil {
+			res.Err = selfTestJWK(ck, published)
+		}
+
+		report.Keys = append(report.Keys, res)
+	}
+
+	return report
+}
+
+func (km *KeyManager) publishedKeys() (map[string]JWK, error) {
+	raw, err := km.JWKS()
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks JWKS
+	if err := json.Unmarshal(raw, &jwks); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]JWK, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		out[k.Kid] = k
+	}
+
+	return out, nil
+}
+
+func (km *KeyManager) selfTestSign(ck *CachedKey) error {
+	if ck.priv == nil {
+		return nil
+	}
+
+	opts, err := signingOptions(ck.key.Alg)
+	if err != nil {
+		return err
+	}
+
+	sig, err := km.signInput(rand.Reader, ck, opts, newDigester(opts), selfTestCanary)
+	if err != nil {
+		return fmt.Errorf("sign canary: %w", err)
+	}
+
+	if err := verifySignature(ck.key.Alg, ck.pub, selfTestCanary, sig); err != nil {
+		return fmt.Errorf("verify canary: %w", err)
+	}
+
+	return nil
+}
+
+func selfTestJWK(ck *CachedKey, published map[string]JWK) error {
+	jwk, ok := published[ck.key.KID]
+	if !ok {
+		return errors.New("missing from JWKS")
+	}
+
+	pub, err := jwkToPublicKey(jwk)
+	if err != nil {
+		return err
+	}
+
+	eq, ok := pub.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !eq.Equal(ck.pub) {
+		return errors.New("JWKS public key does not match key material")
+	}
+
+	return nil
+}
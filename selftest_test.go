@@ -0,0 +1,107 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSelfTest_AllKeysPass(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err := km.InitKeys([]Alg{AlgRS256, AlgES256, AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+
+	report := km.SelfTest()
+	if !report.OK() {
+		t.Fatalf("self-test failed: %v", report.Err())
+	}
+
+	if len(report.Keys) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(report.Keys))
+	}
+
+	for _, info := range km.Keys() {
+		if info.SignCount != 0 || info.VerifyCount != 0 {
+			t.Fatalf("self-test must not count usage, got %+v", info)
+		}
+	}
+}
+
+type brokenSigner struct {
+	crypto.Signer
+}
+
+func (brokenSigner) Sign(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+	return make([]byte, ed25519.SignatureSize), nil
+}
+
+func TestSelfTest_DetectsCorruptedKey(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA, AlgES256})
+
+	ck := km.snapshot().active[keySlot{alg: AlgEdDSA}]
+	ck.priv = brokenSigner{ck.priv}
+
+	report := km.SelfTest()
+	if report.OK() {
+		t.Fatalf("expected self-test failure for corrupted key")
+	}
+
+	var keyErr *KeyError
+	if !errors.As(report.Err(), &keyErr) || keyErr.KID != ck.key.KID {
+		t.Fatalf("expected KeyError for %s, got %v", ck.key.KID, report.Err())
+	}
+
+	failed := 0
+	for _, res := range report.Keys {
+		if res.Err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("expected exactly one failing key, got %d", failed)
+	}
+}
+
+func TestSelfTest_DetectsJWKSMismatch(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	other, _ := generatePrivateKey(AlgEdDSA)
+
+	snap := *km.snapshot()
+	snap.active = make(map[keySlot]*CachedKey)
+	for slot, ck := range km.snapshot().active {
+		snap.active[slot] = &CachedKey{key: ck.key, priv: other, pub: other.Public(), usage: ck.usage}
+	}
+	km.snap.Store(&snap)
+
+	if report := km.SelfTest(); report.OK() {
+		t.Fatalf("expected failure when JWKS publishes a different key")
+	}
+}
+
+func TestJWKToPublicKey_RoundTrip(t *testing.T) {
+	for _, alg := range []Alg{AlgRS256, AlgES256, AlgEdDSA} {
+		priv, _ := generatePrivateKey(alg)
+		jwks := buildJWKS(map[string]*CachedKey{
+			"k": {key: &Key{KID: "k", Alg: alg}, pub: priv.Public()},
+		})
+
+		pub, err := jwkToPublicKey(jwks.Keys[0])
+		if err != nil {
+			t.Fatalf("%s: jwkToPublicKey error: %v", alg, err)
+		}
+
+		if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(priv.Public()) {
+			t.Fatalf("%s: round-tripped key differs", alg)
+		}
+	}
+
+	if _, err := jwkToPublicKey(JWK{Kty: "oct"}); err == nil {
+		t.Fatalf("expected error for unsupported kty")
+	}
+}
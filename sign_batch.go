@@ -0,0 +1,102 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+func (km *KeyManager) SignBatch(
+	alg Alg,
+	inputs [][]byte,
+	workers int,
+) (kid string, sigs [][]byte, err error) {
+	return km.SignBatchCtx(context.Background(), alg, inputs, workers)
+}
+
+// SignBatchCtx signs every input with the active key for alg, resolving the
+// key once for the whole batch. With workers > 1 the inputs are split across
+// that many goroutines. Signatures are returned in input order. The batch
+// stops early when ctx is cancelled.
+func (km *KeyManager) SignBatchCtx(
+	ctx context.Context,
+	alg Alg,
+	inputs [][]byte,
+	workers int,
+) (kid string, sigs [][]byte, err error) {
+	ck := km.signingKey(ctx, keySlot{alg: alg})
+	if ck == nil {
+		return "", nil, fmt.Errorf("%w for alg %s", ErrNoActiveKey, alg)
+	}
+
+	if alg.symmetric() {
+		return "", nil, fmt.Errorf("%w: %s keys cannot sign", ErrUnsupportedAlg, alg)
+	}
+	if ck.priv == nil {
+		return "", nil, ErrVerifyOnly
+	}
+	if ck.key.RevokedAt != nil || km.listRevoked(ck.key.KID) {
+		return "", nil, fmt.Errorf("%w: %s", ErrKeyRevoked, ck.key.KID)
+	}
+	if err := km.checkExpiry(ck.key); err != nil {
+		return "", nil, err
+	}
+	if err := km.authorize(ctx, ActionSign, ck.key); err != nil {
+		return "", nil, err
+	}
+	if err := km.allowSign(ctx, ck, len(inputs)); err != nil {
+		return "", nil, err
+	}
+
+	opts, err := signingOptions(alg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := km.clock.Now()
+
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	sigs = make([][]byte, len(inputs))
+	errs := make([]error, workers)
+
+	r := km.entropy()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			d := newDigester(opts)
+			for i := w; i < len(inputs); i += workers {
+				if err := ctx.Err(); err != nil {
+					errs[w] = err
+					return
+				}
+				sig, err := km.signInput(r, ck, opts, d, km.domainInput(rawInput, ck.key, inputs[i]))
+				if err != nil {
+					errs[w] = fmt.Errorf("sign input %d: %w", i, err)
+					return
+				}
+				sigs[i] = sig
+				ck.usage.recordSign(now)
+				km.recordSign(ctx, ck.key, inputs[i], now)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return ck.key.KID, sigs, nil
+}
@@ -0,0 +1,73 @@
+package keys_manager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func testSignBatch(t *testing.T, alg Alg, workers int) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err := km.InitKeys([]Alg{alg}); err != nil {
+		t.Fatalf("%s: InitKeys error: %v", alg, err)
+	}
+
+	inputs := make([][]byte, 25)
+	for i := range inputs {
+		inputs[i] = []byte(fmt.Sprintf("payload-%d", i))
+	}
+
+	kid, sigs, err := km.SignBatch(alg, inputs, workers)
+	if err != nil {
+		t.Fatalf("%s: SignBatch error: %v", alg, err)
+	}
+
+	if len(sigs) != len(inputs) {
+		t.Fatalf("%s: expected %d signatures, got %d", alg, len(inputs), len(sigs))
+	}
+
+	for i, sig := range sigs {
+		if err := km.Verify(kid, inputs[i], sig); err != nil {
+			t.Fatalf("%s: signature %d does not verify: %v", alg, i, err)
+		}
+	}
+
+	if got := km.Keys()[0].SignCount; got != uint64(len(inputs)) {
+		t.Fatalf("%s: expected sign count %d, got %d", alg, len(inputs), got)
+	}
+}
+
+func TestSignBatch_Sequential(t *testing.T) {
+	testSignBatch(t, AlgRS256, 1)
+	testSignBatch(t, AlgES256, 0)
+	testSignBatch(t, AlgEdDSA, 1)
+}
+
+func TestSignBatch_Parallel(t *testing.T) {
+	testSignBatch(t, AlgRS256, 4)
+	testSignBatch(t, AlgES256, 8)
+	testSignBatch(t, AlgEdDSA, 100)
+}
+
+func TestSignBatch_NoActiveKey(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	_, _, err := km.SignBatch(AlgES256, [][]byte{[]byte("x")}, 2)
+	if !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey, got %v", err)
+	}
+}
+
+func TestSignBatch_Empty(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	_, sigs, err := km.SignBatch(AlgEdDSA, nil, 4)
+	if err != nil {
+		t.Fatalf("SignBatch error: %v", err)
+	}
+
+	if len(sigs) != 0 {
+		t.Fatalf("expected no signatures, got %d", len(sigs))
+	}
+}
@@ -0,0 +1,59 @@
+package keys_manager
+
+import "testing"
+
+func benchmarkSign(b *testing.B, alg Alg) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err != nil {
+		b.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := km.InitKeys([]Alg{alg}); err != nil {
+		b.Fatalf("InitKeys error: %v", err)
+	}
+
+	input := []byte("eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxMjM0NTY3ODkwIn0")
+	build := func(string) ([]byte, error) { return input, nil }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := km.Sign(alg, build); err != nil {
+				b.Fatalf("Sign error: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkSign_RS256(b *testing.B) { benchmarkSign(b, AlgRS256) }
+func BenchmarkSign_ES256(b *testing.B) { benchmarkSign(b, AlgES256) }
+func BenchmarkSign_EdDSA(b *testing.B) { benchmarkSign(b, AlgEdDSA) }
+
+func BenchmarkVerify_EdDSA(b *testing.B) {
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	if err != nil {
+		b.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := km.InitKeys([]Alg{AlgEdDSA}); err != nil {
+		b.Fatalf("InitKeys error: %v", err)
+	}
+
+	input := []byte("payload")
+	kid := km.activeKey(AlgEdDSA).key.KID
+	sig, err := km.Sign(AlgEdDSA, func(string) ([]byte, error) { return input, nil })
+	if err != nil {
+		b.Fatalf("Sign error: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := km.Verify(kid, input, sig); err != nil {
+				b.Fatalf("Verify error: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"math/rand/v2"
+	"time"
+)
+
+// SignDigestPrefix is the number of leading SHA-256 bytes of the signing
+// input kept in a SignRecord: enough to match a record to a token, too
+// few to recover the token from the audit trail.
+const SignDigestPrefix = 8
+
+// SignRecord describes one signature made by the manager, for audit trails
+// of token issuance. Caller comes from WithCaller.
+type SignRecord struct {
+	KID     string
+	Alg     Alg
+	Purpose Purpose
+	Caller  string
+	Digest  []byte // first SignDigestPrefix bytes of SHA-256(input)
+	Time    time.Time
+}
+
+type signRecorder struct {
+	fn   func(context.Context, SignRecord)
+	rate float64
+}
+
+func (km *KeyManager) recordSign(ctx context.Context, k *Key, input []byte, now time.Time) {
+	r := km.signRecorder
+	if r == nil || (r.rate < 1 && rand.Float64() >= r.rate) {
+		return
+	}
+
+	sum := sha256.Sum256(input)
+	r.fn(ctx, SignRecord{
+		KID:     k.KID,
+		Alg:     k.Alg,
+		Purpose: k.Purpose,
+		Caller:  CallerFromContext(ctx),
+		Digest:  sum[:SignDigestPrefix],
+		Time:    now,
+	})
+}
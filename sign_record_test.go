@@ -0,0 +1,53 @@
+package keys_manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"testing"
+)
+
+func TestSignRecorder_RecordsSignatures(t *testing.T) {
+	var mu sync.Mutex
+	var records []SignRecord
+	rec := func(_ context.Context, r SignRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, r)
+	}
+
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithSignRecorder(rec, 1))
+	_ = km.InitKeysWithPurpose(PurposeAccess, []Alg{AlgES256})
+
+	input := []byte("header.claims")
+	ctx := WithCaller(context.Background(), "issuer")
+	if _, err := km.SignWithPurposeCtx(ctx, AlgES256, PurposeAccess, func(string) ([]byte, error) { return input, nil }); err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+	sum := sha256.Sum256(input)
+	if r.Alg != AlgES256 || r.Purpose != PurposeAccess || r.Caller != "issuer" || r.KID == "" || r.Time.IsZero() {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if !bytes.Equal(r.Digest, sum[:SignDigestPrefix]) {
+		t.Fatalf("expected digest prefix %x, got %x", sum[:SignDigestPrefix], r.Digest)
+	}
+}
+
+func TestSignRecorder_Sampling(t *testing.T) {
+	var n int
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithSignRecorder(func(context.Context, SignRecord) { n++ }, 0))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	for range 20 {
+		_, _ = km.Sign(AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil })
+	}
+	if n != 0 {
+		t.Fatalf("rate 0 must record nothing, got %d", n)
+	}
+}
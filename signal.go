@@ -0,0 +1,82 @@
+package keys_manager
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// SetRotationPolicy replaces the policy used by later rotations and
+// imports. Rotations already in progress keep the policy they read.
+func (km *KeyManager) SetRotationPolicy(p RotationPolicy) {
+	km.policyOverride.Store(&p)
+}
+
+func (km *KeyManager) rotationConfig() (RotationConfig, error) {
+	if p := km.policyOverride.Load(); p != nil {
+		return (*p)()
+	}
+	return km.policy()
+}
+
+// rotationConfigFor prefers a policy set with WithPurposePolicy over the
+// manager's policy.
+func (km *KeyManager) rotationConfigFor(purpose Purpose) (RotationConfig, error) {
+	if p, ok := km.purposePolicies[purpose]; ok {
+		return p()
+	}
+	return km.rotationConfig()
+}
+
+// ReloadOnSignal reloads the cache whenever one of sigs arrives, SIGHUP
+// when none are given, until the manager is closed. If loadPolicy is not
+// nil it is called first and a policy it returns replaces the current
+// one; when it fails the previous policy stays in effect and the cache is
+// still reloaded.
+func (km *KeyManager) ReloadOnSignal(loadPolicy func() (RotationPolicy, error), sigs ...os.Signal) error {
+	if km.closed.Load() {
+		return ErrClosed
+	}
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	km.bg.goFunc(func(ctx context.Context) {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-ch:
+				km.reloadOnSignal(ctx, sig, loadPolicy)
+			}
+		}
+	})
+
+	return nil
+}
+
+func (km *KeyManager) reloadOnSignal(ctx context.Context, sig os.Signal, loadPolicy func() (RotationPolicy, error)) {
+	log := km.logger()
+
+	if loadPolicy != nil {
+		p, err := loadPolicy()
+		if err != nil {
+			log.WarnContext(ctx, "rotation policy reload failed", "signal", sig.String(), "err", err)
+			km.reportError(OpSignalReload, "", err)
+		} else if p != nil {
+			km.SetRotationPolicy(p)
+		}
+	}
+
+	err := km.reload(withReloadCause(ctx, ReloadSignal))
+	if err == nil {
+		log.InfoContext(ctx, "key cache reloaded on signal", "signal", sig.String())
+	}
+	km.reportError(OpSignalReload, "", err)
+}
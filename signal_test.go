@@ -0,0 +1,80 @@
+//go:build unix
+
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	defer km.Close(context.Background())
+
+	loaded := make(chan struct{}, 1)
+	err := km.ReloadOnSignal(func() (RotationPolicy, error) {
+		loaded <- struct{}{}
+		return func() (RotationConfig, error) {
+			return RotationConfig{TTL: 5 * time.Minute}, nil
+		}, nil
+	}, syscall.SIGUSR1)
+	if err != nil {
+		t.Fatalf("ReloadOnSignal error: %v", err)
+	}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	_ = store.Save(makeTestKey("external", AlgEdDSA, true, nil, MockEncryptor{}, priv))
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+
+	select {
+	case <-loaded:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("policy loader was not called")
+	}
+	waitFor(t, func() bool { return km.activeKey(AlgEdDSA) != nil })
+
+	cfg, _ := km.rotationConfig()
+	if cfg.TTL != 5*time.Minute {
+		t.Fatalf("expected reloaded policy, got TTL %v", cfg.TTL)
+	}
+}
+
+func TestReloadOnSignal_KeepsPolicyOnLoaderError(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	defer km.Close(context.Background())
+
+	called := make(chan struct{}, 1)
+	_ = km.ReloadOnSignal(func() (RotationPolicy, error) {
+		called <- struct{}{}
+		return nil, errors.New("bad config")
+	}, syscall.SIGUSR2)
+
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+	select {
+	case <-called:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("policy loader was not called")
+	}
+
+	cfg, _ := km.rotationConfig()
+	if cfg.TTL != time.Hour {
+		t.Fatalf("previous policy must stay in effect, got TTL %v", cfg.TTL)
+	}
+}
+
+func TestReloadOnSignal_Closed(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.Close(context.Background())
+
+	if err := km.ReloadOnSignal(nil); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
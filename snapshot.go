@@ -0,0 +1,255 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const snapshotVersion = 1
+
+// KeySaver is implemented by stores that can persist a single key as is.
+// Save must retire any other active key of the same alg, purpose and labels
+// when the saved key is active.
+type KeySaver interface {
+	Save(key *Key) error
+}
+
+// KeyBatchSaver is implemented by stores that can persist several keys in
+// one transaction: every key is stored exactly as given, or none is.
+// ReEncrypt and Restore use it so a failure cannot leave the store half
+// written.
+type KeyBatchSaver interface {
+	SaveAll(keys []*Key) error
+}
+
+type snapshotEnvelope struct {
+	Version    int    `json:"version"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type snapshot struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Keys       []snapshotKey `json:"keys"`
+}
+
+// snapshotKey carries encrypted material, unlike the log-safe Key JSON.
+type snapshotKey struct {
+	KID        string            `json:"kid"`
+	Alg        Alg               `json:"alg"`
+	Purpose    Purpose           `json:"purpose,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Env        string            `json:"environment,omitempty"`
+	IsActive   bool              `json:"is_active"`
+	Pending    bool              `json:"pending,omitempty"`
+	Staged     bool              `json:"staged,omitempty"`
+	Priority   int               `json:"priority,omitempty"`
+	Weight     int               `json:"weight,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at,omitzero"`
+	NotBefore  *time.Time        `json:"not_before,omitempty"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time        `json:"revoked_at,omitempty"`
+	Nonce      []byte            `json:"nonce"`
+	Ciphertext []byte            `json:"ciphertext"`
+	PublicKey  []byte            `json:"public_key,omitempty"`
+	Cert       []byte            `json:"certificate,omitempty"`
+	Attest     *Attestation      `json:"attestation,omitempty"`
+	Escrow     []byte            `json:"escrow,omitempty"`
+}
+
+// Export returns every stored key, including its encrypted material, as a
+// single versioned bundle that is itself encrypted with the Encryptor.
+func (km *KeyManager) Export() ([]byte, error) {
+	return km.ExportCtx(context.Background())
+}
+
+// ExportCtx is Export with every key authorized for ActionExport first.
+func (km *KeyManager) ExportCtx(ctx context.Context) ([]byte, error) {
+	if km.encryptor == nil {
+		return nil, ErrVerifyOnly
+	}
+
+	keys, err := km.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if err := km.authorize(ctx, ActionExport, k); err != nil {
+			return nil, err
+		}
+	}
+
+	snap := snapshot{
+		Version:    snapshotVersion,
+		ExportedAt: km.clock.Now().UTC(),
+		Keys:       make([]snapshotKey, 0, len(keys)),
+	}
+
+	for _, k := range keys {
+		if k.EncryptedKey == nil {
+			return nil, &KeyError{KID: k.KID, Alg: k.Alg, Err: errors.New("no encrypted material")}
+		}
+
+		snap.Keys = append(snap.Keys, toSnapshotKey(k, k.EncryptedKey))
+	}
+
+	sort.Slice(snap.Keys, func(i, j int) bool {
+		a, b := snap.Keys[i], snap.Keys[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.KID < b.KID
+	})
+
+	plain, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	enc, err := km.encryptor.Encrypt(plain)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt snapshot: %w", err)
+	}
+
+	return json.Marshal(snapshotEnvelope{
+		Version:    snapshotVersion,
+		Nonce:      enc.Nonce,
+		Ciphertext: enc.Ciphertext,
+	})
+}
+
+// Import restores keys from a bundle produced by Export into the store.
+// Every key must decrypt with the current Encryptor. Keys whose KID is
+// already stored are left untouched. A KeyBatchSaver store gets all keys
+// in one transaction; with any other store a failed save deletes the keys
+// already imported, which needs a KeyDeleter. The store must implement
+// KeySaver.
+func (km *KeyManager) Import(bundle []byte) error {
+	saver, ok := km.store.(KeySaver)
+	if !ok {
+		return fmt.Errorf("import: %w", ErrStoreUnsupported)
+	}
+
+	if km.encryptor == nil {
+		return ErrVerifyOnly
+	}
+
+	var env snapshotEnvelope
+	if err := json.Unmarshal(bundle, &env); err != nil {
+		return fmt.Errorf("parse snapshot: %w", err)
+	}
+	if env.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", env.Version)
+	}
+
+	plain, err := km.encryptor.Decrypt(&EncryptedKey{Nonce: env.Nonce, Ciphertext: env.Ciphertext})
+	if err != nil {
+		return fmt.Errorf("%w: snapshot: %w", ErrDecryptFailed, err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(plain, &snap); err != nil {
+		return fmt.Errorf("parse snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	existing, err := km.store.List()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, k := range existing {
+		known[k.KID] = true
+	}
+
+	var toSave []*Key
+	for _, sk := range snap.Keys {
+		if known[sk.KID] {
+			continue
+		}
+		if err := km.checkEnvironment(sk.envKey()); err != nil {
+			return err
+		}
+
+		k := sk.key(&EncryptedKey{Nonce: sk.Nonce, Ciphertext: sk.Ciphertext})
+
+		if _, err := km.loadKey(context.Background(), k); err != nil {
+			return err
+		}
+
+		toSave = append(toSave, k)
+	}
+
+	if err := km.saveAll(saver, toSave, nil); err != nil {
+		return err
+	}
+
+	return km.ReloadCache()
+}
+
+func toSnapshotKey(k *Key, ek *EncryptedKey) snapshotKey {
+	return snapshotKey{
+		KID:        k.KID,
+		Alg:        k.Alg,
+		Purpose:    k.Purpose,
+		Labels:     k.Labels,
+		Env:        k.Environment,
+		IsActive:   k.IsActive,
+		Pending:    k.Pending,
+		Staged:     k.Staged,
+		Priority:   k.Priority,
+		Weight:     k.Weight,
+		CreatedAt:  k.CreatedAt,
+		UpdatedAt:  k.UpdatedAt,
+		NotBefore:  k.NotBefore,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		Nonce:      ek.Nonce,
+		Ciphertext: ek.Ciphertext,
+		PublicKey:  k.PublicKey,
+		Cert:       k.Certificate,
+		Attest:     k.Attestation,
+		Escrow:     k.Escrow,
+	}
+}
+
+// envKey returns the fields of sk checkEnvironment needs, before its key
+// material is touched.
+func (sk snapshotKey) envKey() *Key {
+	return &Key{KID: sk.KID, Alg: sk.Alg, Environment: sk.Env}
+}
+
+func (sk snapshotKey) key(ek *EncryptedKey) *Key {
+	return &Key{
+		KID:          sk.KID,
+		Alg:          sk.Alg,
+		Purpose:      sk.Purpose,
+		Labels:       sk.Labels,
+		Environment:  sk.Env,
+		IsActive:     sk.IsActive,
+		Pending:      sk.Pending,
+		Staged:       sk.Staged,
+		Priority:     sk.Priority,
+		Weight:       sk.Weight,
+		CreatedAt:    sk.CreatedAt,
+		UpdatedAt:    sk.UpdatedAt,
+		NotBefore:    sk.NotBefore,
+		ExpiresAt:    sk.ExpiresAt,
+		RevokedAt:    sk.RevokedAt,
+		EncryptedKey: ek,
+		PublicKey:    sk.PublicKey,
+		Certificate:  sk.Cert,
+		Attestation:  sk.Attest,
+		Escrow:       sk.Escrow,
+	}
+}
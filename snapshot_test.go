@@ -0,0 +1,145 @@
+package keys_manager
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_ExportImportRoundTrip(t *testing.T) {
+	enc, _ := NewAESGCMEncryptor(randomMasterKey(t))
+
+	src, _ := NewKeyManager(NewMockStore(), enc, mockPolicy)
+	if err := src.InitKeys([]Alg{AlgRS256, AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeys error: %v", err)
+	}
+	if err := src.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	bundle, err := src.Export()
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+
+	again, _ := src.Export()
+	if len(again) == 0 || bytes.Equal(bundle, again) {
+		t.Fatalf("bundles must be encrypted with fresh nonces")
+	}
+
+	dstStore := NewMockStore()
+	dst, _ := NewKeyManager(dstStore, enc, mockPolicy)
+
+	if err := dst.Import(bundle); err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+
+	srcKeys, dstKeys := src.Keys(), dst.Keys()
+	if len(dstKeys) != 3 || len(srcKeys) != len(dstKeys) {
+		t.Fatalf("expected 3 imported keys, got %d", len(dstKeys))
+	}
+
+	for i := range srcKeys {
+		if srcKeys[i].KID != dstKeys[i].KID || srcKeys[i].State != dstKeys[i].State {
+			t.Fatalf("key %d differs: %+v vs %+v", i, srcKeys[i], dstKeys[i])
+		}
+	}
+
+	data := []byte("payload")
+	var kid string
+	sig, err := src.Sign(AlgRS256, func(k string) ([]byte, error) {
+		kid = k
+		return data, nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	if err := dst.Verify(kid, data, sig); err != nil {
+		t.Fatalf("imported key does not verify source signature: %v", err)
+	}
+
+	if err := dst.Import(bundle); err != nil {
+		t.Fatalf("re-import error: %v", err)
+	}
+	if n := len(dst.Keys()); n != 3 {
+		t.Fatalf("re-import must skip existing keys, got %d keys", n)
+	}
+}
+
+func TestSnapshot_ImportRollsBackFailedSave(t *testing.T) {
+	src, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = src.InitKeys([]Alg{AlgEdDSA, AlgES256, AlgRS256})
+
+	bundle, err := src.Export()
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+
+	store := &unbatchedStore{mock: NewMockStore(), failAt: 2}
+	dst, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err := dst.Import(bundle); err == nil {
+		t.Fatalf("expected Import to fail")
+	}
+	if keys, _ := store.mock.List(); len(keys) != 0 {
+		t.Fatalf("a failed import must leave no keys behind, got %d", len(keys))
+	}
+}
+
+func TestSnapshot_ImportWrongMasterKey(t *testing.T) {
+	enc1, _ := NewAESGCMEncryptor(randomMasterKey(t))
+	enc2, _ := NewAESGCMEncryptor(randomMasterKey(t))
+
+	src, _ := NewKeyManager(NewMockStore(), enc1, mockPolicy)
+	_ = src.InitKeys([]Alg{AlgEdDSA})
+
+	bundle, _ := src.Export()
+
+	dst, _ := NewKeyManager(NewMockStore(), enc2, mockPolicy)
+	if err := dst.Import(bundle); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("expected ErrDecryptFailed, got %v", err)
+	}
+}
+
+func TestSnapshot_ImportRejectsUnknownVersion(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+
+	if err := km.Import([]byte(`{"version":99}`)); err == nil {
+		t.Fatalf("expected error for unknown version")
+	}
+}
+
+type listOnlyStore struct {
+	Store
+}
+
+func TestSnapshot_ImportRequiresKeySaver(t *testing.T) {
+	km, _ := NewKeyManager(listOnlyStore{NewMockStore()}, MockEncryptor{}, mockPolicy)
+
+	if err := km.Import([]byte(`{}`)); !errors.Is(err, ErrStoreUnsupported) {
+		t.Fatalf("expected ErrStoreUnsupported, got %v", err)
+	}
+}
+
+func TestSnapshot_ExportIsDeterministicallyOrdered(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+	base := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, kid := range []string{"c", "a", "b"} {
+		priv, _ := generatePrivateKey(AlgEdDSA)
+		k := makeTestKey(kid, AlgEdDSA, false, nil, enc, priv)
+		k.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		store.Save(k)
+	}
+
+	km, _ := NewKeyManager(store, enc, mockPolicy, WithClock(&fakeClock{now: base}))
+
+	first, _ := km.Export()
+	second, _ := km.Export()
+
+	if !bytes.Equal(first, second) {
+		t.Fatalf("export must be deterministic for an unchanged store")
+	}
+}
@@ -0,0 +1,132 @@
+// Package testvectors produces signing test vectors for the current keyset
+// of a KeyManager. Partner teams feed them to their verifiers before
+// go-live: every signature and token in a Set must verify against the JWKS
+// shipped with it.
+//
+// Ed25519 and RS256 signatures are deterministic; ES256 signatures are
+// randomized unless the manager uses a fixed entropy source.
+package testvectors
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	km "github.com/keylet-auth/keys-manager"
+)
+
+// DefaultPayloads are signed when Generate is given no payloads.
+var DefaultPayloads = [][]byte{
+	{},
+	[]byte("keys-manager test vector"),
+	[]byte(`{"sub":"test-vector","aud":"verifier"}`),
+}
+
+// Audience is the aud of every token vector. Verifiers must not accept it
+// for real traffic, so a leaked vector cannot stand in for a user token.
+const Audience = "keys-manager-test-vectors"
+
+// TokenLifetime bounds how long a token vector verifies; regenerate the
+// set for later runs.
+const TokenLifetime = time.Hour
+
+// Signature is a raw signature over Payload. ES256 signatures are r||s as
+// in JWS, not DER.
+type Signature struct {
+	KID       string `json:"kid"`
+	Alg       km.Alg `json:"alg"`
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// Token is a compact JWS with sub "test-vector" and aud Audience, issued
+// at GeneratedAt and expiring TokenLifetime later.
+type Token struct {
+	KID string `json:"kid"`
+	Alg km.Alg `json:"alg"`
+	JWT string `json:"jwt"`
+}
+
+type Set struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	JWKS        json.RawMessage `json:"jwks"`
+	Signatures  []Signature     `json:"signatures"`
+	Tokens      []Token         `json:"tokens"`
+}
+
+// Generate signs payloads, or DefaultPayloads when none are given, and a
+// token with every active signing key of the default purpose, in alg
+// order.
+func Generate(ctx context.Context, m *km.KeyManager, payloads ...[]byte) (*Set, error) {
+	if len(payloads) == 0 {
+		payloads = DefaultPayloads
+	}
+
+	jwks, err := m.JWKS()
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.Now().UTC()
+	claims, err := json.Marshal(map[string]any{
+		"iss": "keys-manager",
+		"sub": "test-vector",
+		"aud": Audience,
+		"iat": now.Unix(),
+		"exp": now.Add(TokenLifetime).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{
+		GeneratedAt: now,
+		JWKS:        jwks,
+		Signatures:  []Signature{},
+		Tokens:      []Token{},
+	}
+
+	kids := m.ActiveKIDs()
+	algs := make([]km.Alg, 0, len(kids))
+	for alg := range kids {
+		if alg != km.AlgA256GCM {
+			algs = append(algs, alg)
+		}
+	}
+	slices.Sort(algs)
+
+	for _, alg := range algs {
+		for _, payload := range payloads {
+			var kid string
+			sig, err := m.SignCtx(ctx, alg, func(k string) ([]byte, error) {
+				kid = k
+				return payload, nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("testvectors: sign %s: %w", alg, err)
+			}
+			set.Signatures = append(set.Signatures, Signature{KID: kid, Alg: alg, Payload: payload, Signature: sig})
+		}
+
+		var kid, input string
+		sig, err := m.SignCtx(ctx, alg, func(k string) ([]byte, error) {
+			kid = k
+			header := fmt.Sprintf(`{"alg":%q,"kid":%q,"typ":"JWT"}`, alg, k)
+			input = b64([]byte(header)) + "." + b64(claims)
+			return []byte(input), nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("testvectors: sign %s token: %w", alg, err)
+		}
+		set.Tokens = append(set.Tokens, Token{KID: kid, Alg: alg, JWT: input + "." + b64(sig)})
+	}
+
+	return set, nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,76 @@
+package testvectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	km "github.com/keylet-auth/keys-manager"
+	"github.com/keylet-auth/keys-manager/keytest"
+)
+
+func TestGenerate(t *testing.T) {
+	b := keytest.New(t, 7)
+	m := b.Manager(b.Store())
+	if err := m.InitKeys([]km.Alg{km.AlgEdDSA, km.AlgES256}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	set, err := Generate(context.Background(), m)
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+
+	if len(set.Signatures) != 2*len(DefaultPayloads) || len(set.Tokens) != 2 {
+		t.Fatalf("expected vectors for both algs, got %d signatures and %d tokens", len(set.Signatures), len(set.Tokens))
+	}
+	if set.Signatures[0].Alg != km.AlgES256 || set.Tokens[1].Alg != km.AlgEdDSA {
+		t.Fatalf("vectors must be ordered by alg")
+	}
+
+	for _, v := range set.Signatures {
+		if !strings.Contains(string(set.JWKS), v.KID) {
+			t.Fatalf("JWKS must contain %s", v.KID)
+		}
+		if err := m.Verify(v.KID, v.Payload, v.Signature); err != nil {
+			t.Fatalf("%s vector does not verify: %v", v.Alg, err)
+		}
+	}
+	if !set.GeneratedAt.Equal(keytest.Epoch) {
+		t.Fatalf("GeneratedAt must come from the manager's clock, got %v", set.GeneratedAt)
+	}
+	for _, tok := range set.Tokens {
+		claims, err := m.VerifyJWT(tok.JWT)
+		if err != nil || claims["sub"] != "test-vector" || claims["aud"] != Audience {
+			t.Fatalf("%s token does not verify: %v %v", tok.Alg, claims, err)
+		}
+		if fmt.Sprint(claims["exp"]) != fmt.Sprint(keytest.Epoch.Add(TokenLifetime).Unix()) {
+			t.Fatalf("%s token must expire after TokenLifetime, got %v", tok.Alg, claims["exp"])
+		}
+	}
+
+	b.Clock().Advance(2 * TokenLifetime)
+	if _, err := m.VerifyJWT(set.Tokens[0].JWT); !errors.Is(err, km.ErrTokenExpired) {
+		t.Fatalf("expected token vectors to expire, got %v", err)
+	}
+	b.Clock().Set(keytest.Epoch)
+
+	again, _ := Generate(context.Background(), m)
+	last := len(set.Signatures) - 1
+	if !bytes.Equal(set.Signatures[last].Signature, again.Signatures[last].Signature) {
+		t.Fatalf("Ed25519 vectors must be deterministic")
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded Set
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Signatures) != len(set.Signatures) {
+		t.Fatalf("set must round-trip through JSON: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package keys_manager
+
+import "context"
+
+// Tracer starts spans around manager operations and the store and
+// encryptor calls they make. See the otelkm package for an OpenTelemetry
+// implementation.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key, value string)
+	// End finishes the span, recording err when it is not nil.
+	End(err error)
+}
+
+// Span names used by the manager.
+const (
+	SpanSign        = "keys_manager.Sign"
+	SpanVerify      = "keys_manager.Verify"
+	SpanRotate      = "keys_manager.Rotate"
+	SpanReload      = "keys_manager.ReloadCache"
+	SpanStoreList   = "keys_manager.store.List"
+	SpanStoreGet    = "keys_manager.store.Get"
+	SpanStoreRotate = "keys_manager.store.Rotate"
+	SpanEncrypt     = "keys_manager.encryptor.Encrypt"
+	SpanDecrypt     = "keys_manager.encryptor.Decrypt"
+)
+
+func (km *KeyManager) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if km.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return km.tracer.Start(ctx, name)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, string) {}
+func (noopSpan) End(error)                   {}
@@ -1,12 +1,22 @@
 package keys_manager
 
 import (
+	"context"
 	"crypto"
+	"fmt"
 	"time"
 )
 
 type RotationConfig struct {
 	TTL time.Duration
+
+	// PropagationDelay makes Rotate publish the new key inactive and
+	// activate it only once the delay has passed, so verifiers caching the
+	// JWKS know it before the first token signed with it. Slots without an
+	// active key are filled at once, and rotating a slot again during the
+	// delay does nothing. Keys approved through WithApprover are staged
+	// the same way.
+	PropagationDelay time.Duration
 }
 
 type RotationPolicy func() (RotationConfig, error)
@@ -17,8 +27,70 @@ const (
 	AlgRS256 Alg = "RS256"
 	AlgES256 Alg = "ES256"
 	AlgEdDSA Alg = "EdDSA"
+
+	// AlgA256GCM keys are 256-bit secrets for AES-GCM, used to encrypt
+	// cookies. They cannot sign and are never published in the JWKS.
+	AlgA256GCM Alg = "A256GCM"
+
+	// RSA-PSS algs are verify-only: RS256 keys check PSS signatures made
+	// elsewhere with them, but the manager never signs or rotates them.
+	AlgPS256 Alg = "PS256"
+	AlgPS384 Alg = "PS384"
+	AlgPS512 Alg = "PS512"
+)
+
+// symmetric reports whether keys of the alg are secrets without a public
+// half.
+func (a Alg) symmetric() bool {
+	return a == AlgA256GCM
+}
+
+// pssHash returns the hash of an RSA-PSS alg; the salt is as long as the
+// hash, as RFC 7518 requires.
+func (a Alg) pssHash() (crypto.Hash, bool) {
+	switch a {
+	case AlgPS256:
+		return crypto.SHA256, true
+	case AlgPS384:
+		return crypto.SHA384, true
+	case AlgPS512:
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+type Purpose string
+
+const (
+	PurposeDefault Purpose = ""
+	PurposeAccess  Purpose = "access"
+	PurposeRefresh Purpose = "refresh"
+	PurposeWebhook Purpose = "webhook"
+	PurposeCookie  Purpose = "cookie"
 )
 
+// keySlot identifies an independently rotated line of keys.
+type keySlot struct {
+	alg     Alg
+	purpose Purpose
+	labels  string
+}
+
+func slotOf(k *Key) keySlot {
+	return keySlot{alg: k.Alg, purpose: k.Purpose, labels: formatLabels(k.Labels)}
+}
+
+func (s keySlot) String() string {
+	out := fmt.Sprintf("alg %s", s.alg)
+	if s.purpose != PurposeDefault {
+		out += fmt.Sprintf(" purpose %s", s.purpose)
+	}
+	if s.labels != "" {
+		out += fmt.Sprintf(" labels %s", s.labels)
+	}
+	return out
+}
+
 type EncryptedKey struct {
 	Nonce      []byte
 	Ciphertext []byte
@@ -27,16 +99,42 @@ type EncryptedKey struct {
 type Key struct {
 	KID          string
 	Alg          Alg
+	Purpose      Purpose
+	Labels       map[string]string
+	Environment  string // deployment the key belongs to, see WithEnvironment
 	IsActive     bool
+	Pending      bool // awaiting approval, see WithApprover
+	Staged       bool // published, activates at NotBefore; see PropagationDelay
+	Priority     int  // ranks active keys of one slot under PreferPriority
+	Weight       int  // share of Sign traffic among weighted active keys, see RotateWeighted
 	CreatedAt    time.Time
+	UpdatedAt    time.Time // bumped by the store whenever the key changes
+	NotBefore    *time.Time
 	ExpiresAt    *time.Time
+	RevokedAt    *time.Time
 	EncryptedKey *EncryptedKey
+	PublicKey    []byte // PKIX, ASN.1 DER
+	Certificate  []byte // X.509, ASN.1 DER; see WithSelfSignedCertificates
+	Attestation  *Attestation
+	Escrow       []byte // private key wrapped for the recovery key, see WithEscrow
 }
 
 type CachedKey struct {
-	key  *Key
-	priv crypto.Signer
-	pub  crypto.PublicKey
+	key   *Key
+	priv  crypto.Signer
+	pub   crypto.PublicKey
+	usage *keyUsage
+
+	// deferred is set for lazily cached keys whose private key has not
+	// been decrypted yet.
+	deferred bool
+
+	// ctDigest identifies the ciphertext the private key was decrypted
+	// from.
+	ctDigest ciphertextDigest
+
+	// secret holds the key material of symmetric keys, which have no priv.
+	secret []byte
 }
 
 type Encryptor interface {
@@ -48,3 +146,33 @@ type Store interface {
 	List() ([]*Key, error)
 	Rotate(newKey *Key, oldKey *Key) error
 }
+
+// VersionedStore is implemented by stores that can cheaply report a value
+// that changes whenever any stored key changes, e.g. a counter or an etag.
+// Reloads are skipped while the version stays the same.
+type VersionedStore interface {
+	Version() (string, error)
+}
+
+// ContextStore is implemented by stores that can abort List when the
+// caller's context is done. It is used for reloads on the request path.
+type ContextStore interface {
+	ListContext(ctx context.Context) ([]*Key, error)
+}
+
+type TokenSigner interface {
+	Sign(alg Alg, build func(kid string) ([]byte, error)) ([]byte, error)
+}
+
+type TokenVerifier interface {
+	Verify(kid string, payload, sig []byte) error
+}
+
+type KeyRotator interface {
+	Rotate(alg Alg) error
+	RotateExpired() error
+}
+
+type JWKSProvider interface {
+	JWKS() ([]byte, error)
+}
@@ -0,0 +1,77 @@
+package keys_manager
+
+import (
+	"sync"
+	"time"
+)
+
+// UnknownKIDPolicy controls whether a lookup of an uncached KID may trigger
+// a cache reload.
+type UnknownKIDPolicy int
+
+const (
+	// UnknownKIDReload reloads the cache on every miss.
+	UnknownKIDReload UnknownKIDPolicy = iota
+	// UnknownKIDNegativeCache remembers missed KIDs for a TTL and does not
+	// reload for them again until it passes.
+	UnknownKIDNegativeCache
+	// UnknownKIDRateLimit allows at most one miss-triggered reload per
+	// interval, regardless of the KID.
+	UnknownKIDRateLimit
+	// UnknownKIDStrict never reloads on a miss.
+	UnknownKIDStrict
+)
+
+const maxNegativeKIDs = 4096
+
+type unknownKIDGuard struct {
+	policy UnknownKIDPolicy
+	ttl    time.Duration
+
+	mu         sync.Mutex
+	misses     map[string]time.Time
+	lastReload time.Time
+}
+
+func (g *unknownKIDGuard) allowReload(kid string, now time.Time) bool {
+	switch g.policy {
+	case UnknownKIDStrict:
+		return false
+
+	case UnknownKIDNegativeCache:
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		until, ok := g.misses[kid]
+		return !ok || !now.Before(until)
+
+	case UnknownKIDRateLimit:
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if !g.lastReload.IsZero() && now.Sub(g.lastReload) < g.ttl {
+			return false
+		}
+		g.lastReload = now
+		return true
+
+	default:
+		return true
+	}
+}
+
+func (g *unknownKIDGuard) recordMiss(kid string, now time.Time) {
+	if g.policy != UnknownKIDNegativeCache {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Random KIDs would grow the map without bound; start over instead.
+	if g.misses == nil || len(g.misses) >= maxNegativeKIDs {
+		g.misses = make(map[string]time.Time)
+	}
+
+	g.misses[kid] = now.Add(g.ttl)
+}
@@ -0,0 +1,120 @@
+package keys_manager
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingStore struct {
+	*MockStore
+	lists atomic.Int64
+}
+
+func (s *countingStore) List() ([]*Key, error) {
+	s.lists.Add(1)
+	return s.MockStore.List()
+}
+
+func newCountingManager(t *testing.T, clock Clock, opts ...Option) (*KeyManager, *countingStore) {
+	t.Helper()
+
+	store := &countingStore{MockStore: NewMockStore()}
+	opts = append([]Option{WithClock(clock)}, opts...)
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, opts...)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+
+	store.lists.Store(0)
+	return km, store
+}
+
+func TestUnknownKID_DefaultReloadsEveryMiss(t *testing.T) {
+	km, store := newCountingManager(t, &fakeClock{now: time.Now()})
+
+	km.keyByKID("a")
+	km.keyByKID("a")
+
+	if got := store.lists.Load(); got != 2 {
+		t.Fatalf("expected 2 reloads, got %d", got)
+	}
+}
+
+func TestUnknownKID_Strict(t *testing.T) {
+	km, store := newCountingManager(t, &fakeClock{now: time.Now()},
+		WithUnknownKIDPolicy(UnknownKIDStrict, 0))
+
+	km.keyByKID("a")
+	km.keyByKID("b")
+
+	if got := store.lists.Load(); got != 0 {
+		t.Fatalf("strict mode must not reload, got %d reloads", got)
+	}
+}
+
+func TestUnknownKID_NegativeCache(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	km, store := newCountingManager(t, clock,
+		WithUnknownKIDPolicy(UnknownKIDNegativeCache, time.Minute))
+
+	km.keyByKID("a")
+	km.keyByKID("a")
+	km.keyByKID("b")
+
+	if got := store.lists.Load(); got != 2 {
+		t.Fatalf("expected one reload per distinct kid, got %d", got)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	km.keyByKID("a")
+
+	if got := store.lists.Load(); got != 3 {
+		t.Fatalf("expected reload after negative TTL, got %d", got)
+	}
+}
+
+func TestUnknownKID_NegativeCacheFindsNewKey(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	km, store := newCountingManager(t, clock,
+		WithUnknownKIDPolicy(UnknownKIDNegativeCache, time.Minute))
+
+	if km.keyByKID("late") != nil {
+		t.Fatalf("unexpected key")
+	}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("late", AlgEdDSA, false, nil, MockEncryptor{}, priv))
+
+	if km.keyByKID("late") != nil {
+		t.Fatalf("negative cache must suppress reload within TTL")
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+
+	if km.keyByKID("late") == nil {
+		t.Fatalf("key must be found after negative TTL expires")
+	}
+}
+
+func TestUnknownKID_RateLimit(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	km, store := newCountingManager(t, clock,
+		WithUnknownKIDPolicy(UnknownKIDRateLimit, time.Second))
+
+	for _, kid := range []string{"a", "b", "c", "d"} {
+		km.keyByKID(kid)
+	}
+
+	if got := store.lists.Load(); got != 1 {
+		t.Fatalf("expected a single reload within interval, got %d", got)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	km.keyByKID("e")
+
+	if got := store.lists.Load(); got != 2 {
+		t.Fatalf("expected reload after interval, got %d", got)
+	}
+}
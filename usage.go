@@ -0,0 +1,135 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type keyUsage struct {
+	signs      atomic.Uint64
+	verifies   atomic.Uint64
+	lastSign   atomic.Int64
+	lastVerify atomic.Int64
+
+	// flushed* hold the counts already handed to a UsageStore.
+	flushedSigns    atomic.Uint64
+	flushedVerifies atomic.Uint64
+}
+
+func (u *keyUsage) recordSign(now time.Time) {
+	u.signs.Add(1)
+	u.lastSign.Store(now.UnixNano())
+}
+
+func (u *keyUsage) recordVerify(now time.Time) {
+	u.verifies.Add(1)
+	u.lastVerify.Store(now.UnixNano())
+}
+
+func unixTime(ns int64) *time.Time {
+	if ns == 0 {
+		return nil
+	}
+	t := time.Unix(0, ns).UTC()
+	return &t
+}
+
+// UsageDelta counts the uses of a key since the previous flush.
+type UsageDelta struct {
+	KID            string
+	Signs          uint64
+	Verifies       uint64
+	LastSignedAt   *time.Time
+	LastVerifiedAt *time.Time
+}
+
+// UsageStore is implemented by stores that persist usage counters. Every
+// replica reports its own deltas, so the store should add them up and keep
+// the latest timestamps.
+type UsageStore interface {
+	AddUsage(ctx context.Context, deltas []UsageDelta) error
+}
+
+// UsageReader is implemented by UsageStores that can report the totals
+// added up so far, one UsageDelta per KID, so tools can tell whether a key
+// is still in use without asking every replica.
+type UsageReader interface {
+	Usage(ctx context.Context) ([]UsageDelta, error)
+}
+
+func (km *KeyManager) checkUsageStore() error {
+	if km.usageInterval <= 0 {
+		return nil
+	}
+	if _, ok := km.store.(UsageStore); !ok {
+		return fmt.Errorf("%w: usage persistence requires UsageStore", ErrStoreUnsupported)
+	}
+	return nil
+}
+
+func (km *KeyManager) usageLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			km.reportError(OpUsageFlush, "", km.flushUsage(ctx))
+		}
+	}
+}
+
+// flushUsage writes the usage recorded since the last successful flush.
+// Counts of keys that left the cache in between are dropped.
+func (km *KeyManager) flushUsage(ctx context.Context) error {
+	us, ok := km.store.(UsageStore)
+	if !ok {
+		return nil
+	}
+
+	type pending struct {
+		u               *keyUsage
+		signs, verifies uint64
+	}
+
+	var (
+		deltas []UsageDelta
+		marks  []pending
+	)
+	for kid, ck := range km.snapshot().cache {
+		u := ck.usage
+		signs, verifies := u.signs.Load(), u.verifies.Load()
+
+		d := UsageDelta{
+			KID:      kid,
+			Signs:    signs - u.flushedSigns.Load(),
+			Verifies: verifies - u.flushedVerifies.Load(),
+		}
+		if d.Signs == 0 && d.Verifies == 0 {
+			continue
+		}
+		d.LastSignedAt = unixTime(u.lastSign.Load())
+		d.LastVerifiedAt = unixTime(u.lastVerify.Load())
+
+		deltas = append(deltas, d)
+		marks = append(marks, pending{u: u, signs: signs, verifies: verifies})
+	}
+
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	if err := us.AddUsage(ctx, deltas); err != nil {
+		return err
+	}
+
+	for _, m := range marks {
+		m.u.flushedSigns.Store(m.signs)
+		m.u.flushedVerifies.Store(m.verifies)
+	}
+	return nil
+}
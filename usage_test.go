@@ -0,0 +1,123 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type usageStore struct {
+	*MockStore
+
+	mu     sync.Mutex
+	totals map[string]UsageDelta
+	fail   bool
+}
+
+func (s *usageStore) AddUsage(_ context.Context, deltas []UsageDelta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return errors.New("usage write failed")
+	}
+	if s.totals == nil {
+		s.totals = make(map[string]UsageDelta)
+	}
+	for _, d := range deltas {
+		t := s.totals[d.KID]
+		t.KID = d.KID
+		t.Signs += d.Signs
+		t.Verifies += d.Verifies
+		t.LastSignedAt = d.LastSignedAt
+		t.LastVerifiedAt = d.LastVerifiedAt
+		s.totals[d.KID] = t
+	}
+	return nil
+}
+
+func (s *usageStore) total(kid string) UsageDelta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.totals[kid]
+}
+
+func TestUsage_LastUsedTimestamps(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock))
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	if info := km.Keys()[0]; info.LastSignedAt != nil || info.LastVerifiedAt != nil {
+		t.Fatalf("unused key must have no last-used times, got %+v", info)
+	}
+
+	var kid string
+	sig, _ := km.Sign(AlgEdDSA, func(k string) ([]byte, error) {
+		kid = k
+		return []byte("x"), nil
+	})
+
+	clock.now = clock.now.Add(time.Minute)
+	if err := km.Verify(kid, []byte("x"), sig); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+
+	info := km.Keys()[0]
+	if info.LastSignedAt == nil || !info.LastSignedAt.Equal(clock.now.Add(-time.Minute)) {
+		t.Fatalf("unexpected LastSignedAt %v", info.LastSignedAt)
+	}
+	if info.LastVerifiedAt == nil || !info.LastVerifiedAt.Equal(clock.now) {
+		t.Fatalf("unexpected LastVerifiedAt %v", info.LastVerifiedAt)
+	}
+}
+
+func TestUsagePersistence_FlushesDeltas(t *testing.T) {
+	store := &usageStore{MockStore: NewMockStore()}
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithUsagePersistence(time.Hour))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	kid := km.activeKey(AlgEdDSA).key.KID
+
+	sign := func() {
+		_, _ = km.Sign(AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil })
+	}
+
+	sign()
+	sign()
+	if err := km.flushUsage(context.Background()); err != nil {
+		t.Fatalf("flushUsage error: %v", err)
+	}
+	if got := store.total(kid).Signs; got != 2 {
+		t.Fatalf("expected 2 persisted signs, got %d", got)
+	}
+
+	store.fail = true
+	sign()
+	if err := km.flushUsage(context.Background()); err == nil {
+		t.Fatalf("expected flush error")
+	}
+	store.fail = false
+
+	sign()
+	_ = km.Close(context.Background())
+
+	total := store.total(kid)
+	if total.Signs != 4 {
+		t.Fatalf("failed flushes must be retried and Close must flush, got %d signs", total.Signs)
+	}
+	if total.LastSignedAt == nil {
+		t.Fatalf("expected LastSignedAt to be persisted")
+	}
+}
+
+func TestUsagePersistence_RequiresUsageStore(t *testing.T) {
+	_, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithUsagePersistence(time.Minute))
+	if !errors.Is(err, ErrStoreUnsupported) {
+		t.Fatalf("expected ErrStoreUnsupported, got %v", err)
+	}
+}
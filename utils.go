@@ -8,12 +8,16 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	_ "crypto/sha512" // SHA-384 and SHA-512 for PSS
 	"crypto/x509"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"math/big"
-	"time"
+	"sort"
+	"sync"
 )
 
 func b64(data []byte) string {
@@ -24,33 +28,84 @@ func b64big(i *big.Int) string {
 	return b64(i.Bytes())
 }
 
-func generateKID(alg Alg) string {
+func generateKID(r io.Reader, alg Alg) (string, error) {
 	const size = 12
 
 	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("generate kid: %w", err)
+	}
+
+	randomPart := base64.RawURLEncoding.EncodeToString(buf)
+	return fmt.Sprintf("%s_%s", alg, randomPart), nil
+}
 
-	_, err := rand.Read(buf)
+// signerOpts is built once so the sign path does not box a new
+// crypto.SignerOpts per call.
+var signerOpts = map[Alg]crypto.SignerOpts{
+	AlgRS256: crypto.SHA256,
+	AlgES256: crypto.SHA256,
+	AlgEdDSA: crypto.Hash(0),
+}
 
-	if err == nil {
-		randomPart := base64.RawURLEncoding.EncodeToString(buf)
-		return fmt.Sprintf("%s_%s", alg, randomPart)
+func signingOptions(alg Alg) (crypto.SignerOpts, error) {
+	opts, ok := signerOpts[alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
 	}
+	return opts, nil
+}
 
-	ts := []byte(time.Now().Format(time.RFC3339Nano))
-	fallback := base64.RawURLEncoding.EncodeToString(append(ts, buf...))
+// digester hashes signing inputs into a reusable buffer. For algorithms
+// that sign the message directly it returns the input unchanged.
+type digester struct {
+	h    hash.Hash
+	buf  []byte
+	pool *sync.Pool
+}
 
-	return fmt.Sprintf("%s_%s", alg, fallback)
+var sha256Digesters = sync.Pool{
+	New: func() any {
+		return &digester{h: sha256.New(), buf: make([]byte, 0, sha256.Size)}
+	},
 }
 
-func signingOptions(alg Alg) (crypto.SignerOpts, error) {
-	switch alg {
-	case AlgRS256, AlgES256:
-		return crypto.SHA256, nil
-	case AlgEdDSA:
-		return crypto.Hash(0), nil
-	default:
-		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+func newDigester(opts crypto.SignerOpts) *digester {
+	if opts.HashFunc() == crypto.Hash(0) {
+		return &digester{}
 	}
+
+	return &digester{h: opts.HashFunc().New()}
+}
+
+// acquireDigester returns a pooled digester where one is available. The
+// caller must release it once the digest is no longer referenced.
+func acquireDigester(opts crypto.SignerOpts) *digester {
+	if opts.HashFunc() == crypto.SHA256 {
+		d := sha256Digesters.Get().(*digester)
+		d.pool = &sha256Digesters
+		return d
+	}
+
+	return newDigester(opts)
+}
+
+func (d *digester) release() {
+	if d.pool != nil {
+		d.pool.Put(d)
+	}
+}
+
+func (d *digester) digest(input []byte) []byte {
+	if d.h == nil {
+		return input
+	}
+
+	d.h.Reset()
+	d.h.Write(input)
+	d.buf = d.h.Sum(d.buf[:0])
+
+	return d.buf
 }
 
 func marshalPKCS8(priv crypto.Signer) ([]byte, error) {
@@ -62,6 +117,33 @@ func marshalPKCS8(priv crypto.Signer) ([]byte, error) {
 	return der, nil
 }
 
+func marshalPublicKey(pub crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("marshal pkix: %w", err)
+	}
+
+	return der, nil
+}
+
+func parsePublicKey(der []byte) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse pkix: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return k, nil
+	case *ecdsa.PublicKey:
+		return k, nil
+	case ed25519.PublicKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", k)
+	}
+}
+
 func parsePrivateKey(der []byte) (crypto.Signer, error) {
 	key, err := x509.ParsePKCS8PrivateKey(der)
 	if err != nil {
@@ -97,6 +179,22 @@ func verifySignature(alg Alg, pub crypto.PublicKey, payload, sig []byte) error {
 		}
 		return nil
 
+	case AlgPS256, AlgPS384, AlgPS512:
+		rsaKey, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("verify: public key is not RSA")
+		}
+
+		hash, _ := alg.pssHash()
+		h := hash.New()
+		h.Write(payload)
+
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+		if err := rsa.VerifyPSS(rsaKey, hash, h.Sum(nil), sig, opts); err != nil {
+			return fmt.Errorf("verify: rsa-pss signature invalid: %w", err)
+		}
+		return nil
+
 	case AlgES256:
 		ecKey, ok := pub.(*ecdsa.PublicKey)
 		if !ok {
@@ -107,10 +205,11 @@ func verifySignature(alg Alg, pub crypto.PublicKey, payload, sig []byte) error {
 		h.Write(payload)
 		digest := h.Sum(nil)
 
-		if len(sig)%2 != 0 {
-			return errors.New("verify: invalid ECDSA signature length")
+		size := ecdsaRawSize(ecKey)
+		if len(sig) != size {
+			return &SignatureLengthError{Alg: alg, Want: size, Got: len(sig)}
 		}
-		half := len(sig) / 2
+		half := size / 2
 
 		r := new(big.Int).SetBytes(sig[:half])
 		s := new(big.Int).SetBytes(sig[half:])
@@ -134,31 +233,71 @@ func verifySignature(alg Alg, pub crypto.PublicKey, payload, sig []byte) error {
 		return nil
 
 	default:
-		return fmt.Errorf("verify: unsupported alg %q", alg)
+		return fmt.Errorf("verify: %w %q", ErrUnsupportedAlg, alg)
 	}
 }
 
+// ecdsaRawSize is the length of an R||S signature on the key's curve: 64,
+// 96 and 132 bytes for P-256, P-384 and P-521.
+func ecdsaRawSize(k *ecdsa.PublicKey) int {
+	return (k.Curve.Params().BitSize + 7) / 8 * 2
+}
+
 func generatePrivateKey(alg Alg) (crypto.Signer, error) {
+	return generatePrivateKeyFrom(rand.Reader, alg)
+}
+
+// generatePrivateKeyFrom draws the key from r. ES256 scalars are read from
+// r directly, since ecdsa.GenerateKey may ignore a custom reader; RSA key
+// generation does too unless GODEBUG=cryptocustomrand=1.
+func generatePrivateKeyFrom(r io.Reader, alg Alg) (crypto.Signer, error) {
 	switch alg {
 	case AlgRS256:
-		return rsa.GenerateKey(rand.Reader, 2048)
+		return rsa.GenerateKey(r, 2048)
 	case AlgES256:
-		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if r == rand.Reader {
+			return ecdsa.GenerateKey(elliptic.P256(), r)
+		}
+		return readECDSAKey(r)
 	case AlgEdDSA:
-		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		_, priv, err := ed25519.GenerateKey(r)
 		return priv, err
 	}
-	return nil, fmt.Errorf("unknown alg: %s", alg)
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
 }
 
-func buildJWKS(cache map[string]*CachedKey) *JWKS {
-	out := &JWKS{Keys: []JWK{}}
+func readECDSAKey(r io.Reader) (*ecdsa.PrivateKey, error) {
+	buf := make([]byte, 32)
+	defer wipeBytes(buf)
 
-	for _, ck := range cache {
-		if ck == nil || ck.key == nil {
-			continue
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		// Scalars outside [1, n-1] are rejected; retrying keeps the
+		// distribution uniform.
+		if priv, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), buf); err == nil {
+			return priv, nil
 		}
+	}
+}
+
+func generateSecret(r io.Reader, alg Alg) ([]byte, error) {
+	if alg != AlgA256GCM {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := io.ReadFull(r, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
 
+func buildJWKS(cache map[string]*CachedKey) *JWKS {
+	out := &JWKS{Keys: []JWK{}}
+
+	for _, ck := range jwksOrder(cache) {
 		k := JWK{
 			Kid: ck.key.KID,
 			Alg: string(ck.key.Alg),
@@ -201,3 +340,97 @@ func buildJWKS(cache map[string]*CachedKey) *JWKS {
 
 	return out
 }
+
+// jwksOrder returns the published keys of cache in JWKS order: active keys
+// first, then by CreatedAt and KID. Map iteration order would otherwise
+// change the document, and its ETag, on every build.
+func jwksOrder(cache map[string]*CachedKey) []*CachedKey {
+	keys := make([]*CachedKey, 0, len(cache))
+	for _, ck := range cache {
+		if ck == nil || ck.key == nil || ck.key.RevokedAt != nil {
+			continue
+		}
+		keys = append(keys, ck)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i].key, keys[j].key
+		if a.IsActive != b.IsActive {
+			return a.IsActive
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.KID < b.KID
+	})
+
+	return keys
+}
+
+func jwkToPublicKey(k JWK) (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: n: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: e: %w", k.Kid, err)
+		}
+
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwk %s: exponent too large", k.Kid)
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("jwk %s: unsupported curve %q", k.Kid, k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: x: %w", k.Kid, err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: y: %w", k.Kid, err)
+		}
+		if len(x) > 32 || len(y) > 32 {
+			return nil, fmt.Errorf("jwk %s: invalid coordinate length", k.Kid)
+		}
+
+		point := make([]byte, 65)
+		point[0] = 4
+		copy(point[33-len(x):33], x)
+		copy(point[65-len(y):], y)
+
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: %w", k.Kid, err)
+		}
+
+		return pub, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %s: unsupported curve %q", k.Kid, k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %s: x: %w", k.Kid, err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %s: invalid Ed25519 key length", k.Kid)
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("jwk %s: unsupported kty %q", k.Kid, k.Kty)
+	}
+}
@@ -0,0 +1,93 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+)
+
+// Verifier checks signatures and tokens against the keys of a store
+// without ever decrypting private key material. It is backed by a
+// verify-only KeyManager with its own cache policy, so a verification
+// service can keep retired keys far longer, and reload on a different
+// schedule, than the signer that made them.
+type Verifier struct {
+	km *KeyManager
+}
+
+var _ TokenVerifier = (*Verifier)(nil)
+
+// NewVerifier loads the keys of store for verification. Options apply as
+// for NewKeyManager; the ones that only concern signing or rotation have
+// no effect. WithRevocationList and WithRevocationSigner work as for a
+// manager, so a verifier can start out rejecting revoked keys.
+func NewVerifier(store Store, opts ...Option) (*Verifier, error) {
+	opts = append([]Option{WithVerifyOnly()}, opts...)
+
+	km, err := NewKeyManager(store, nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{km: km}, nil
+}
+
+func (v *Verifier) Verify(kid string, payload, sig []byte) error {
+	return v.km.Verify(kid, payload, sig)
+}
+
+func (v *Verifier) VerifyCtx(ctx context.Context, kid string, payload, sig []byte) error {
+	return v.km.VerifyCtx(ctx, kid, payload, sig)
+}
+
+func (v *Verifier) VerifyWithAlg(ctx context.Context, kid string, alg Alg, payload, sig []byte) error {
+	return v.km.VerifyWithAlg(ctx, kid, alg, payload, sig)
+}
+
+func (v *Verifier) VerifyAny(alg Alg, payload, sig []byte) error {
+	return v.km.VerifyAny(alg, payload, sig)
+}
+
+func (v *Verifier) VerifyJWT(token string) (map[string]any, error) {
+	return v.km.VerifyJWT(token)
+}
+
+func (v *Verifier) VerifyJWTCtx(ctx context.Context, token string) (map[string]any, error) {
+	return v.km.VerifyJWTCtx(ctx, token)
+}
+
+func (v *Verifier) GetPublicKey(kid string) (crypto.PublicKey, error) {
+	return v.km.GetPublicKey(kid)
+}
+
+func (v *Verifier) JWKS() ([]byte, error) {
+	return v.km.JWKS()
+}
+
+func (v *Verifier) Keys() []KeyInfo {
+	return v.km.Keys()
+}
+
+func (v *Verifier) ApplyRevocationList(list string) error {
+	return v.km.ApplyRevocationList(list)
+}
+
+func (v *Verifier) ReloadCache() error {
+	return v.km.ReloadCache()
+}
+
+func (v *Verifier) Health() *HealthReport {
+	return v.km.Health()
+}
+
+func (v *Verifier) Close(ctx context.Context) error {
+	return v.km.Close(ctx)
+}
+
+// pastRetention reports whether k is an inactive key that expired longer
+// ago than the window set with WithKeyRetention.
+func (km *KeyManager) pastRetention(k *Key) bool {
+	if km.retention <= 0 || k.IsActive || k.ExpiresAt == nil {
+		return false
+	}
+	return km.clock.Now().Sub(*k.ExpiresAt) > km.retention
+}
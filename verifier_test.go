@@ -0,0 +1,53 @@
+package keys_manager
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifier_RetainsKeysLongerThanSigner(t *testing.T) {
+	store := NewMockStore()
+	enc := MockEncryptor{}
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	exp := time.Now().Add(-48 * time.Hour)
+	retired := makeTestKey("retired", AlgEdDSA, false, &exp, enc, priv)
+	retired.PublicKey, _ = marshalPublicKey(priv.Public())
+	_ = store.Save(retired)
+
+	payload := []byte("payload")
+	sig := ed25519.Sign(priv.(ed25519.PrivateKey), payload)
+
+	signer, err := NewKeyManager(store, enc, mockPolicy, WithKeyRetention(time.Hour))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	_ = signer.InitKeys([]Alg{AlgEdDSA})
+
+	verifier, err := NewVerifier(store, WithKeyRetention(30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("NewVerifier error: %v", err)
+	}
+
+	if err := signer.Verify("retired", payload, sig); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("signer must drop keys past its retention, got %v", err)
+	}
+	if err := verifier.Verify("retired", payload, sig); err != nil {
+		t.Fatalf("verifier must keep keys within its retention, got %v", err)
+	}
+
+	signerJWKS, _ := signer.JWKS()
+	verifierJWKS, _ := verifier.JWKS()
+	if strings.Contains(string(signerJWKS), "retired") || !strings.Contains(string(verifierJWKS), "retired") {
+		t.Fatalf("JWKS must follow each manager's retention")
+	}
+
+	active := signer.activeKey(AlgEdDSA).key.KID
+	sig, _ = signer.Sign(AlgEdDSA, func(string) ([]byte, error) { return payload, nil })
+	if err := verifier.Verify(active, payload, sig); err != nil {
+		t.Fatalf("verifier must pick up the signer's new key, got %v", err)
+	}
+}
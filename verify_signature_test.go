@@ -1,6 +1,7 @@
 package keys_manager
 
 import (
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
@@ -8,6 +9,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"errors"
 	"testing"
 )
 
@@ -85,6 +87,50 @@ func TestVerifySignature_ES256(t *testing.T) {
 	}
 }
 
+func TestVerifySignature_ES256WrongLength(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for _, n := range []int{0, 2, 62, 63, 65, 96, 132} {
+		err := verifySignature(AlgES256, &priv.PublicKey, []byte("x"), make([]byte, n))
+
+		var lenErr *SignatureLengthError
+		if !errors.As(err, &lenErr) || lenErr.Want != 64 || lenErr.Got != n {
+			t.Fatalf("%d byte signature: expected SignatureLengthError, got %v", n, err)
+		}
+	}
+}
+
+func TestVerify_DERSignatures(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgES256})
+
+	ck := km.activeKey(AlgES256)
+	payload := []byte("interop")
+	digest := sha256.Sum256(payload)
+	der, err := ecdsa.SignASN1(rand.Reader, ck.priv.(*ecdsa.PrivateKey), digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	if err := km.Verify(ck.key.KID, payload, der); err == nil {
+		t.Fatalf("DER signature must be rejected without WithDERSignatures")
+	}
+
+	lenient, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy, WithDERSignatures())
+	if err := lenient.Verify(ck.key.KID, payload, der); err != nil {
+		t.Fatalf("DER signature must verify with WithDERSignatures, got %v", err)
+	}
+	if err := lenient.VerifyAny(AlgES256, payload, der); err != nil {
+		t.Fatalf("VerifyAny must accept DER with WithDERSignatures, got %v", err)
+	}
+
+	raw, _ := km.Sign(AlgES256, func(string) ([]byte, error) { return payload, nil })
+	if err := lenient.Verify(ck.key.KID, payload, raw); err != nil {
+		t.Fatalf("raw signatures must keep verifying, got %v", err)
+	}
+}
+
 func TestVerifySignature_EdDSA(t *testing.T) {
 	pub, priv, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
@@ -115,3 +161,51 @@ func TestVerifySignature_UnsupportedAlg(t *testing.T) {
 		t.Fatal("expected error for unsupported algorithm")
 	}
 }
+
+func TestVerifyWithAlg_RSAPSS(t *testing.T) {
+	km, _ := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgRS256, AlgES256})
+
+	rsaKey := km.activeKey(AlgRS256)
+	priv := rsaKey.priv.(*rsa.PrivateKey)
+	payload := []byte("issued elsewhere")
+	ctx := context.Background()
+
+	for _, alg := range []Alg{AlgPS256, AlgPS384, AlgPS512} {
+		hash, _ := alg.pssHash()
+		h := hash.New()
+		h.Write(payload)
+		digest := h.Sum(nil)
+
+		sig, err := rsa.SignPSS(rand.Reader, priv, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		if err != nil {
+			t.Fatalf("%s: sign: %v", alg, err)
+		}
+		if err := km.VerifyWithAlg(ctx, rsaKey.key.KID, alg, payload, sig); err != nil {
+			t.Fatalf("%s: VerifyWithAlg error: %v", alg, err)
+		}
+
+		short, _ := rsa.SignPSS(rand.Reader, priv, hash, digest, &rsa.PSSOptions{SaltLength: 8})
+		if err := km.VerifyWithAlg(ctx, rsaKey.key.KID, alg, payload, short); err == nil {
+			t.Fatalf("%s: salt length other than the hash size must be rejected", alg)
+		}
+	}
+
+	if err := km.VerifyWithAlg(ctx, km.activeKey(AlgES256).key.KID, AlgPS256, payload, []byte("sig")); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("PS256 on an EC key: expected ErrInvalidToken, got %v", err)
+	}
+	if err := km.Rotate(AlgPS256); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Fatalf("PS256 keys must not be generated, got %v", err)
+	}
+
+	input := b64([]byte(`{"alg":"PS256","kid":"`+rsaKey.key.KID+`"}`)) + "." + b64([]byte(`{"sub":"alice"}`))
+	digest := sha256.Sum256([]byte(input))
+	sig, _ := rsa.SignPSS(rand.Reader, priv, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	token := input + "." + b64(sig)
+	if _, err := km.VerifyJWT(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("VerifyJWT must not accept PS256 on an RS256 key, got %v", err)
+	}
+	if _, err := km.ReSign(token, ReSignOptions{}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("ReSign must not accept PS256 on an RS256 key, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package keys_manager
+
+import (
+	"context"
+	"fmt"
+)
+
+// StoreEvent describes a change made to a store. KID is empty when the
+// store cannot tell which key changed.
+type StoreEvent struct {
+	KID string
+}
+
+// Watcher is implemented by stores that can push change notifications.
+// The channel is closed when the subscription ends or ctx is done.
+//
+// While a subscription is live the cache is only reloaded on
+// notification: cache misses and WithBackgroundRefresh no longer poll
+// the store. If the channel closes early, the manager falls back to them.
+type Watcher interface {
+	Watch(ctx context.Context) (<-chan StoreEvent, error)
+}
+
+// watchStore subscribes before the initial load so no change is missed
+// between the two.
+func (km *KeyManager) watchStore() (<-chan StoreEvent, error) {
+	w := km.watcher
+	if w == nil {
+		sw, ok := km.store.(Watcher)
+		if !ok {
+			return nil, nil
+		}
+		w = sw
+	}
+
+	events, err := w.Watch(km.bg.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("watch store: %w", err)
+	}
+
+	km.watching.Store(true)
+	return events, nil
+}
+
+func (km *KeyManager) watchLoop(ctx context.Context, events <-chan StoreEvent) {
+	defer func() {
+		km.watching.Store(false)
+		if km.refreshInterval > 0 && ctx.Err() == nil {
+			km.refreshLoop(ctx, km.refreshInterval)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			// Drain queued events so a burst costs a single reload.
+			for drained := false; !drained; {
+				select {
+				case _, ok := <-events:
+					if !ok {
+						km.reportError(OpWatchReload, "", km.reload(withReloadCause(ctx, ReloadWatch)))
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+			km.reportError(OpWatchReload, "", km.reload(withReloadCause(ctx, ReloadWatch)))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type watchingStore struct {
+	*MockStore
+	events chan StoreEvent
+	lists  atomic.Int64
+	err    error
+}
+
+func newWatchingStore() *watchingStore {
+	return &watchingStore{MockStore: NewMockStore(), events: make(chan StoreEvent, 8)}
+}
+
+func (s *watchingStore) List() ([]*Key, error) {
+	s.lists.Add(1)
+	return s.MockStore.List()
+}
+
+func (s *watchingStore) Watch(context.Context) (<-chan StoreEvent, error) {
+	return s.events, s.err
+}
+
+func newEdKey(kid string) *Key {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	return makeTestKey(kid, AlgEdDSA, true, nil, MockEncryptor{}, priv)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWatcher_MissDoesNotReload(t *testing.T) {
+	store := newWatchingStore()
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	before := store.lists.Load()
+
+	if err := km.Verify("unknown", nil, nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	if got := store.lists.Load(); got != before {
+		t.Fatalf("expected no reload on miss while watching, got %d lists", got-before)
+	}
+}
+
+func TestWatcher_NotificationReloads(t *testing.T) {
+	store := newWatchingStore()
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	_ = store.Save(newEdKey("k1"))
+	store.events <- StoreEvent{KID: "k1"}
+
+	waitFor(t, func() bool {
+		_, err := km.GetPublicKey("k1")
+		return err == nil
+	})
+}
+
+func TestWatcher_ClosedFallsBackToImplicitReload(t *testing.T) {
+	store := newWatchingStore()
+
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	defer km.Close(context.Background())
+
+	close(store.events)
+	waitFor(t, func() bool { return !km.watching.Load() })
+
+	_ = store.Save(newEdKey("k1"))
+
+	if _, err := km.GetPublicKey("k1"); err != nil {
+		t.Fatalf("expected implicit reload after watch ended, got %v", err)
+	}
+}
+
+func TestWatcher_SubscribeError(t *testing.T) {
+	store := newWatchingStore()
+	store.err = errors.New("boom")
+
+	if _, err := NewKeyManager(store, MockEncryptor{}, mockPolicy); err == nil {
+		t.Fatalf("expected subscribe error")
+	}
+}
@@ -0,0 +1,189 @@
+package keys_manager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader carries webhook signatures in the form
+//
+//	t=<unix seconds>,v1=<kid>:<base64url signature>,v1=...
+//
+// Each v1 entry signs "<t>.<payload>" with one webhook key.
+const WebhookSignatureHeader = "Webhook-Signature"
+
+const webhookScheme = "v1"
+
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// maxWebhookSignatures bounds the v1 entries VerifyWebhook looks at, so a
+// forged header cannot make it run an unbounded number of verifications.
+const maxWebhookSignatures = 16
+
+func (km *KeyManager) SignWebhook(payload []byte) (http.Header, error) {
+	return km.SignWebhookCtx(context.Background(), payload)
+}
+
+// SignWebhookCtx signs payload with every active PurposeWebhook key and
+// with the retired ones that have not expired yet, so receivers still
+// pinned to a previous key keep verifying during a rotation. The returned
+// header holds WebhookSignatureHeader.
+func (km *KeyManager) SignWebhookCtx(ctx context.Context, payload []byte) (http.Header, error) {
+	keys := km.webhookKeys()
+	if len(keys) == 0 {
+		_ = km.implicitReload(ctx, ReloadActiveMiss)
+		keys = km.webhookKeys()
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w for purpose %s", ErrNoActiveKey, PurposeWebhook)
+	}
+
+	ts := strconv.FormatInt(km.clock.Now().Unix(), 10)
+	signed := webhookSigningInput(ts, payload)
+
+	var b strings.Builder
+	b.WriteString("t=" + ts)
+
+	for _, ck := range keys {
+		ck, err := km.materialize(ck)
+		if err != nil {
+			return nil, err
+		}
+
+		sig, err := km.sign(ctx, ck, func(string) ([]byte, error) { return signed, nil })
+		if err != nil {
+			return nil, fmt.Errorf("sign webhook with %s: %w", ck.key.KID, err)
+		}
+
+		fmt.Fprintf(&b, ",%s=%s:%s", webhookScheme, ck.key.KID, base64.RawURLEncoding.EncodeToString(sig))
+	}
+
+	h := make(http.Header)
+	h.Set(WebhookSignatureHeader, b.String())
+
+	return h, nil
+}
+
+// webhookKeys returns the webhook keys to sign with, active keys first and
+// the rest newest first.
+func (km *KeyManager) webhookKeys() []*CachedKey {
+	now := km.clock.Now()
+
+	var out []*CachedKey
+	for _, ck := range km.snapshot().cache {
+		k := ck.key
+		if k.Purpose != PurposeWebhook || k.Alg.symmetric() || k.RevokedAt != nil {
+			continue
+		}
+		if km.expired(k, now) || km.notYetValid(k, now) {
+			continue
+		}
+		out = append(out, ck)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].key, out[j].key
+		if a.IsActive != b.IsActive {
+			return a.IsActive
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	return out
+}
+
+func (km *KeyManager) VerifyWebhook(h http.Header, payload []byte) error {
+	return km.VerifyWebhookCtx(context.Background(), h, payload)
+}
+
+// VerifyWebhookCtx accepts payload when its timestamp is within the
+// webhook tolerance and at least one v1 signature verifies with a known
+// PurposeWebhook key. Keys are looked up in the loaded cache only: the KIDs
+// come from the sender, so an unknown one never reaches the store.
+// Signatures by unknown keys are skipped, and headers with more than
+// maxWebhookSignatures entries are rejected.
+func (km *KeyManager) VerifyWebhookCtx(ctx context.Context, h http.Header, payload []byte) error {
+	value := h.Get(WebhookSignatureHeader)
+	if value == "" {
+		return fmt.Errorf("%w: missing %s header", ErrInvalidWebhook, WebhookSignatureHeader)
+	}
+
+	var ts string
+	type entry struct {
+		kid string
+		sig []byte
+	}
+	var entries []entry
+
+	for _, part := range strings.Split(value, ",") {
+		name, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return fmt.Errorf("%w: malformed header", ErrInvalidWebhook)
+		}
+
+		switch name {
+		case "t":
+			ts = v
+		case webhookScheme:
+			i := strings.LastIndex(v, ":")
+			if i < 0 {
+				return fmt.Errorf("%w: malformed signature", ErrInvalidWebhook)
+			}
+			sig, err := base64.RawURLEncoding.DecodeString(v[i+1:])
+			if err != nil {
+				return fmt.Errorf("%w: malformed signature", ErrInvalidWebhook)
+			}
+			if len(entries) == maxWebhookSignatures {
+				return fmt.Errorf("%w: more than %d signatures", ErrInvalidWebhook, maxWebhookSignatures)
+			}
+			entries = append(entries, entry{kid: v[:i], sig: sig})
+		}
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or malformed timestamp", ErrInvalidWebhook)
+	}
+
+	tolerance := km.webhookTolerance
+	if tolerance == 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	if d := km.clock.Now().Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhook)
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("%w: no %s signatures", ErrInvalidWebhook, webhookScheme)
+	}
+
+	cache := km.snapshot().cache
+	signed := webhookSigningInput(ts, payload)
+	for _, e := range entries {
+		ck := cache[e.kid]
+		if ck == nil || ck.key.Purpose != PurposeWebhook {
+			continue
+		}
+		if km.verifyCached(ck, "", false, rawInput, signed, e.sig) == nil {
+			return nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: no signature matches a webhook key", ErrInvalidWebhook)
+}
+
+func webhookSigningInput(ts string, payload []byte) []byte {
+	out := make([]byte, 0, len(ts)+1+len(payload))
+	out = append(out, ts...)
+	out = append(out, '.')
+	return append(out, payload...)
+}
@@ -0,0 +1,139 @@
+package keys_manager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newWebhookManager(t *testing.T, clock *fakeClock) *KeyManager {
+	t.Helper()
+
+	km, err := NewKeyManager(NewMockStore(), MockEncryptor{}, mockPolicy, WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	if err := km.InitKeysWithPurpose(PurposeWebhook, []Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeysWithPurpose error: %v", err)
+	}
+
+	return km
+}
+
+func TestWebhook_SignAndVerify(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km := newWebhookManager(t, clock)
+
+	payload := []byte(`{"event":"invoice.paid"}`)
+	h, err := km.SignWebhook(payload)
+	if err != nil {
+		t.Fatalf("SignWebhook error: %v", err)
+	}
+
+	value := h.Get(WebhookSignatureHeader)
+	if !strings.HasPrefix(value, "t=1893456000,v1=") {
+		t.Fatalf("unexpected header %q", value)
+	}
+
+	if err := km.VerifyWebhook(h, payload); err != nil {
+		t.Fatalf("VerifyWebhook error: %v", err)
+	}
+
+	if err := km.VerifyWebhook(h, []byte(`{"event":"invoice.void"}`)); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("modified payload must be rejected, got %v", err)
+	}
+
+	clock.now = clock.now.Add(DefaultWebhookTolerance + time.Second)
+	if err := km.VerifyWebhook(h, payload); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("stale signature must be rejected, got %v", err)
+	}
+}
+
+func TestWebhook_SignsWithGraceKeysAfterRotation(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km := newWebhookManager(t, clock)
+
+	old := km.activeKeyFor(t.Context(), keySlot{alg: AlgEdDSA, purpose: PurposeWebhook}).key.KID
+
+	clock.now = clock.now.Add(time.Minute)
+	if err := km.RotateWithPurpose(AlgEdDSA, PurposeWebhook); err != nil {
+		t.Fatalf("rotate error: %v", err)
+	}
+
+	h, _ := km.SignWebhook([]byte("x"))
+	value := h.Get(WebhookSignatureHeader)
+	if n := strings.Count(value, "v1="); n != 2 {
+		t.Fatalf("expected signatures from active and grace key, got %q", value)
+	}
+	if !strings.Contains(value, ",v1="+old+":") || strings.Index(value, old) < strings.LastIndex(value, "v1=") {
+		t.Fatalf("grace key must be listed after the active key, got %q", value)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	h, _ = km.SignWebhook([]byte("x"))
+	if n := strings.Count(h.Get(WebhookSignatureHeader), "v1="); n != 1 {
+		t.Fatalf("expired grace key must not sign, got %q", h.Get(WebhookSignatureHeader))
+	}
+}
+
+func TestWebhook_RejectsOtherPurposeKeys(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km := newWebhookManager(t, clock)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	ts := "1893456000"
+	var kid string
+	sig, err := km.Sign(AlgEdDSA, func(k string) ([]byte, error) {
+		kid = k
+		return webhookSigningInput(ts, []byte("x")), nil
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	h, _ := km.SignWebhook([]byte("x"))
+	h.Set(WebhookSignatureHeader, "t="+ts+",v1="+kid+":"+b64(sig))
+
+	if err := km.VerifyWebhook(h, []byte("x")); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("signature by a non-webhook key must be rejected, got %v", err)
+	}
+}
+
+func TestWebhook_UnknownKIDsDoNotReload(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km, store := newCountingManager(t, clock)
+	if err := km.InitKeysWithPurpose(PurposeWebhook, []Alg{AlgEdDSA}); err != nil {
+		t.Fatalf("InitKeysWithPurpose error: %v", err)
+	}
+	store.lists.Store(0)
+
+	h, _ := km.SignWebhook([]byte("x"))
+	h.Set(WebhookSignatureHeader, "t=1893456000,v1=forged-1:AA,v1=forged-2:AA")
+
+	if err := km.VerifyWebhook(h, []byte("x")); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("expected ErrInvalidWebhook, got %v", err)
+	}
+	if got := store.lists.Load(); got != 0 {
+		t.Fatalf("unknown webhook kids must not reload the store, got %d reloads", got)
+	}
+}
+
+func TestWebhook_CapsSignatureEntries(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	km := newWebhookManager(t, clock)
+
+	h, _ := km.SignWebhook([]byte("x"))
+	valid := h.Get(WebhookSignatureHeader)
+
+	var b strings.Builder
+	for i := range maxWebhookSignatures {
+		fmt.Fprintf(&b, ",v1=forged-%d:AA", i)
+	}
+	h.Set(WebhookSignatureHeader, valid+b.String())
+
+	if err := km.VerifyWebhook(h, []byte("x")); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("headers over the signature cap must be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package keys_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+// weightedSplit reports whether every active key of a slot carries a
+// Weight, making them a deliberate traffic split rather than duplicates.
+func weightedSplit(keys []*Key) bool {
+	for _, k := range keys {
+		if k.Weight <= 0 {
+			return false
+		}
+	}
+	return len(keys) > 1
+}
+
+func (km *KeyManager) weightedSplits(bySlot map[keySlot][]*Key, cache map[string]*CachedKey) map[keySlot][]*CachedKey {
+	var out map[keySlot][]*CachedKey
+	for s, keys := range bySlot {
+		if !weightedSplit(keys) {
+			continue
+		}
+		km.rankActive(keys)
+
+		if out == nil {
+			out = make(map[keySlot][]*CachedKey)
+		}
+		for _, k := range keys {
+			out[s] = append(out[s], cache[k.KID])
+		}
+	}
+	return out
+}
+
+// signingKey returns the key of s that signs next: the active key, or a
+// weighted pick when the slot is split.
+func (km *KeyManager) signingKey(ctx context.Context, s keySlot) *CachedKey {
+	ck := km.activeKeyFor(ctx, s)
+	if ck == nil {
+		return nil
+	}
+	return km.pickWeighted(s, ck)
+}
+
+// pickWeighted chooses among the weighted active keys of s in proportion to
+// their weights. Slots without a split, and picks of a key that is not yet
+// valid, keep ck.
+func (km *KeyManager) pickWeighted(s keySlot, ck *CachedKey) *CachedKey {
+	split := km.snapshot().splits[s]
+	if len(split) == 0 {
+		return ck
+	}
+
+	total := 0
+	for _, w := range split {
+		total += w.key.Weight
+	}
+
+	n := rand.IntN(total)
+	for _, w := range split {
+		if n < w.key.Weight {
+			if km.notYetValid(w.key, km.clock.Now()) {
+				return ck
+			}
+			return w
+		}
+		n -= w.key.Weight
+	}
+
+	return ck
+}
+
+// RotateWeighted starts a canary rollout in the slot of alg and purpose: it
+// generates a key that takes weight shares of the Sign traffic while the
+// current active keys keep signing, each with currentWeight shares unless
+// it already has a weight. SetWeight adjusts or ends the split; Rotate
+// retires all of it. The store must implement KeyBatchSaver, and canaries
+// are not available with WithApprover.
+func (km *KeyManager) RotateWeighted(ctx context.Context, alg Alg, purpose Purpose, weight, currentWeight int) (string, error) {
+	s := keySlot{alg: alg, purpose: purpose}
+
+	if weight <= 0 || currentWeight <= 0 {
+		return "", fmt.Errorf("weights must be positive, got %d and %d", weight, currentWeight)
+	}
+
+	saver, err := km.saver()
+	if err != nil {
+		return "", err
+	}
+	if km.approver != nil {
+		return "", errors.New("weighted rotation cannot wait for approval")
+	}
+	if err := km.authorizeRequest(ctx, AccessRequest{Action: ActionRotate, Alg: alg, Purpose: purpose}); err != nil {
+		return "", err
+	}
+
+	policy, err := km.rotationConfigFor(purpose)
+	if err != nil {
+		return "", err
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := km.clock.Now()
+
+	var updates []*Key
+	for _, k := range keys {
+		if !k.IsActive || slotOf(k) != s {
+			continue
+		}
+		k = cloneKey(k)
+		if k.Weight <= 0 {
+			k.Weight = currentWeight
+			k.UpdatedAt = now
+		}
+		updates = append(updates, k)
+	}
+	if len(updates) == 0 {
+		return "", fmt.Errorf("%w for %s", ErrNoActiveKey, s)
+	}
+
+	canary, err := km.generateKey(ctx, s, nil, now, now.Add(policy.TTL))
+	if err != nil {
+		return "", err
+	}
+	canary.Weight = weight
+
+	if err := km.putKeys(saver, append(updates, canary)...); err != nil {
+		return "", err
+	}
+
+	km.logRotate(ctx, s, canary.KID, "", nil)
+	km.publish(ctx, LifecycleEvent{Type: EventKeyRotated, KID: canary.KID, Alg: alg, Purpose: purpose})
+
+	return canary.KID, km.reload(ctx)
+}
+
+// SetWeight changes the share of Sign traffic of the active key kid. A
+// weight of 0 takes the key out of the split and retires it, which ends a
+// canary rollout either way; the last active key of a slot cannot be
+// retired so. The store must implement KeyBatchSaver.
+func (km *KeyManager) SetWeight(ctx context.Context, kid string, weight int) error {
+	if weight < 0 {
+		return fmt.Errorf("weight must not be negative, got %d", weight)
+	}
+
+	saver, err := km.saver()
+	if err != nil {
+		return err
+	}
+
+	keys, err := km.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var target *Key
+	for _, k := range keys {
+		if k.KID == kid {
+			target = k
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+	if !target.IsActive {
+		return fmt.Errorf("%w: %s is not active", ErrNoActiveKey, kid)
+	}
+	active := 0
+	for _, k := range keys {
+		if k.IsActive && slotOf(k) == slotOf(target) {
+			active++
+		}
+	}
+
+	if err := km.authorize(ctx, ActionRotate, target); err != nil {
+		return err
+	}
+
+	updated := cloneKey(target)
+	updated.Weight = weight
+	updated.UpdatedAt = km.clock.Now()
+	if weight == 0 {
+		if active < 2 {
+			return fmt.Errorf("%w: %s is the only active key of %s", ErrKeyInUse, kid, slotOf(target))
+		}
+		updated.IsActive = false
+	}
+
+	if err := km.putKeys(saver, updated); err != nil {
+		return err
+	}
+
+	return km.reload(ctx)
+}
@@ -0,0 +1,132 @@
+package keys_manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func weightedStore(weights map[string]int) *MockStore {
+	store := NewMockStore()
+	now := time.Now()
+
+	for kid, w := range weights {
+		priv, _ := generatePrivateKey(AlgEdDSA)
+		k := makeTestKey(kid, AlgEdDSA, true, nil, MockEncryptor{}, priv)
+		k.CreatedAt = now
+		k.Weight = w
+		store.data[kid] = k
+	}
+
+	return store
+}
+
+func signCounts(t *testing.T, km *KeyManager, n int) map[string]int {
+	t.Helper()
+
+	counts := make(map[string]int)
+	for range n {
+		_, err := km.Sign(AlgEdDSA, func(kid string) ([]byte, error) {
+			counts[kid]++
+			return []byte("payload"), nil
+		})
+		if err != nil {
+			t.Fatalf("Sign error: %v", err)
+		}
+	}
+	return counts
+}
+
+func TestWeight_CanaryRollout(t *testing.T) {
+	store := NewMockStore()
+	km, err := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	if err != nil {
+		t.Fatalf("NewKeyManager error: %v", err)
+	}
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	stable := km.activeKey(AlgEdDSA).key.KID
+
+	canary, err := km.RotateWeighted(t.Context(), AlgEdDSA, PurposeDefault, 1, 3)
+	if err != nil {
+		t.Fatalf("RotateWeighted error: %v", err)
+	}
+	if !store.data[stable].IsActive || !store.data[canary].IsActive {
+		t.Fatalf("the canary must sign next to the current key")
+	}
+
+	counts := signCounts(t, km, 2000)
+	if counts[stable]+counts[canary] != 2000 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+	if counts[canary] < 300 || counts[canary] > 700 {
+		t.Fatalf("canary must get about a quarter of the traffic, got %v", counts)
+	}
+	if dups := km.DuplicateActiveKeys(); len(dups) != 0 {
+		t.Fatalf("weighted keys are not duplicates, got %+v", dups)
+	}
+
+	if err := km.SetWeight(t.Context(), stable, 0); err != nil {
+		t.Fatalf("SetWeight error: %v", err)
+	}
+	if counts := signCounts(t, km, 50); counts[canary] != 50 {
+		t.Fatalf("retiring the stable key must hand all traffic to the canary, got %v", counts)
+	}
+
+	if err := km.SetWeight(t.Context(), canary, 0); !errors.Is(err, ErrKeyInUse) {
+		t.Fatalf("the last active key must not be retired, got %v", err)
+	}
+}
+
+func TestWeight_UnweightedKeyDisablesSplit(t *testing.T) {
+	km, _ := NewKeyManager(weightedStore(map[string]int{"a": 1, "b": 0}), MockEncryptor{}, mockPolicy)
+
+	counts := signCounts(t, km, 50)
+	if len(counts) != 1 || counts["b"] != 50 {
+		t.Fatalf("without a full split the winner must sign everything, got %v", counts)
+	}
+
+	if dups := km.DuplicateActiveKeys(); len(dups) != 1 {
+		t.Fatalf("mixed weighted and unweighted keys are duplicates, got %+v", dups)
+	}
+}
+
+func TestWeight_RotateRetiresWholeSplit(t *testing.T) {
+	store := weightedStore(map[string]int{"stable": 3, "canary": 1})
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+
+	if err := km.Rotate(AlgEdDSA); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	active := 0
+	for _, k := range km.Keys() {
+		if k.State == KeyStateActive {
+			active++
+		}
+	}
+	if active != 1 || store.data["stable"].IsActive || store.data["canary"].IsActive {
+		t.Fatalf("rotation must retire every key of the split, %d active", active)
+	}
+}
+
+func TestWeight_SplitsOtherSignPaths(t *testing.T) {
+	km, _ := NewKeyManager(weightedStore(map[string]int{"stable": 1, "canary": 1}), MockEncryptor{}, mockPolicy)
+
+	seen := make(map[string]bool)
+	for range 200 {
+		kid, _, err := km.SignBatch(AlgEdDSA, [][]byte{[]byte("payload")}, 1)
+		if err != nil {
+			t.Fatalf("SignBatch error: %v", err)
+		}
+		seen[kid] = true
+
+		info, err := km.ActiveKeyInfo(AlgEdDSA)
+		if err != nil {
+			t.Fatalf("ActiveKeyInfo error: %v", err)
+		}
+		seen["info:"+info.KID] = true
+	}
+	if len(seen) != 4 {
+		t.Fatalf("SignBatch and ActiveKeyInfo must follow the split, got %v", seen)
+	}
+}
@@ -0,0 +1,156 @@
+package keys_manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"math/big"
+)
+
+// Zeroization is best effort: the Go runtime may have copied key material
+// and the crypto packages keep derived values we cannot reach.
+
+func wipeBytes(b []byte) {
+	clear(b)
+}
+
+func wipeBig(n *big.Int) {
+	if n != nil {
+		clear(n.Bits())
+		n.SetInt64(0)
+	}
+}
+
+func wipeSigner(priv crypto.Signer) {
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		clear(k)
+	case *ecdsa.PrivateKey:
+		wipeBig(k.D)
+	case *rsa.PrivateKey:
+		wipeBig(k.D)
+		for _, p := range k.Primes {
+			wipeBig(p)
+		}
+		wipeBig(k.Precomputed.Dp)
+		wipeBig(k.Precomputed.Dq)
+		wipeBig(k.Precomputed.Qinv)
+	}
+}
+
+// signerWiped reports whether priv was zeroed by wipeSigner.
+func signerWiped(priv crypto.Signer) bool {
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		for _, b := range k {
+			if b != 0 {
+				return false
+			}
+		}
+		return true
+	case *ecdsa.PrivateKey:
+		return k.D == nil || k.D.Sign() == 0
+	case *rsa.PrivateKey:
+		return k.D == nil || k.D.Sign() == 0
+	}
+	return false
+}
+
+// cloneSigner copies priv so callers outside the manager own material the
+// cache never wipes.
+func cloneSigner(priv crypto.Signer) (crypto.Signer, error) {
+	der, err := marshalPKCS8(priv)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(der)
+
+	return parsePrivateKey(der)
+}
+
+// retiredMaterial is key material a reload dropped from the cache.
+type retiredMaterial struct {
+	signers []crypto.Signer
+	secrets [][]byte
+}
+
+func (r retiredMaterial) wipe() {
+	for _, priv := range r.signers {
+		wipeSigner(priv)
+	}
+	for _, secret := range r.secrets {
+		wipeBytes(secret)
+	}
+}
+
+// wipeRetired wipes material dropped from the cache once no signature is
+// being made, so in-flight signers never see zeroed material.
+func (km *KeyManager) wipeRetired(retired retiredMaterial) {
+	if len(retired.signers) == 0 && len(retired.secrets) == 0 {
+		return
+	}
+
+	km.material.Lock()
+	defer km.material.Unlock()
+
+	retired.wipe()
+}
+
+// wipeKeys wipes the material of cache entries nothing else references,
+// such as those evicted from the bounded cache.
+func (km *KeyManager) wipeKeys(cks []*CachedKey) {
+	if len(cks) == 0 {
+		return
+	}
+
+	km.material.Lock()
+	defer km.material.Unlock()
+
+	for _, ck := range cks {
+		if ck.priv != nil {
+			wipeSigner(ck.priv)
+		}
+		wipeBytes(ck.secret)
+	}
+}
+
+// signerID identifies key material so entries sharing it can be matched.
+func signerID(priv crypto.Signer) any {
+	if k, ok := priv.(ed25519.PrivateKey); ok {
+		if len(k) == 0 {
+			return nil
+		}
+		return &k[0]
+	}
+	return priv
+}
+
+// prunedMaterial returns the private keys and secrets held by old that
+// next no longer references.
+func prunedMaterial(old, next map[string]*CachedKey) retiredMaterial {
+	kept := make(map[any]struct{}, len(next))
+	for _, ck := range next {
+		if ck.priv != nil {
+			kept[signerID(ck.priv)] = struct{}{}
+		}
+		if len(ck.secret) > 0 {
+			kept[&ck.secret[0]] = struct{}{}
+		}
+	}
+
+	var out retiredMaterial
+	for _, ck := range old {
+		if ck.priv != nil {
+			if _, ok := kept[signerID(ck.priv)]; !ok {
+				out.signers = append(out.signers, ck.priv)
+			}
+		}
+		if len(ck.secret) > 0 {
+			if _, ok := kept[&ck.secret[0]]; !ok {
+				out.secrets = append(out.secrets, ck.secret)
+			}
+		}
+	}
+	return out
+}
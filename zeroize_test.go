@@ -0,0 +1,119 @@
+package keys_manager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestWipeSigner(t *testing.T) {
+	for _, alg := range []Alg{AlgRS256, AlgES256, AlgEdDSA} {
+		priv, _ := generatePrivateKey(alg)
+		wipeSigner(priv)
+
+		switch k := priv.(type) {
+		case ed25519.PrivateKey:
+			for _, b := range k {
+				if b != 0 {
+					t.Fatalf("%s: key bytes not wiped", alg)
+				}
+			}
+		case *ecdsa.PrivateKey:
+			if k.D.Sign() != 0 {
+				t.Fatalf("%s: D not wiped", alg)
+			}
+		case *rsa.PrivateKey:
+			if k.D.Sign() != 0 || k.Primes[0].Sign() != 0 {
+				t.Fatalf("%s: private exponent or primes not wiped", alg)
+			}
+		}
+	}
+}
+
+func TestReload_WipesPrunedKeysAfterOneGeneration(t *testing.T) {
+	store := NewMockStore()
+
+	priv, _ := generatePrivateKey(AlgEdDSA)
+	store.Save(makeTestKey("gone", AlgEdDSA, false, nil, MockEncryptor{}, priv))
+
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+
+	cached := km.snapshot().cache["gone"].priv.(ed25519.PrivateKey)
+
+	store.mu.Lock()
+	delete(store.data, "gone")
+	store.mu.Unlock()
+
+	_ = km.ReloadCache()
+	if isZero(cached) {
+		t.Fatalf("pruned key must survive until the next generation")
+	}
+
+	_ = km.ReloadCache()
+	if !isZero(cached) {
+		t.Fatalf("pruned key must be wiped after the next reload")
+	}
+
+	if _, err := km.Sign(AlgEdDSA, func(string) ([]byte, error) { return []byte("x"), nil }); err != nil {
+		t.Fatalf("retained keys must keep working: %v", err)
+	}
+}
+
+func TestReload_WipesPrunedSecrets(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = km.InitKeysWithPurpose(PurposeCookie, []Alg{AlgA256GCM})
+	kid := km.activeKeyFor(context.Background(), keySlot{alg: AlgA256GCM, purpose: PurposeCookie}).key.KID
+
+	secret := km.snapshot().cache[kid].secret
+
+	store.mu.Lock()
+	delete(store.data, kid)
+	store.mu.Unlock()
+
+	_ = km.ReloadCache()
+	_ = km.ReloadCache()
+	if !isZero(secret) {
+		t.Fatalf("pruned symmetric secret must be wiped after the next reload")
+	}
+}
+
+func TestSigner_SurvivesWipe(t *testing.T) {
+	store := NewMockStore()
+	km, _ := NewKeyManager(store, MockEncryptor{}, mockPolicy)
+	_ = km.InitKeys([]Alg{AlgEdDSA})
+	kid := km.activeKey(AlgEdDSA).key.KID
+
+	signer, err := km.Signer(kid)
+	if err != nil {
+		t.Fatalf("Signer error: %v", err)
+	}
+	cached := km.snapshot().cache[kid]
+
+	store.mu.Lock()
+	delete(store.data, kid)
+	store.mu.Unlock()
+	_ = km.ReloadCache()
+	_ = km.ReloadCache()
+
+	if isZero(signer.(ed25519.PrivateKey)) {
+		t.Fatalf("handed out signers must not be wiped with the cache")
+	}
+	if _, err := km.signInput(nil, cached, crypto.Hash(0), newDigester(crypto.Hash(0)), []byte("x")); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("signing with wiped material must fail, got %v", err)
+	}
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}